@@ -1,14 +1,27 @@
 package ccclient
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/jsonclient"
 )
 
 type Client struct {
 	JSONClient jsonClient
 	BaseURL    string
+
+	// Maximum number of list pages to request from CC at the same time.
+	// When this is 0 or 1, pages are fetched sequentially by following the next links.
+	MaxConcurrentPages int
 }
 
 //go:generate counterfeiter -o fakes/json_client.go --fake-name JSONClient . jsonClient
@@ -21,6 +34,11 @@ type Route struct {
 	Host          string
 	Path          string
 	Url           string
+	Protocol      string
+	Port          *int
+	Options       RouteOptions
+	Metadata      Metadata
+	UpdatedAt     time.Time `json:"updated_at"`
 	Destinations  []Destination
 	Relationships struct {
 		Domain struct {
@@ -36,11 +54,22 @@ type Route struct {
 	}
 }
 
+type RouteOptions struct {
+	// Either round-robin or least-connection, empty when not set
+	Loadbalancing string
+}
+
+type Metadata struct {
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
 type Destination struct {
-	Guid   string
-	App    App
-	Weight *int
-	Port   int
+	Guid     string
+	App      App
+	Weight   *int
+	Port     int
+	Protocol string
 }
 
 type App struct {
@@ -53,13 +82,15 @@ type Process struct {
 }
 
 type Domain struct {
-	Guid     string
-	Name     string
-	Internal bool
+	Guid      string
+	Name      string
+	Internal  bool
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 type Space struct {
 	Guid          string
+	UpdatedAt     time.Time `json:"updated_at"`
 	Relationships struct {
 		Organization struct {
 			Data struct {
@@ -69,75 +100,327 @@ type Space struct {
 	}
 }
 
+// RouteBinding binds a route to a route service, which CC sends all the traffic of the route through
+type RouteBinding struct {
+	Guid            string
+	RouteServiceUrl string    `json:"route_service_url"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	Relationships   struct {
+		Route struct {
+			Data struct {
+				Guid string
+			}
+		}
+	}
+}
+
+// Types of the audit events CC records for changes to routes that do not change the updated_at of the route
+const (
+	AuditEventTypeMapRoute           = "audit.app.map-route"
+	AuditEventTypeUnmapRoute         = "audit.app.unmap-route"
+	AuditEventTypeDeleteRoute        = "audit.route.delete-request"
+	AuditEventTypeDeleteApp          = "audit.app.delete-request"
+	AuditEventTypeDeleteSpace        = "audit.space.delete-request"
+	AuditEventTypeDeleteRouteBinding = "audit.service_route_binding.delete"
+)
+
+// AuditEvent records a change made through CC. The target is the resource that was changed,
+// e.g. the app for mapping or unmapping a route, whose guid is then in the data.
+type AuditEvent struct {
+	Guid      string
+	Type      string
+	CreatedAt time.Time `json:"created_at"`
+	Target    struct {
+		Guid string
+		Type string
+	}
+	Data struct {
+		RouteGuid string `json:"route_guid"`
+	}
+}
+
 // determined by CC API: https://v3-apidocs.cloudfoundry.org/version/3.76.0/index.html#get-a-route
 const MaxResultsPerPage int = 5000
 
-func (c *Client) ListRoutes(token string) ([]Route, error) {
-	pathAndQuery := fmt.Sprintf("v3/routes?per_page=%d", MaxResultsPerPage)
+// keeps the query of a guids filter well below the URL length limits of CC and its proxies
+const maxGuidsPerRequest = 50
+
+func (c *Client) ListRoutes(ctx context.Context, token string) ([]Route, error) {
+	return c.listRoutes(ctx, token, url.Values{})
+}
+
+// ListRoutesUpdatedAfter lists the routes that were created or updated at or after the given time
+func (c *Client) ListRoutesUpdatedAfter(ctx context.Context, token string, after time.Time) ([]Route, error) {
+	return c.listRoutes(ctx, token, updatedAfterQuery(after))
+}
 
-	var response struct {
-		Pagination struct {
-			TotalPages int `json:"total_pages"`
+func (c *Client) listRoutes(ctx context.Context, token string, query url.Values) ([]Route, error) {
+	pathAndQuery := listPathAndQuery("v3/routes", query)
+
+	pages, err := c.getAllPages(ctx, pathAndQuery, token)
+	if err != nil {
+		return nil, err
+	}
+
+	routes := []Route{}
+	for _, page := range pages {
+		var resources []Route
+		if err := json.Unmarshal(page, &resources); err != nil {
+			return nil, fmt.Errorf("unmarshal routes: %w", err)
+		}
+		routes = append(routes, resources...)
+	}
+	return routes, nil
+}
+
+func (c *Client) ListDomains(ctx context.Context, token string) ([]Domain, error) {
+	return c.listDomains(ctx, token, url.Values{})
+}
+
+// ListDomainsUpdatedAfter lists the domains that were created or updated at or after the given time
+func (c *Client) ListDomainsUpdatedAfter(ctx context.Context, token string, after time.Time) ([]Domain, error) {
+	return c.listDomains(ctx, token, updatedAfterQuery(after))
+}
+
+func (c *Client) listDomains(ctx context.Context, token string, query url.Values) ([]Domain, error) {
+	pathAndQuery := listPathAndQuery("v3/domains", query)
+
+	pages, err := c.getAllPages(ctx, pathAndQuery, token)
+	if err != nil {
+		return nil, err
+	}
+
+	domains := []Domain{}
+	for _, page := range pages {
+		var resources []Domain
+		if err := json.Unmarshal(page, &resources); err != nil {
+			return nil, fmt.Errorf("unmarshal domains: %w", err)
 		}
-		Resources []Route
+		domains = append(domains, resources...)
 	}
+	return domains, nil
+}
+
+func (c *Client) ListSpaces(ctx context.Context, token string) ([]Space, error) {
+	return c.listSpaces(ctx, token, url.Values{})
+}
+
+// ListSpacesUpdatedAfter lists the spaces that were created or updated at or after the given time
+func (c *Client) ListSpacesUpdatedAfter(ctx context.Context, token string, after time.Time) ([]Space, error) {
+	return c.listSpaces(ctx, token, updatedAfterQuery(after))
+}
+
+func (c *Client) listSpaces(ctx context.Context, token string, query url.Values) ([]Space, error) {
+	pathAndQuery := listPathAndQuery("v3/spaces", query)
 
-	err := c.getList(pathAndQuery, token, &response)
+	pages, err := c.getAllPages(ctx, pathAndQuery, token)
 	if err != nil {
 		return nil, err
 	}
-	if response.Pagination.TotalPages > 1 {
-		return nil, errors.New("too many results, paging not implemented")
+
+	spaces := []Space{}
+	for _, page := range pages {
+		var resources []Space
+		if err := json.Unmarshal(page, &resources); err != nil {
+			return nil, fmt.Errorf("unmarshal spaces: %w", err)
+		}
+		spaces = append(spaces, resources...)
 	}
+	return spaces, nil
+}
 
-	return response.Resources, nil
+// ListRouteBindings lists the bindings of routes to route services. CC versions
+// without the v3 service route bindings endpoint have no bindings.
+func (c *Client) ListRouteBindings(ctx context.Context, token string) ([]RouteBinding, error) {
+	return c.listRouteBindings(ctx, token, url.Values{})
 }
 
-func (c *Client) ListDomains(token string) ([]Domain, error) {
-	pathAndQuery := fmt.Sprintf("v3/domains?per_page=%d", MaxResultsPerPage)
+// ListRouteBindingsUpdatedAfter lists the route bindings that were created or updated at or after the given time
+func (c *Client) ListRouteBindingsUpdatedAfter(ctx context.Context, token string, after time.Time) ([]RouteBinding, error) {
+	return c.listRouteBindings(ctx, token, updatedAfterQuery(after))
+}
 
-	var response struct {
-		Pagination struct {
-			TotalPages int `json:"total_pages"`
+func (c *Client) listRouteBindings(ctx context.Context, token string, query url.Values) ([]RouteBinding, error) {
+	pathAndQuery := listPathAndQuery("v3/service_route_bindings", query)
+
+	pages, err := c.getAllPages(ctx, pathAndQuery, token)
+	var httpErr *jsonclient.HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound {
+		return []RouteBinding{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bindings := []RouteBinding{}
+	for _, page := range pages {
+		var resources []RouteBinding
+		if err := json.Unmarshal(page, &resources); err != nil {
+			return nil, fmt.Errorf("unmarshal route bindings: %w", err)
 		}
-		Resources []Domain
+		bindings = append(bindings, resources...)
 	}
+	return bindings, nil
+}
 
-	err := c.getList(pathAndQuery, token, &response)
+// ListRoutesByGuids lists the routes with the given guids, routes that do not exist (anymore) are not listed
+func (c *Client) ListRoutesByGuids(ctx context.Context, token string, guids []string) ([]Route, error) {
+	routes := []Route{}
+	for start := 0; start < len(guids); start += maxGuidsPerRequest {
+		end := start + maxGuidsPerRequest
+		if end > len(guids) {
+			end = len(guids)
+		}
+		chunk, err := c.listRoutes(ctx, token, url.Values{"guids": {strings.Join(guids[start:end], ",")}})
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, chunk...)
+	}
+	return routes, nil
+}
+
+// ListAuditEventsCreatedAfter lists the audit events of the given types that were created at or after the given time
+func (c *Client) ListAuditEventsCreatedAfter(ctx context.Context, token string, types []string, after time.Time) ([]AuditEvent, error) {
+	query := url.Values{
+		"types":            {strings.Join(types, ",")},
+		"created_ats[gte]": {after.UTC().Format(time.RFC3339)},
+	}
+	pathAndQuery := listPathAndQuery("v3/audit_events", query)
+
+	pages, err := c.getAllPages(ctx, pathAndQuery, token)
 	if err != nil {
 		return nil, err
 	}
-	if response.Pagination.TotalPages > 1 {
-		return nil, errors.New("too many results, paging not implemented")
+
+	events := []AuditEvent{}
+	for _, page := range pages {
+		var resources []AuditEvent
+		if err := json.Unmarshal(page, &resources); err != nil {
+			return nil, fmt.Errorf("unmarshal audit events: %w", err)
+		}
+		events = append(events, resources...)
 	}
+	return events, nil
+}
+
+func listPathAndQuery(path string, query url.Values) string {
+	query.Set("per_page", strconv.Itoa(MaxResultsPerPage))
+	return fmt.Sprintf("%s?%s", path, query.Encode())
+}
 
-	return response.Resources, nil
+// updatedAfterQuery filters on updated_at with gte rather than gt: CC timestamps only have
+// second precision, so resources updated later within the same second would otherwise be missed
+func updatedAfterQuery(after time.Time) url.Values {
+	return url.Values{"updated_ats[gte]": {after.UTC().Format(time.RFC3339)}}
 }
 
-func (c *Client) ListSpaces(token string) ([]Space, error) {
-	pathAndQuery := fmt.Sprintf("v3/spaces?per_page=%d", MaxResultsPerPage)
+type listResponse struct {
+	Pagination struct {
+		TotalPages int `json:"total_pages"`
+		Next       *struct {
+			Href string `json:"href"`
+		} `json:"next"`
+	}
+	Resources json.RawMessage
+}
 
-	var response struct {
-		Pagination struct {
-			TotalPages int `json:"total_pages"`
+// resources returns the raw resources array, treating a missing array as empty
+func (r listResponse) resources() json.RawMessage {
+	if len(r.Resources) == 0 {
+		return json.RawMessage("[]")
+	}
+	return r.Resources
+}
+
+// getAllPages returns the raw resources of every page of a list endpoint, in page order.
+// Pages are fetched one after another by following the next links returned by CC,
+// unless MaxConcurrentPages allows them to be fetched in parallel.
+func (c *Client) getAllPages(ctx context.Context, pathAndQuery string, token string) ([]json.RawMessage, error) {
+	reqURL := fmt.Sprintf("%s/%s", c.BaseURL, pathAndQuery)
+
+	var firstPage listResponse
+	err := c.getPage(ctx, reqURL, token, &firstPage)
+	if err != nil {
+		return nil, err
+	}
+	pages := []json.RawMessage{firstPage.resources()}
+
+	if c.MaxConcurrentPages > 1 && firstPage.Pagination.TotalPages > 1 {
+		remaining, err := c.getPagesConcurrently(ctx, reqURL, token, firstPage.Pagination.TotalPages)
+		if err != nil {
+			return nil, err
+		}
+		return append(pages, remaining...), nil
+	}
+
+	next := firstPage.Pagination.Next
+	for next != nil && next.Href != "" {
+		var page listResponse
+		err := c.getPage(ctx, next.Href, token, &page)
+		if err != nil {
+			return nil, err
 		}
-		Resources []Space
+		pages = append(pages, page.resources())
+		next = page.Pagination.Next
 	}
+	return pages, nil
+}
 
-	err := c.getList(pathAndQuery, token, &response)
+// getPagesConcurrently fetches pages 2 through totalPages of firstPageURL
+// using at most MaxConcurrentPages requests at a time
+func (c *Client) getPagesConcurrently(ctx context.Context, firstPageURL string, token string, totalPages int) ([]json.RawMessage, error) {
+	parsedURL, err := url.Parse(firstPageURL)
 	if err != nil {
 		return nil, err
 	}
-	if response.Pagination.TotalPages > 1 {
-		return nil, errors.New("too many results, paging not implemented")
+
+	pages := make([]json.RawMessage, totalPages-1)
+	errs := make([]error, totalPages-1)
+
+	pageNumbers := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < c.MaxConcurrentPages && w < totalPages-1; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pageNumber := range pageNumbers {
+				pageURL := *parsedURL
+				query := pageURL.Query()
+				query.Set("page", strconv.Itoa(pageNumber))
+				pageURL.RawQuery = query.Encode()
+
+				var page listResponse
+				i := pageNumber - 2
+				errs[i] = c.getPage(ctx, pageURL.String(), token, &page)
+				pages[i] = page.resources()
+			}
+		}()
+	}
+dispatch:
+	for pageNumber := 2; pageNumber <= totalPages; pageNumber++ {
+		select {
+		case pageNumbers <- pageNumber:
+		case <-ctx.Done():
+			break dispatch
+		}
 	}
+	close(pageNumbers)
+	wg.Wait()
 
-	return response.Resources, nil
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %w", i+2, err)
+		}
+	}
+	return pages, nil
 }
 
-func (c *Client) getList(pathAndQuery string, token string, response interface{}) error {
-	reqURL := fmt.Sprintf("%s/%s", c.BaseURL, pathAndQuery)
-	request, err := http.NewRequest("GET", reqURL, nil)
+func (c *Client) getPage(ctx context.Context, reqURL string, token string, response interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
 	if err != nil {
 		return err
 	}
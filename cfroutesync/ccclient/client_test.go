@@ -1,12 +1,17 @@
 package ccclient_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/ccclient"
 	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/ccclient/fakes"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/jsonclient"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -16,6 +21,7 @@ var _ = Describe("Cloud Controller Client", func() {
 		ccClient   *ccclient.Client
 		jsonClient *fakes.JSONClient
 		token      string
+		ctx        context.Context
 	)
 
 	BeforeEach(func() {
@@ -25,6 +31,7 @@ var _ = Describe("Cloud Controller Client", func() {
 			BaseURL:    "https://some.base.url",
 		}
 		token = "fake-token"
+		ctx = context.Background()
 	})
 
 	Describe("ListRoutes", func() {
@@ -40,9 +47,7 @@ var _ = Describe("Cloud Controller Client", func() {
 					"last": {
 						"href": "https://api.example.org/v3/routes?page=2&per_page=2"
 					},
-					"next": {
-						"href": "https://api.example.org/v3/routes?page=2&per_page=2"
-					},
+					"next": null,
 					"previous": null
 				},
 				"resources": [{
@@ -50,9 +55,12 @@ var _ = Describe("Cloud Controller Client", func() {
 						"host": "fake-host",
 						"path": "/fake_path",
 						"url": "fake-host.fake-domain.com/fake_path",
+						"protocol": "http",
+						"port": null,
+						"options": { "loadbalancing": "least-connection" },
 						"metadata": {
-							"labels": {},
-							"annotations": {}
+							"labels": { "team": "potato" },
+							"annotations": { "cfroutesync.cloudfoundry.org/timeout": "30s" }
 						},
 						"relationships": {
 							"domain": {
@@ -69,9 +77,11 @@ var _ = Describe("Cloud Controller Client", func() {
 					},
 					{
 						"guid": "fake-guid2",
-						"host": "fake-host2",
-						"path": "/fake_path2",
-						"url": "fake-host2.fake-domain.com/fake_path2",
+						"host": "",
+						"path": "",
+						"url": "fake-tcp-domain.com:1234",
+						"protocol": "tcp",
+						"port": 1234,
 						"relationships": {
 							"domain": {
 								"data": {
@@ -94,22 +104,28 @@ var _ = Describe("Cloud Controller Client", func() {
 		})
 
 		It("returns a list of routes", func() {
-			routeResults, err := ccClient.ListRoutes(token)
+			routeResults, err := ccClient.ListRoutes(ctx, token)
 			Expect(err).To(Not(HaveOccurred()))
 			route1 := ccclient.Route{
-				Guid: "fake-guid",
-				Host: "fake-host",
-				Path: "/fake_path",
-				Url:  "fake-host.fake-domain.com/fake_path",
+				Guid:     "fake-guid",
+				Host:     "fake-host",
+				Path:     "/fake_path",
+				Url:      "fake-host.fake-domain.com/fake_path",
+				Protocol: "http",
+				Options:  ccclient.RouteOptions{Loadbalancing: "least-connection"},
+				Metadata: ccclient.Metadata{
+					Labels:      map[string]string{"team": "potato"},
+					Annotations: map[string]string{"cfroutesync.cloudfoundry.org/timeout": "30s"},
+				},
 			}
 			route1.Relationships.Domain.Data.Guid = "fake-domain-1-guid"
 			route1.Relationships.Space.Data.Guid = "fake-space-1-guid"
 
 			route2 := ccclient.Route{
-				Guid: "fake-guid2",
-				Host: "fake-host2",
-				Path: "/fake_path2",
-				Url:  "fake-host2.fake-domain.com/fake_path2",
+				Guid:     "fake-guid2",
+				Url:      "fake-tcp-domain.com:1234",
+				Protocol: "tcp",
+				Port:     intPtr(1234),
 			}
 			route2.Relationships.Domain.Data.Guid = "fake-domain-2-guid"
 			route2.Relationships.Space.Data.Guid = "fake-space-2-guid"
@@ -120,7 +136,7 @@ var _ = Describe("Cloud Controller Client", func() {
 		})
 
 		It("forms the right request URL", func() {
-			_, err := ccClient.ListRoutes(token)
+			_, err := ccClient.ListRoutes(ctx, token)
 			Expect(err).To(Not(HaveOccurred()))
 
 			receivedRequest, _ := jsonClient.MakeRequestArgsForCall(0)
@@ -128,8 +144,18 @@ var _ = Describe("Cloud Controller Client", func() {
 			Expect(receivedRequest.URL.Path).To(Equal("/v3/routes"))
 		})
 
+		It("makes the request with the provided context", func() {
+			type key struct{}
+			ctx = context.WithValue(ctx, key{}, "some-value")
+			_, err := ccClient.ListRoutes(ctx, token)
+			Expect(err).To(Not(HaveOccurred()))
+
+			receivedRequest, _ := jsonClient.MakeRequestArgsForCall(0)
+			Expect(receivedRequest.Context().Value(key{})).To(Equal("some-value"))
+		})
+
 		It("sets the provided token as an Authorization header on the request", func() {
-			_, err := ccClient.ListRoutes(token)
+			_, err := ccClient.ListRoutes(ctx, token)
 			Expect(err).To(Not(HaveOccurred()))
 
 			receivedRequest, _ := jsonClient.MakeRequestArgsForCall(0)
@@ -139,21 +165,141 @@ var _ = Describe("Cloud Controller Client", func() {
 			Expect(authHeader[0]).To(Equal("bearer fake-token"))
 		})
 
-		Context("this only supports 5000 routes", func() {
-			It("requests 5000 results per page", func() {
-				_, err := ccClient.ListRoutes(token)
-				Expect(err).To(Not(HaveOccurred()))
-				receivedRequest, _ := jsonClient.MakeRequestArgsForCall(0)
-				Expect(receivedRequest.URL.Query()["per_page"]).To(Equal([]string{"5000"}))
-			})
-			It("errors if there is more than one page of results", func() {
-				body := `{ "pagination": { "total_pages": 2 } }`
+		It("requests 5000 results per page", func() {
+			_, err := ccClient.ListRoutes(ctx, token)
+			Expect(err).To(Not(HaveOccurred()))
+			receivedRequest, _ := jsonClient.MakeRequestArgsForCall(0)
+			Expect(receivedRequest.URL.Query()["per_page"]).To(Equal([]string{"5000"}))
+		})
+
+		Context("when there is more than one page of results", func() {
+			var pages map[string]string
+
+			BeforeEach(func() {
+				pages = map[string]string{
+					"1": `{
+						"pagination": {
+							"total_pages": 3,
+							"next": { "href": "https://some.base.url/v3/routes?page=2&per_page=5000" }
+						},
+						"resources": [{ "guid": "route-1-guid" }]
+					}`,
+					"2": `{
+						"pagination": {
+							"total_pages": 3,
+							"next": { "href": "https://some.base.url/v3/routes?page=3&per_page=5000" }
+						},
+						"resources": [{ "guid": "route-2-guid" }, { "guid": "route-3-guid" }]
+					}`,
+					"3": `{
+						"pagination": {
+							"total_pages": 3,
+							"next": null
+						},
+						"resources": [{ "guid": "route-4-guid" }]
+					}`,
+				}
 				jsonClient.MakeRequestStub = func(req *http.Request, responseStruct interface{}) error {
-					return json.Unmarshal([]byte(body), responseStruct)
+					page := req.URL.Query().Get("page")
+					if page == "" {
+						page = "1"
+					}
+					return json.Unmarshal([]byte(pages[page]), responseStruct)
 				}
+			})
 
-				_, err := ccClient.ListRoutes(token)
-				Expect(err).To(MatchError(ContainSubstring("too many results, paging not implemented")))
+			routeGuids := func(routes []ccclient.Route) []string {
+				guids := []string{}
+				for _, route := range routes {
+					guids = append(guids, route.Guid)
+				}
+				return guids
+			}
+
+			It("follows the next links and returns the routes from every page in order", func() {
+				routeResults, err := ccClient.ListRoutes(ctx, token)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(routeGuids(routeResults)).To(Equal([]string{"route-1-guid", "route-2-guid", "route-3-guid", "route-4-guid"}))
+
+				Expect(jsonClient.MakeRequestCallCount()).To(Equal(3))
+				secondRequest, _ := jsonClient.MakeRequestArgsForCall(1)
+				Expect(secondRequest.URL.String()).To(Equal("https://some.base.url/v3/routes?page=2&per_page=5000"))
+				Expect(secondRequest.Header.Get("Authorization")).To(Equal("bearer fake-token"))
+			})
+
+			Context("when a later page fails", func() {
+				BeforeEach(func() {
+					jsonClient.MakeRequestStub = func(req *http.Request, responseStruct interface{}) error {
+						if req.URL.Query().Get("page") == "3" {
+							return errors.New("potato")
+						}
+						return json.Unmarshal([]byte(pages["1"]), responseStruct)
+					}
+					pages["1"] = `{
+						"pagination": {
+							"total_pages": 3,
+							"next": { "href": "https://some.base.url/v3/routes?page=3&per_page=5000" }
+						},
+						"resources": [{ "guid": "route-1-guid" }]
+					}`
+				})
+
+				It("returns the error", func() {
+					_, err := ccClient.ListRoutes(ctx, token)
+					Expect(err).To(MatchError(ContainSubstring("potato")))
+				})
+			})
+
+			Context("when fetching pages concurrently", func() {
+				BeforeEach(func() {
+					ccClient.MaxConcurrentPages = 2
+				})
+
+				It("requests every remaining page by number and returns the routes in page order", func() {
+					routeResults, err := ccClient.ListRoutes(ctx, token)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(routeGuids(routeResults)).To(Equal([]string{"route-1-guid", "route-2-guid", "route-3-guid", "route-4-guid"}))
+
+					Expect(jsonClient.MakeRequestCallCount()).To(Equal(3))
+					requestedPages := []string{}
+					for i := 0; i < 3; i++ {
+						request, _ := jsonClient.MakeRequestArgsForCall(i)
+						Expect(request.URL.Path).To(Equal("/v3/routes"))
+						Expect(request.URL.Query().Get("per_page")).To(Equal("5000"))
+						Expect(request.Header.Get("Authorization")).To(Equal("bearer fake-token"))
+						requestedPages = append(requestedPages, request.URL.Query().Get("page"))
+					}
+					Expect(requestedPages).To(ConsistOf("", "2", "3"))
+				})
+
+				Context("when the context is cancelled", func() {
+					It("stops requesting pages and returns the context error", func() {
+						cancelCtx, cancel := context.WithCancel(ctx)
+						jsonClient.MakeRequestStub = func(req *http.Request, responseStruct interface{}) error {
+							cancel()
+							return json.Unmarshal([]byte(pages["1"]), responseStruct)
+						}
+
+						_, err := ccClient.ListRoutes(cancelCtx, token)
+						Expect(err).To(MatchError(context.Canceled))
+					})
+				})
+
+				Context("when one of the pages fails", func() {
+					BeforeEach(func() {
+						jsonClient.MakeRequestStub = func(req *http.Request, responseStruct interface{}) error {
+							if req.URL.Query().Get("page") == "2" {
+								return errors.New("potato")
+							}
+							return json.Unmarshal([]byte(pages["1"]), responseStruct)
+						}
+					})
+
+					It("returns a helpful error", func() {
+						_, err := ccClient.ListRoutes(ctx, token)
+						Expect(err).To(MatchError(ContainSubstring("page 2: potato")))
+					})
+				})
 			})
 		})
 
@@ -163,7 +309,7 @@ var _ = Describe("Cloud Controller Client", func() {
 			})
 
 			It("returns a helpful error", func() {
-				_, err := ccClient.ListRoutes(token)
+				_, err := ccClient.ListRoutes(ctx, token)
 				Expect(err).To(MatchError(ContainSubstring("potato")))
 			})
 		})
@@ -174,7 +320,7 @@ var _ = Describe("Cloud Controller Client", func() {
 			})
 
 			It("returns a helpful error", func() {
-				_, err := ccClient.ListRoutes(token)
+				_, err := ccClient.ListRoutes(ctx, token)
 				Expect(err).To(MatchError(ContainSubstring("invalid URL escape")))
 			})
 		})
@@ -193,9 +339,7 @@ var _ = Describe("Cloud Controller Client", func() {
 				"last": {
 				  "href": "https://api.example.org/v3/domains?page=2&per_page=2"
 				},
-				"next": {
-				  "href": "https://api.example.org/v3/domains?page=2&per_page=2"
-				},
+				"next": null,
 				"previous": null
 			  },
 			  "resources": [
@@ -212,6 +356,7 @@ var _ = Describe("Cloud Controller Client", func() {
 				  "guid": "fake-domain-2-guid",
 				  "name": "fake-domain2.example.com",
                   "internal": true,
+				  "router_group": { "guid": "fake-router-group-guid" },
 				  "metadata": {
 					"labels": {},
 					"annotations": {}
@@ -226,7 +371,7 @@ var _ = Describe("Cloud Controller Client", func() {
 		})
 
 		It("returns a list of domains", func() {
-			domainResults, err := ccClient.ListDomains(token)
+			domainResults, err := ccClient.ListDomains(ctx, token)
 			Expect(err).To(Not(HaveOccurred()))
 			domain1 := ccclient.Domain{
 				Guid:     "fake-domain-1-guid",
@@ -244,7 +389,7 @@ var _ = Describe("Cloud Controller Client", func() {
 		})
 
 		It("forms the right request URL", func() {
-			_, err := ccClient.ListDomains(token)
+			_, err := ccClient.ListDomains(ctx, token)
 			Expect(err).To(Not(HaveOccurred()))
 
 			receivedRequest, _ := jsonClient.MakeRequestArgsForCall(0)
@@ -253,7 +398,7 @@ var _ = Describe("Cloud Controller Client", func() {
 		})
 
 		It("sets the provided token as an Authorization header on the request", func() {
-			_, err := ccClient.ListDomains(token)
+			_, err := ccClient.ListDomains(ctx, token)
 			Expect(err).To(Not(HaveOccurred()))
 
 			receivedRequest, _ := jsonClient.MakeRequestArgsForCall(0)
@@ -263,22 +408,38 @@ var _ = Describe("Cloud Controller Client", func() {
 			Expect(authHeader[0]).To(Equal("bearer fake-token"))
 		})
 
-		Context("this only supports 5000 domains", func() {
-			It("requests 5000 results per page", func() {
-				_, err := ccClient.ListDomains(token)
-				Expect(err).To(Not(HaveOccurred()))
-				receivedRequest, _ := jsonClient.MakeRequestArgsForCall(0)
-				Expect(receivedRequest.URL.Query()["per_page"]).To(Equal([]string{"5000"}))
-			})
-			It("errors if there is more than one page of results", func() {
-				body := `{ "pagination": { "total_pages": 2 } }`
-				jsonClient.MakeRequestStub = func(req *http.Request, responseStruct interface{}) error {
-					return json.Unmarshal([]byte(body), responseStruct)
+		It("requests 5000 results per page", func() {
+			_, err := ccClient.ListDomains(ctx, token)
+			Expect(err).To(Not(HaveOccurred()))
+			receivedRequest, _ := jsonClient.MakeRequestArgsForCall(0)
+			Expect(receivedRequest.URL.Query()["per_page"]).To(Equal([]string{"5000"}))
+		})
+
+		It("follows the next links when there is more than one page of results", func() {
+			firstPage := `{
+				"pagination": {
+					"total_pages": 2,
+					"next": { "href": "https://some.base.url/v3/domains?page=2&per_page=5000" }
+				},
+				"resources": [{ "guid": "fake-domain-1-guid" }]
+			}`
+			lastPage := `{
+				"pagination": { "total_pages": 2, "next": null },
+				"resources": [{ "guid": "fake-domain-2-guid" }]
+			}`
+			jsonClient.MakeRequestStub = func(req *http.Request, responseStruct interface{}) error {
+				if req.URL.Query().Get("page") == "2" {
+					return json.Unmarshal([]byte(lastPage), responseStruct)
 				}
+				return json.Unmarshal([]byte(firstPage), responseStruct)
+			}
 
-				_, err := ccClient.ListDomains(token)
-				Expect(err).To(MatchError(ContainSubstring("too many results, paging not implemented")))
-			})
+			results, err := ccClient.ListDomains(ctx, token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(HaveLen(2))
+			Expect(results[0].Guid).To(Equal("fake-domain-1-guid"))
+			Expect(results[1].Guid).To(Equal("fake-domain-2-guid"))
+			Expect(jsonClient.MakeRequestCallCount()).To(Equal(2))
 		})
 
 		Context("when the json client returns an error", func() {
@@ -287,7 +448,7 @@ var _ = Describe("Cloud Controller Client", func() {
 			})
 
 			It("returns a helpful error", func() {
-				_, err := ccClient.ListDomains(token)
+				_, err := ccClient.ListDomains(ctx, token)
 				Expect(err).To(MatchError(ContainSubstring("potato")))
 			})
 		})
@@ -298,7 +459,7 @@ var _ = Describe("Cloud Controller Client", func() {
 			})
 
 			It("returns a helpful error", func() {
-				_, err := ccClient.ListDomains(token)
+				_, err := ccClient.ListDomains(ctx, token)
 				Expect(err).To(MatchError(ContainSubstring("invalid URL escape")))
 			})
 		})
@@ -317,9 +478,7 @@ var _ = Describe("Cloud Controller Client", func() {
 				"last": {
 				  "href": "https://api.example.org/v3/spaces?page=2&per_page=2"
 				},
-				"next": {
-				  "href": "https://api.example.org/v3/spaces?page=2&per_page=2"
-				},
+				"next": null,
 				"previous": null
 			  },
 			  "resources": [
@@ -362,7 +521,7 @@ var _ = Describe("Cloud Controller Client", func() {
 		})
 
 		It("returns a list of spaces", func() {
-			spaceResults, err := ccClient.ListSpaces(token)
+			spaceResults, err := ccClient.ListSpaces(ctx, token)
 			Expect(err).To(Not(HaveOccurred()))
 			space1 := ccclient.Space{
 				Guid: "fake-space-1-guid",
@@ -380,7 +539,7 @@ var _ = Describe("Cloud Controller Client", func() {
 		})
 
 		It("forms the right request URL", func() {
-			_, err := ccClient.ListSpaces(token)
+			_, err := ccClient.ListSpaces(ctx, token)
 			Expect(err).To(Not(HaveOccurred()))
 
 			receivedRequest, _ := jsonClient.MakeRequestArgsForCall(0)
@@ -389,7 +548,7 @@ var _ = Describe("Cloud Controller Client", func() {
 		})
 
 		It("sets the provided token as an Authorization header on the request", func() {
-			_, err := ccClient.ListSpaces(token)
+			_, err := ccClient.ListSpaces(ctx, token)
 			Expect(err).To(Not(HaveOccurred()))
 
 			receivedRequest, _ := jsonClient.MakeRequestArgsForCall(0)
@@ -399,22 +558,38 @@ var _ = Describe("Cloud Controller Client", func() {
 			Expect(authHeader[0]).To(Equal("bearer fake-token"))
 		})
 
-		Context("this only supports 5000 spaces", func() {
-			It("requests 5000 results per page", func() {
-				_, err := ccClient.ListSpaces(token)
-				Expect(err).To(Not(HaveOccurred()))
-				receivedRequest, _ := jsonClient.MakeRequestArgsForCall(0)
-				Expect(receivedRequest.URL.Query()["per_page"]).To(Equal([]string{"5000"}))
-			})
-			It("errors if there is more than one page of results", func() {
-				body := `{ "pagination": { "total_pages": 2 } }`
-				jsonClient.MakeRequestStub = func(req *http.Request, responseStruct interface{}) error {
-					return json.Unmarshal([]byte(body), responseStruct)
+		It("requests 5000 results per page", func() {
+			_, err := ccClient.ListSpaces(ctx, token)
+			Expect(err).To(Not(HaveOccurred()))
+			receivedRequest, _ := jsonClient.MakeRequestArgsForCall(0)
+			Expect(receivedRequest.URL.Query()["per_page"]).To(Equal([]string{"5000"}))
+		})
+
+		It("follows the next links when there is more than one page of results", func() {
+			firstPage := `{
+				"pagination": {
+					"total_pages": 2,
+					"next": { "href": "https://some.base.url/v3/spaces?page=2&per_page=5000" }
+				},
+				"resources": [{ "guid": "fake-space-1-guid" }]
+			}`
+			lastPage := `{
+				"pagination": { "total_pages": 2, "next": null },
+				"resources": [{ "guid": "fake-space-2-guid" }]
+			}`
+			jsonClient.MakeRequestStub = func(req *http.Request, responseStruct interface{}) error {
+				if req.URL.Query().Get("page") == "2" {
+					return json.Unmarshal([]byte(lastPage), responseStruct)
 				}
+				return json.Unmarshal([]byte(firstPage), responseStruct)
+			}
 
-				_, err := ccClient.ListSpaces(token)
-				Expect(err).To(MatchError(ContainSubstring("too many results, paging not implemented")))
-			})
+			results, err := ccClient.ListSpaces(ctx, token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(HaveLen(2))
+			Expect(results[0].Guid).To(Equal("fake-space-1-guid"))
+			Expect(results[1].Guid).To(Equal("fake-space-2-guid"))
+			Expect(jsonClient.MakeRequestCallCount()).To(Equal(2))
 		})
 
 		Context("when the json client returns an error", func() {
@@ -423,7 +598,7 @@ var _ = Describe("Cloud Controller Client", func() {
 			})
 
 			It("returns a helpful error", func() {
-				_, err := ccClient.ListSpaces(token)
+				_, err := ccClient.ListSpaces(ctx, token)
 				Expect(err).To(MatchError(ContainSubstring("potato")))
 			})
 		})
@@ -434,9 +609,234 @@ var _ = Describe("Cloud Controller Client", func() {
 			})
 
 			It("returns a helpful error", func() {
-				_, err := ccClient.ListSpaces(token)
+				_, err := ccClient.ListSpaces(ctx, token)
 				Expect(err).To(MatchError(ContainSubstring("invalid URL escape")))
 			})
 		})
 	})
+
+	Describe("ListRouteBindings", func() {
+		BeforeEach(func() {
+			body := `
+			{
+			  "pagination": { "total_pages": 1, "next": null },
+			  "resources": [
+				{
+				  "guid": "fake-binding-1-guid",
+				  "route_service_url": "https://route-service.example.com/auth",
+				  "relationships": {
+					"service_instance": { "data": { "guid": "fake-service-instance-guid" } },
+					"route": { "data": { "guid": "fake-route-1-guid" } }
+				  }
+				}
+			  ]
+			}
+			`
+			jsonClient.MakeRequestStub = func(req *http.Request, responseStruct interface{}) error {
+				return json.Unmarshal([]byte(body), responseStruct)
+			}
+		})
+
+		It("returns a list of route bindings", func() {
+			bindings, err := ccClient.ListRouteBindings(ctx, token)
+			Expect(err).NotTo(HaveOccurred())
+
+			binding := ccclient.RouteBinding{
+				Guid:            "fake-binding-1-guid",
+				RouteServiceUrl: "https://route-service.example.com/auth",
+			}
+			binding.Relationships.Route.Data.Guid = "fake-route-1-guid"
+			Expect(bindings).To(Equal([]ccclient.RouteBinding{binding}))
+		})
+
+		It("forms the right request URL", func() {
+			_, err := ccClient.ListRouteBindings(ctx, token)
+			Expect(err).NotTo(HaveOccurred())
+
+			receivedRequest, _ := jsonClient.MakeRequestArgsForCall(0)
+			Expect(receivedRequest.Method).To(Equal("GET"))
+			Expect(receivedRequest.URL.Path).To(Equal("/v3/service_route_bindings"))
+			Expect(receivedRequest.Header.Get("Authorization")).To(Equal("bearer fake-token"))
+		})
+
+		Context("when the json client returns an error", func() {
+			BeforeEach(func() {
+				jsonClient.MakeRequestReturns(errors.New("potato"))
+			})
+
+			It("returns a helpful error", func() {
+				_, err := ccClient.ListRouteBindings(ctx, token)
+				Expect(err).To(MatchError(ContainSubstring("potato")))
+			})
+		})
+
+		Context("when CC has no service route bindings endpoint", func() {
+			BeforeEach(func() {
+				jsonClient.MakeRequestReturns(&jsonclient.HTTPError{StatusCode: 404, Body: "potato"})
+			})
+
+			It("returns no route bindings", func() {
+				bindings, err := ccClient.ListRouteBindings(ctx, token)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(bindings).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("listing resources updated after a point in time", func() {
+		var after time.Time
+
+		BeforeEach(func() {
+			after = time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedZone("somewhere", 3600))
+			body := `{
+				"pagination": { "total_pages": 1, "next": null },
+				"resources": [{ "guid": "some-guid", "updated_at": "2020-01-02T02:04:07Z" }]
+			}`
+			jsonClient.MakeRequestStub = func(req *http.Request, responseStruct interface{}) error {
+				return json.Unmarshal([]byte(body), responseStruct)
+			}
+		})
+
+		expectUpdatedAfterRequest := func(path string) {
+			Expect(jsonClient.MakeRequestCallCount()).To(Equal(1))
+			receivedRequest, _ := jsonClient.MakeRequestArgsForCall(0)
+			Expect(receivedRequest.URL.Path).To(Equal(path))
+			Expect(receivedRequest.URL.Query().Get("updated_ats[gte]")).To(Equal("2020-01-02T02:04:05Z"))
+			Expect(receivedRequest.URL.Query().Get("per_page")).To(Equal("5000"))
+			Expect(receivedRequest.Header.Get("Authorization")).To(Equal("bearer fake-token"))
+		}
+
+		Specify("ListRoutesUpdatedAfter filters routes by updated_at", func() {
+			routes, err := ccClient.ListRoutesUpdatedAfter(ctx, token, after)
+			Expect(err).NotTo(HaveOccurred())
+			expectUpdatedAfterRequest("/v3/routes")
+			Expect(routes).To(HaveLen(1))
+			Expect(routes[0].Guid).To(Equal("some-guid"))
+			Expect(routes[0].UpdatedAt).To(BeTemporally("==", time.Date(2020, 1, 2, 2, 4, 7, 0, time.UTC)))
+		})
+
+		Specify("ListDomainsUpdatedAfter filters domains by updated_at", func() {
+			domains, err := ccClient.ListDomainsUpdatedAfter(ctx, token, after)
+			Expect(err).NotTo(HaveOccurred())
+			expectUpdatedAfterRequest("/v3/domains")
+			Expect(domains).To(HaveLen(1))
+			Expect(domains[0].UpdatedAt).To(BeTemporally("==", time.Date(2020, 1, 2, 2, 4, 7, 0, time.UTC)))
+		})
+
+		Specify("ListSpacesUpdatedAfter filters spaces by updated_at", func() {
+			spaces, err := ccClient.ListSpacesUpdatedAfter(ctx, token, after)
+			Expect(err).NotTo(HaveOccurred())
+			expectUpdatedAfterRequest("/v3/spaces")
+			Expect(spaces).To(HaveLen(1))
+			Expect(spaces[0].UpdatedAt).To(BeTemporally("==", time.Date(2020, 1, 2, 2, 4, 7, 0, time.UTC)))
+		})
+
+		Specify("ListRouteBindingsUpdatedAfter filters route bindings by updated_at", func() {
+			bindings, err := ccClient.ListRouteBindingsUpdatedAfter(ctx, token, after)
+			Expect(err).NotTo(HaveOccurred())
+			expectUpdatedAfterRequest("/v3/service_route_bindings")
+			Expect(bindings).To(HaveLen(1))
+			Expect(bindings[0].UpdatedAt).To(BeTemporally("==", time.Date(2020, 1, 2, 2, 4, 7, 0, time.UTC)))
+		})
+	})
+
+	Describe("ListRoutesByGuids", func() {
+		BeforeEach(func() {
+			jsonClient.MakeRequestStub = func(req *http.Request, responseStruct interface{}) error {
+				guids := strings.Split(req.URL.Query().Get("guids"), ",")
+				body := fmt.Sprintf(`{
+					"pagination": { "total_pages": 1, "next": null },
+					"resources": [{ "guid": %q }]
+				}`, guids[0])
+				return json.Unmarshal([]byte(body), responseStruct)
+			}
+		})
+
+		It("filters routes by guid", func() {
+			routes, err := ccClient.ListRoutesByGuids(ctx, token, []string{"route-a", "route-b"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(jsonClient.MakeRequestCallCount()).To(Equal(1))
+			receivedRequest, _ := jsonClient.MakeRequestArgsForCall(0)
+			Expect(receivedRequest.URL.Path).To(Equal("/v3/routes"))
+			Expect(receivedRequest.URL.Query().Get("guids")).To(Equal("route-a,route-b"))
+			Expect(receivedRequest.Header.Get("Authorization")).To(Equal("bearer fake-token"))
+			Expect(routes).To(HaveLen(1))
+			Expect(routes[0].Guid).To(Equal("route-a"))
+		})
+
+		It("splits many guids over several requests", func() {
+			var guids []string
+			for i := 0; i < 120; i++ {
+				guids = append(guids, fmt.Sprintf("route-%d", i))
+			}
+
+			routes, err := ccClient.ListRoutesByGuids(ctx, token, guids)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(jsonClient.MakeRequestCallCount()).To(Equal(3))
+			lastRequest, _ := jsonClient.MakeRequestArgsForCall(2)
+			Expect(strings.Split(lastRequest.URL.Query().Get("guids"), ",")).To(Equal(guids[100:]))
+			Expect(routes).To(HaveLen(3))
+		})
+
+		It("does not make a request without guids", func() {
+			routes, err := ccClient.ListRoutesByGuids(ctx, token, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(routes).To(BeEmpty())
+			Expect(jsonClient.MakeRequestCallCount()).To(Equal(0))
+		})
+	})
+
+	Describe("ListAuditEventsCreatedAfter", func() {
+		BeforeEach(func() {
+			body := `{
+				"pagination": { "total_pages": 1, "next": null },
+				"resources": [{
+					"guid": "event-guid",
+					"type": "audit.app.map-route",
+					"created_at": "2020-01-02T02:04:07Z",
+					"target": { "guid": "app-guid", "type": "app", "name": "some-app" },
+					"data": { "route_guid": "route-guid", "app_port": 8080 }
+				}]
+			}`
+			jsonClient.MakeRequestStub = func(req *http.Request, responseStruct interface{}) error {
+				return json.Unmarshal([]byte(body), responseStruct)
+			}
+		})
+
+		It("filters audit events by type and created_at", func() {
+			after := time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedZone("somewhere", 3600))
+			events, err := ccClient.ListAuditEventsCreatedAfter(ctx, token, []string{ccclient.AuditEventTypeMapRoute, ccclient.AuditEventTypeUnmapRoute}, after)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(jsonClient.MakeRequestCallCount()).To(Equal(1))
+			receivedRequest, _ := jsonClient.MakeRequestArgsForCall(0)
+			Expect(receivedRequest.URL.Path).To(Equal("/v3/audit_events"))
+			Expect(receivedRequest.URL.Query().Get("types")).To(Equal("audit.app.map-route,audit.app.unmap-route"))
+			Expect(receivedRequest.URL.Query().Get("created_ats[gte]")).To(Equal("2020-01-02T02:04:05Z"))
+			Expect(receivedRequest.URL.Query().Get("per_page")).To(Equal("5000"))
+			Expect(receivedRequest.Header.Get("Authorization")).To(Equal("bearer fake-token"))
+
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Guid).To(Equal("event-guid"))
+			Expect(events[0].Type).To(Equal(ccclient.AuditEventTypeMapRoute))
+			Expect(events[0].CreatedAt).To(BeTemporally("==", time.Date(2020, 1, 2, 2, 4, 7, 0, time.UTC)))
+			Expect(events[0].Target.Guid).To(Equal("app-guid"))
+			Expect(events[0].Data.RouteGuid).To(Equal("route-guid"))
+		})
+
+		Context("when CC returns an error", func() {
+			It("returns the error", func() {
+				jsonClient.MakeRequestStub = nil
+				jsonClient.MakeRequestReturns(errors.New("potato"))
+				_, err := ccClient.ListAuditEventsCreatedAfter(ctx, token, []string{ccclient.AuditEventTypeMapRoute}, time.Time{})
+				Expect(err).To(MatchError(ContainSubstring("potato")))
+			})
+		})
+	})
 })
+
+func intPtr(x int) *int {
+	return &x
+}
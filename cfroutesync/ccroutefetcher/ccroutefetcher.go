@@ -1,26 +1,60 @@
 package ccroutefetcher
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"path"
+	"sort"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
 	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/ccclient"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/jsonclient"
 	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/policyclient"
 )
 
 //go:generate counterfeiter -o fakes/ccclient.go --fake-name CCClient . ccClient
 type ccClient interface {
-	ListRoutes(token string) ([]ccclient.Route, error)
-	ListDomains(token string) ([]ccclient.Domain, error)
-	ListSpaces(token string) ([]ccclient.Space, error)
+	ListRoutes(ctx context.Context, token string) ([]ccclient.Route, error)
+	ListDomains(ctx context.Context, token string) ([]ccclient.Domain, error)
+	ListSpaces(ctx context.Context, token string) ([]ccclient.Space, error)
+	ListRoutesUpdatedAfter(ctx context.Context, token string, after time.Time) ([]ccclient.Route, error)
+	ListDomainsUpdatedAfter(ctx context.Context, token string, after time.Time) ([]ccclient.Domain, error)
+	ListSpacesUpdatedAfter(ctx context.Context, token string, after time.Time) ([]ccclient.Space, error)
+	ListRouteBindings(ctx context.Context, token string) ([]ccclient.RouteBinding, error)
+	ListRouteBindingsUpdatedAfter(ctx context.Context, token string, after time.Time) ([]ccclient.RouteBinding, error)
+	ListRoutesByGuids(ctx context.Context, token string, guids []string) ([]ccclient.Route, error)
+	ListAuditEventsCreatedAfter(ctx context.Context, token string, types []string, after time.Time) ([]ccclient.AuditEvent, error)
+}
+
+// The audit events of the changes that the updated_at timestamps do not show
+var auditEventTypes = []string{
+	ccclient.AuditEventTypeMapRoute,
+	ccclient.AuditEventTypeUnmapRoute,
+	ccclient.AuditEventTypeDeleteRoute,
+	ccclient.AuditEventTypeDeleteApp,
+	ccclient.AuditEventTypeDeleteSpace,
+	ccclient.AuditEventTypeDeleteRouteBinding,
+}
+
+// How far the clock of CC may be behind ours. Audit events are listed from this long before
+// the start of a full fetch, so that no event is missed when the clocks disagree.
+const auditEventClockSkew = time.Minute
+
+//go:generate counterfeiter -o fakes/policyclient.go --fake-name PolicyClient . policyClient
+type policyClient interface {
+	ListPolicies(ctx context.Context, token string) ([]policyclient.Policy, error)
 }
 
 //go:generate counterfeiter -o fakes/uaaclient.go --fake-name UAAClient . uaaClient
 type uaaClient interface {
-	GetToken() (string, error)
+	GetToken(ctx context.Context) (string, error)
+	InvalidateToken(token string)
 }
 
 //go:generate counterfeiter -o fakes/snapshotrepo.go --fake-name SnapshotRepo . snapshotRepo
@@ -32,62 +66,373 @@ type Fetcher struct {
 	CCClient     ccClient
 	UAAClient    uaaClient
 	SnapshotRepo snapshotRepo
+
+	// Fetches the network policies of the snapshot, which has no policies when this is nil.
+	// The policies are always fetched in full.
+	PolicyClient policyClient
+
+	// When non-zero, fetches only request the routes, domains, spaces and route bindings that changed
+	// since the previous fetch and merge them into the previous snapshot. Mapping or unmapping apps and
+	// deleting routes, apps, spaces and route bindings do not change any updated_at, so these are picked up
+	// from the CC audit events instead. A full fetch still happens this often, which also picks up the
+	// changes no audit event is recorded for, e.g. deleted domains.
+	// Zero, the default, always fetches everything.
+	FullSyncInterval time.Duration
+
+	Now func() time.Time
+
+	resources    *ccResources
+	lastFullSync time.Time
+	// created_at of the newest audit event already applied to the resources
+	eventsWatermark time.Time
+}
+
+// ccResources holds the CC resources that make up a snapshot, keyed by guid
+type ccResources struct {
+	routes        map[string]ccclient.Route
+	domains       map[string]ccclient.Domain
+	spaces        map[string]ccclient.Space
+	routeBindings map[string]ccclient.RouteBinding
 }
 
-// FetchOnce gets all the routing data from CC, builds a snapshot and puts it into the repo
-func (f *Fetcher) FetchOnce() error {
-	token, err := f.UAAClient.GetToken()
+// FetchOnce gets the routing data from CC, builds a snapshot and puts it into the repo
+func (f *Fetcher) FetchOnce(ctx context.Context) error {
+	token, err := f.UAAClient.GetToken(ctx)
 	if err != nil {
 		return fmt.Errorf("uaa get token: %w", err)
 	}
 
-	routes, err := f.CCClient.ListRoutes(token)
+	snapshot, err := f.fetchSnapshot(ctx, token)
+	if isUnauthorized(err) {
+		// CC rejected a token we still considered valid, so get a fresh one and try once more
+		log.WithError(err).Info("cloud controller rejected uaa token, refreshing")
+		f.UAAClient.InvalidateToken(token)
+
+		token, err = f.UAAClient.GetToken(ctx)
+		if err != nil {
+			return fmt.Errorf("uaa get token: %w", err)
+		}
+		snapshot, err = f.fetchSnapshot(ctx, token)
+	}
+	if err != nil {
+		return err
+	}
+
+	f.SnapshotRepo.Put(snapshot)
+	log.WithFields(log.Fields{
+		"snapshot": *snapshot,
+	}).Debug("Fetched and put snapshot")
+
+	return nil
+}
+
+func (f *Fetcher) fetchSnapshot(ctx context.Context, token string) (*models.RouteSnapshot, error) {
+	now := f.Now()
+	fullSync := f.resources == nil || f.FullSyncInterval == 0 || now.Sub(f.lastFullSync) >= f.FullSyncInterval
+
+	var resources *ccResources
+	var eventsWatermark time.Time
+	var err error
+	if fullSync {
+		eventsWatermark = now.Add(-auditEventClockSkew)
+		resources, err = f.fetchAll(ctx, token)
+	} else {
+		resources, eventsWatermark, err = f.fetchUpdates(ctx, token)
+	}
 	if err != nil {
-		return fmt.Errorf("cc list routes: %w", err)
+		return nil, err
 	}
 
-	domains, err := f.CCClient.ListDomains(token)
+	snapshot, err := buildSnapshot(resources)
 	if err != nil {
-		return fmt.Errorf("cc list domains: %w", err)
+		return nil, err
 	}
-	domainsMap := make(map[string]ccclient.Domain)
-	for _, domain := range domains {
-		domainsMap[domain.Guid] = domain
+
+	if f.PolicyClient != nil {
+		policies, err := f.PolicyClient.ListPolicies(ctx, token)
+		if err != nil {
+			return nil, fmt.Errorf("policy server list policies: %w", err)
+		}
+		snapshot.Policies = buildPolicies(policies)
+	}
+
+	// only keep the resources once they have formed a consistent snapshot,
+	// so that a failed fetch is simply retried on the next one
+	f.resources = resources
+	f.eventsWatermark = eventsWatermark
+	if fullSync {
+		f.lastFullSync = now
 	}
+	log.WithFields(log.Fields{"full": fullSync, "routes": len(snapshot.Routes)}).Debug("fetched from cloud controller")
+	return snapshot, nil
+}
+
+func (f *Fetcher) fetchAll(ctx context.Context, token string) (*ccResources, error) {
+	routes, err := f.CCClient.ListRoutes(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("cc list routes: %w", err)
+	}
+
+	domains, err := f.CCClient.ListDomains(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("cc list domains: %w", err)
+	}
+
+	spaces, err := f.CCClient.ListSpaces(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("cc list spaces: %w", err)
+	}
+
+	routeBindings, err := f.CCClient.ListRouteBindings(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("cc list route bindings: %w", err)
+	}
+
+	resources := &ccResources{
+		routes:        make(map[string]ccclient.Route),
+		domains:       make(map[string]ccclient.Domain),
+		spaces:        make(map[string]ccclient.Space),
+		routeBindings: make(map[string]ccclient.RouteBinding),
+	}
+	resources.merge(routes, domains, spaces, routeBindings)
+	return resources, nil
+}
 
-	spaces, err := f.CCClient.ListSpaces(token)
+// fetchUpdates asks CC only for resources updated since the newest ones we already have and for
+// the audit events since the newest one already applied, and applies both to a copy of the previously
+// fetched resources. It returns the created_at of the newest audit event along with the resources.
+func (f *Fetcher) fetchUpdates(ctx context.Context, token string) (*ccResources, time.Time, error) {
+	routesWatermark, domainsWatermark, spacesWatermark, routeBindingsWatermark := f.resources.watermarks()
+
+	routes, err := f.CCClient.ListRoutesUpdatedAfter(ctx, token, routesWatermark)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("cc list routes: %w", err)
+	}
+
+	domains, err := f.CCClient.ListDomainsUpdatedAfter(ctx, token, domainsWatermark)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("cc list domains: %w", err)
+	}
+
+	spaces, err := f.CCClient.ListSpacesUpdatedAfter(ctx, token, spacesWatermark)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("cc list spaces: %w", err)
+	}
+
+	routeBindings, err := f.CCClient.ListRouteBindingsUpdatedAfter(ctx, token, routeBindingsWatermark)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("cc list route bindings: %w", err)
+	}
+
+	events, err := f.CCClient.ListAuditEventsCreatedAfter(ctx, token, auditEventTypes, f.eventsWatermark)
 	if err != nil {
-		return fmt.Errorf("cc list spaces: %w", err)
+		return nil, time.Time{}, fmt.Errorf("cc list audit events: %w", err)
+	}
+
+	resources := f.resources.copy()
+	resources.merge(routes, domains, spaces, routeBindings)
+
+	// the destinations of a route are only listed with the route itself, so the routes that apps
+	// were mapped to or unmapped from are fetched again. Routes that are not found were deleted.
+	remappedRouteGuids := remappedRoutes(events)
+	if len(remappedRouteGuids) > 0 {
+		refetched, err := f.CCClient.ListRoutesByGuids(ctx, token, remappedRouteGuids)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("cc list routes by guids: %w", err)
+		}
+		for _, guid := range remappedRouteGuids {
+			delete(resources.routes, guid)
+		}
+		resources.merge(refetched, nil, nil, nil)
+	}
+	resources.applyDeletions(events)
+
+	eventsWatermark := f.eventsWatermark
+	for _, event := range events {
+		if event.CreatedAt.After(eventsWatermark) {
+			eventsWatermark = event.CreatedAt
+		}
+	}
+	return resources, eventsWatermark, nil
+}
+
+// remappedRoutes returns the guids of the routes that apps were mapped to or unmapped from
+func remappedRoutes(events []ccclient.AuditEvent) []string {
+	seen := make(map[string]bool)
+	var guids []string
+	for _, event := range events {
+		if event.Type != ccclient.AuditEventTypeMapRoute && event.Type != ccclient.AuditEventTypeUnmapRoute {
+			continue
+		}
+		guid := event.Data.RouteGuid
+		if guid == "" || seen[guid] {
+			continue
+		}
+		seen[guid] = true
+		guids = append(guids, guid)
+	}
+	// Sorting so that the requests are stable
+	sort.Strings(guids)
+	return guids
+}
+
+// applyDeletions removes the resources that the audit events record the deletion of. CC deletes routes,
+// apps and spaces asynchronously, so they may still be listed while they are being deleted.
+func (r *ccResources) applyDeletions(events []ccclient.AuditEvent) {
+	for _, event := range events {
+		switch event.Type {
+		case ccclient.AuditEventTypeDeleteRoute:
+			delete(r.routes, event.Target.Guid)
+		case ccclient.AuditEventTypeDeleteRouteBinding:
+			delete(r.routeBindings, event.Target.Guid)
+		case ccclient.AuditEventTypeDeleteSpace:
+			for guid, route := range r.routes {
+				if route.Relationships.Space.Data.Guid == event.Target.Guid {
+					delete(r.routes, guid)
+				}
+			}
+		case ccclient.AuditEventTypeDeleteApp:
+			for guid, route := range r.routes {
+				r.routes[guid] = withoutApp(route, event.Target.Guid)
+			}
+		}
+	}
+}
+
+// withoutApp returns the route without the destinations of the app
+func withoutApp(route ccclient.Route, appGuid string) ccclient.Route {
+	var destinations []ccclient.Destination
+	for _, destination := range route.Destinations {
+		if destination.App.Guid != appGuid {
+			destinations = append(destinations, destination)
+		}
+	}
+	if len(destinations) == len(route.Destinations) {
+		return route
+	}
+	route.Destinations = destinations
+	return route
+}
+
+func (r *ccResources) merge(routes []ccclient.Route, domains []ccclient.Domain, spaces []ccclient.Space, routeBindings []ccclient.RouteBinding) {
+	for _, route := range routes {
+		r.routes[route.Guid] = route
+	}
+	for _, domain := range domains {
+		r.domains[domain.Guid] = domain
 	}
-	spacesMap := make(map[string]ccclient.Space)
 	for _, space := range spaces {
-		spacesMap[space.Guid] = space
+		r.spaces[space.Guid] = space
+	}
+	for _, binding := range routeBindings {
+		r.routeBindings[binding.Guid] = binding
+	}
+}
+
+func (r *ccResources) copy() *ccResources {
+	c := &ccResources{
+		routes:        make(map[string]ccclient.Route, len(r.routes)),
+		domains:       make(map[string]ccclient.Domain, len(r.domains)),
+		spaces:        make(map[string]ccclient.Space, len(r.spaces)),
+		routeBindings: make(map[string]ccclient.RouteBinding, len(r.routeBindings)),
+	}
+	for guid, route := range r.routes {
+		c.routes[guid] = route
+	}
+	for guid, domain := range r.domains {
+		c.domains[guid] = domain
+	}
+	for guid, space := range r.spaces {
+		c.spaces[guid] = space
+	}
+	for guid, binding := range r.routeBindings {
+		c.routeBindings[guid] = binding
+	}
+	return c
+}
+
+// watermarks returns the newest updated_at timestamp of the routes, domains, spaces and route bindings
+func (r *ccResources) watermarks() (routes, domains, spaces, routeBindings time.Time) {
+	for _, route := range r.routes {
+		if route.UpdatedAt.After(routes) {
+			routes = route.UpdatedAt
+		}
+	}
+	for _, domain := range r.domains {
+		if domain.UpdatedAt.After(domains) {
+			domains = domain.UpdatedAt
+		}
+	}
+	for _, space := range r.spaces {
+		if space.UpdatedAt.After(spaces) {
+			spaces = space.UpdatedAt
+		}
+	}
+	for _, binding := range r.routeBindings {
+		if binding.UpdatedAt.After(routeBindings) {
+			routeBindings = binding.UpdatedAt
+		}
+	}
+	return routes, domains, spaces, routeBindings
+}
+
+func buildSnapshot(resources *ccResources) (*models.RouteSnapshot, error) {
+	routeGuids := make([]string, 0, len(resources.routes))
+	for guid := range resources.routes {
+		routeGuids = append(routeGuids, guid)
+	}
+	// Sorting so that the snapshot is stable
+	sort.Strings(routeGuids)
+
+	// a binding of a route that is gone is simply ignored, CC deletes it with the route
+	routeServiceUrls := make(map[string]string, len(resources.routeBindings))
+	for _, binding := range resources.routeBindings {
+		routeServiceUrls[binding.Relationships.Route.Data.Guid] = binding.RouteServiceUrl
 	}
 
 	var snapshotRoutes []models.Route
-	for _, route := range routes {
+	for _, guid := range routeGuids {
+		route := resources.routes[guid]
+
 		routeDomainGuid := route.Relationships.Domain.Data.Guid
-		domain, ok := domainsMap[routeDomainGuid]
+		domain, ok := resources.domains[routeDomainGuid]
 		if !ok {
-			return fmt.Errorf("route %s refers to missing domain %s", route.Guid, routeDomainGuid)
+			return nil, fmt.Errorf("route %s refers to missing domain %s", route.Guid, routeDomainGuid)
 		}
 
 		routeSpaceGuid := route.Relationships.Space.Data.Guid
-		space, ok := spacesMap[routeSpaceGuid]
+		space, ok := resources.spaces[routeSpaceGuid]
 		if !ok {
-			return fmt.Errorf("route %s refers to missing space %s", route.Guid, routeSpaceGuid)
+			return nil, fmt.Errorf("route %s refers to missing space %s", route.Guid, routeSpaceGuid)
 		}
 
-		snapshotRoutes = append(snapshotRoutes, buildRouteForSnapshot(route, domain, space))
+		snapshotRoute := buildRouteForSnapshot(route, domain, space)
+		snapshotRoute.RouteServiceUrl = routeServiceUrls[route.Guid]
+		snapshotRoutes = append(snapshotRoutes, snapshotRoute)
 	}
 
-	snapshot := &models.RouteSnapshot{Routes: snapshotRoutes}
-	f.SnapshotRepo.Put(snapshot)
-	log.WithFields(log.Fields{
-		"snapshot": *snapshot,
-	}).Debug("Fetched and put snapshot")
+	return &models.RouteSnapshot{Routes: snapshotRoutes}, nil
+}
 
-	return nil
+func buildPolicies(policies []policyclient.Policy) []models.Policy {
+	var snapshotPolicies []models.Policy
+	for _, policy := range policies {
+		snapshotPolicies = append(snapshotPolicies, models.Policy{
+			SourceAppGuid:      policy.Source.ID,
+			DestinationAppGuid: policy.Destination.ID,
+			Protocol:           policy.Destination.Protocol,
+			StartPort:          policy.Destination.Ports.Start,
+			EndPort:            policy.Destination.Ports.End,
+		})
+	}
+	// Sorting so that the snapshot is stable
+	models.SortPolicies(snapshotPolicies)
+	return snapshotPolicies
+}
+
+func isUnauthorized(err error) bool {
+	var httpErr *jsonclient.HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusUnauthorized
 }
 
 func buildRouteForSnapshot(route ccclient.Route, domain ccclient.Domain, space ccclient.Space) models.Route {
@@ -99,17 +444,34 @@ func buildRouteForSnapshot(route ccclient.Route, domain ccclient.Domain, space c
 				Guid:    ccDestination.App.Guid,
 				Process: models.Process{Type: ccDestination.App.Process.Type},
 			},
-			Port:   ccDestination.Port,
-			Weight: ccDestination.Weight,
+			Port:     ccDestination.Port,
+			Weight:   ccDestination.Weight,
+			Protocol: ccDestination.Protocol,
 		}
 		snapshotRouteDestinations = append(snapshotRouteDestinations, snapshotDestination)
 	}
 
+	protocol := route.Protocol
+	if protocol == "" {
+		// CC versions without TCP routing in the v3 API only have HTTP routes
+		protocol = models.ProtocolHTTP
+	}
+
+	port := 0
+	if route.Port != nil {
+		port = *route.Port
+	}
+
 	return models.Route{
 		Guid:         route.Guid,
 		Host:         strings.ToLower(route.Host),
 		Path:         route.Path,
 		Url:          normalizedUrl(route, domain),
+		Protocol:     protocol,
+		Port:         port,
+		Options:      models.RouteOptions{LoadBalancing: route.Options.Loadbalancing},
+		Labels:       route.Metadata.Labels,
+		Annotations:  route.Metadata.Annotations,
 		Destinations: snapshotRouteDestinations,
 		Domain: models.Domain{
 			Guid:     domain.Guid,
@@ -124,6 +486,9 @@ func buildRouteForSnapshot(route ccclient.Route, domain ccclient.Domain, space c
 }
 
 func normalizedUrl(route ccclient.Route, domain ccclient.Domain) string {
+	if route.Protocol == models.ProtocolTCP && route.Port != nil {
+		return fmt.Sprintf("%s:%d", strings.ToLower(domain.Name), *route.Port)
+	}
 	fqdn := fmt.Sprintf("%s.%s", strings.ToLower(route.Host), strings.ToLower(domain.Name))
 	return path.Join(fqdn, route.Path)
 }
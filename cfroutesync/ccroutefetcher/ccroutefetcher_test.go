@@ -1,12 +1,17 @@
 package ccroutefetcher_test
 
 import (
+	"context"
 	"errors"
+	"time"
 
 	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/ccclient"
 	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/ccroutefetcher"
 	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/ccroutefetcher/fakes"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/jsonclient"
 	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/policyclient"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/webhook"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -20,13 +25,16 @@ var _ = Describe("Fetching once", func() {
 		expectedSnapshot *models.RouteSnapshot
 		fetcher          *ccroutefetcher.Fetcher
 		routesList       []ccclient.Route
+		ctx              context.Context
 	)
 
 	BeforeEach(func() {
+		ctx = context.Background()
 		fakeRoute0Destination0 := ccclient.Destination{
-			Guid:   "route-0-dest-0-guid",
-			Weight: models.IntPtr(10),
-			Port:   8000,
+			Guid:     "route-0-dest-0-guid",
+			Weight:   models.IntPtr(10),
+			Port:     8000,
+			Protocol: "http2",
 		}
 		fakeRoute0Destination0.App.Guid = "route-0-dest-0-app-0-guid"
 		fakeRoute0Destination0.App.Process.Type = "route-0-dest-0-app-0-process-type"
@@ -51,10 +59,14 @@ var _ = Describe("Fetching once", func() {
 
 		routesList = []ccclient.Route{
 			ccclient.Route{
-				Guid: "route-0-guid",
-				Host: "route-0-host",
-				Path: "/route-0-path",
-				Url:  "route-0-host.domain0.example.com/route-0-path",
+				Guid:    "route-0-guid",
+				Host:    "route-0-host",
+				Path:    "/route-0-path",
+				Url:     "route-0-host.domain0.example.com/route-0-path",
+				Options: ccclient.RouteOptions{Loadbalancing: "round-robin"},
+				Metadata: ccclient.Metadata{
+					Annotations: map[string]string{"cfroutesync.cloudfoundry.org/retries": "3"},
+				},
 				Destinations: []ccclient.Destination{
 					fakeRoute0Destination0,
 					fakeRoute0Destination1,
@@ -118,10 +130,13 @@ var _ = Describe("Fetching once", func() {
 		expectedSnapshot = &models.RouteSnapshot{
 			Routes: []models.Route{
 				models.Route{
-					Guid: "route-0-guid",
-					Host: "route-0-host",
-					Path: "/route-0-path",
-					Url:  "route-0-host.domain0.example.com/route-0-path",
+					Guid:        "route-0-guid",
+					Host:        "route-0-host",
+					Path:        "/route-0-path",
+					Url:         "route-0-host.domain0.example.com/route-0-path",
+					Protocol:    "http",
+					Options:     models.RouteOptions{LoadBalancing: "round-robin"},
+					Annotations: map[string]string{"cfroutesync.cloudfoundry.org/retries": "3"},
 					Domain: models.Domain{
 						Guid:     "domain-0-guid",
 						Name:     "domain0.example.com",
@@ -140,8 +155,9 @@ var _ = Describe("Fetching once", func() {
 								Guid:    "route-0-dest-0-app-0-guid",
 								Process: models.Process{Type: "route-0-dest-0-app-0-process-type"},
 							},
-							Port:   8000,
-							Weight: models.IntPtr(10),
+							Port:     8000,
+							Weight:   models.IntPtr(10),
+							Protocol: "http2",
 						},
 						models.Destination{
 							Guid: "route-0-dest-1-guid",
@@ -155,10 +171,11 @@ var _ = Describe("Fetching once", func() {
 					},
 				},
 				models.Route{
-					Guid: "route-1-guid",
-					Host: "route-1-host",
-					Path: "/route-1-path",
-					Url:  "route-1-host.domain1.apps.internal/route-1-path",
+					Guid:     "route-1-guid",
+					Host:     "route-1-host",
+					Path:     "/route-1-path",
+					Url:      "route-1-host.domain1.apps.internal/route-1-path",
+					Protocol: "http",
 					Domain: models.Domain{
 						Guid:     "domain-1-guid",
 						Name:     "domain1.apps.internal",
@@ -183,10 +200,11 @@ var _ = Describe("Fetching once", func() {
 					},
 				},
 				models.Route{
-					Guid: "route-2-guid",
-					Host: "route-2-host",
-					Path: "/route-2-path",
-					Url:  "route-2-host.domain1.apps.internal/route-2-path",
+					Guid:     "route-2-guid",
+					Host:     "route-2-host",
+					Path:     "/route-2-path",
+					Url:      "route-2-host.domain1.apps.internal/route-2-path",
+					Protocol: "http",
 					Domain: models.Domain{
 						Guid:     "domain-1-guid",
 						Name:     "domain1.apps.internal",
@@ -207,22 +225,34 @@ var _ = Describe("Fetching once", func() {
 			CCClient:     fakeCCClient,
 			UAAClient:    fakeUAAClient,
 			SnapshotRepo: fakeSnapshotRepo,
+			Now:          time.Now,
 		}
 	})
 
+	It("passes the context on to the uaa and cc clients", func() {
+		type key struct{}
+		ctx = context.WithValue(ctx, key{}, "some-value")
+		err := fetcher.FetchOnce(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fakeUAAClient.GetTokenArgsForCall(0).Value(key{})).To(Equal("some-value"))
+		routesCtx, _ := fakeCCClient.ListRoutesArgsForCall(0)
+		Expect(routesCtx.Value(key{})).To(Equal("some-value"))
+	})
+
 	It("calls cc client to get routes and destinations", func() {
-		err := fetcher.FetchOnce()
+		err := fetcher.FetchOnce(ctx)
 		Expect(err).NotTo(HaveOccurred())
 
 		Expect(fakeCCClient.ListRoutesCallCount()).To(Equal(1))
-		token := fakeCCClient.ListRoutesArgsForCall(0)
+		_, token := fakeCCClient.ListRoutesArgsForCall(0)
 		Expect(token).To(Equal("fake-uaa-token"))
 	})
 
 	Context("when there are routes to save", func() {
 		It("converts cc types to a route snapshot and puts that into the repo", func() {
 
-			err := fetcher.FetchOnce()
+			err := fetcher.FetchOnce(ctx)
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(fakeSnapshotRepo.PutCallCount()).To(Equal(1))
@@ -253,10 +283,11 @@ var _ = Describe("Fetching once", func() {
 			expectedSnapshot = &models.RouteSnapshot{
 				Routes: []models.Route{
 					models.Route{
-						Guid: "uppercase-route-guid",
-						Host: "uppercase-host",
-						Path: "/PATH",
-						Url:  "uppercase-host.example.com/PATH",
+						Guid:     "uppercase-route-guid",
+						Host:     "uppercase-host",
+						Path:     "/PATH",
+						Url:      "uppercase-host.example.com/PATH",
+						Protocol: "http",
 						Domain: models.Domain{
 							Guid:     "domain-0-guid",
 							Name:     "example.com",
@@ -271,38 +302,198 @@ var _ = Describe("Fetching once", func() {
 				},
 			}
 
-			err := fetcher.FetchOnce()
+			err := fetcher.FetchOnce(ctx)
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(fakeSnapshotRepo.PutCallCount()).To(Equal(1))
 			Expect(fakeSnapshotRepo.PutArgsForCall(0)).To(Equal(expectedSnapshot))
 		})
+
+		It("carries over the protocol and port of tcp routes", func() {
+			routesList = []ccclient.Route{
+				{
+					Guid:     "tcp-route-guid",
+					Url:      "tcp.example.com:1234",
+					Protocol: "tcp",
+					Port:     models.IntPtr(1234),
+				},
+			}
+			routesList[0].Relationships.Domain.Data.Guid = "tcp-domain-guid"
+			routesList[0].Relationships.Space.Data.Guid = "space-0-guid"
+			fakeCCClient.ListRoutesReturns(routesList, nil)
+
+			fakeCCClient.ListDomainsReturns([]ccclient.Domain{
+				{
+					Guid: "tcp-domain-guid",
+					Name: "TCP.example.com",
+				},
+			}, nil)
+
+			err := fetcher.FetchOnce(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeSnapshotRepo.PutArgsForCall(0).Routes).To(Equal([]models.Route{
+				{
+					Guid:     "tcp-route-guid",
+					Url:      "tcp.example.com:1234",
+					Protocol: "tcp",
+					Port:     1234,
+					Domain: models.Domain{
+						Guid: "tcp-domain-guid",
+						Name: "tcp.example.com",
+					},
+					Space: models.Space{
+						Guid:         "space-0-guid",
+						Organization: models.Organization{Guid: "org-0-guid"},
+					},
+				},
+			}))
+		})
+	})
+
+	Context("when a route is bound to a route service", func() {
+		BeforeEach(func() {
+			binding := ccclient.RouteBinding{Guid: "binding-guid", RouteServiceUrl: "https://route-service.example.com"}
+			binding.Relationships.Route.Data.Guid = "route-1-guid"
+			orphanBinding := ccclient.RouteBinding{Guid: "orphan-binding-guid", RouteServiceUrl: "https://other.example.com"}
+			orphanBinding.Relationships.Route.Data.Guid = "deleted-route-guid"
+			fakeCCClient.ListRouteBindingsReturns([]ccclient.RouteBinding{binding, orphanBinding}, nil)
+		})
+
+		It("carries over the route service url", func() {
+			expectedSnapshot.Routes[1].RouteServiceUrl = "https://route-service.example.com"
+
+			err := fetcher.FetchOnce(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeSnapshotRepo.PutArgsForCall(0)).To(Equal(expectedSnapshot))
+		})
+
+		Context("when no route service secret is configured", func() {
+			It("fetches the binding anyway, so that the bound route is not routed around its route service", func() {
+				routesList[0].Destinations[0].Weight = models.IntPtr(50)
+				routesList[0].Destinations[1].Weight = models.IntPtr(50)
+
+				err := fetcher.FetchOnce(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				builder := &webhook.VirtualServiceBuilder{}
+				resources := builder.Build(fakeSnapshotRepo.PutArgsForCall(0).Routes, webhook.Template{})
+
+				Expect(resources).To(HaveLen(1))
+				Expect(resources[0].(webhook.VirtualService).Spec.Hosts).To(Equal([]string{"route-0-host.domain0.example.com"}))
+			})
+		})
+	})
+
+	Context("when network policies are fetched", func() {
+		var fakePolicyClient *fakes.PolicyClient
+
+		BeforeEach(func() {
+			fakePolicyClient = &fakes.PolicyClient{}
+			fakePolicyClient.ListPoliciesReturns([]policyclient.Policy{
+				{
+					Source:      policyclient.Source{ID: "app-1-guid"},
+					Destination: policyclient.Destination{ID: "app-2-guid", Protocol: "udp", Ports: policyclient.Ports{Start: 5000, End: 5010}},
+				},
+				{
+					Source:      policyclient.Source{ID: "app-0-guid"},
+					Destination: policyclient.Destination{ID: "app-1-guid", Protocol: "tcp", Ports: policyclient.Ports{Start: 8080, End: 8080}},
+				},
+			}, nil)
+			fetcher.PolicyClient = fakePolicyClient
+		})
+
+		It("adds the policies to the snapshot in a stable order", func() {
+			Expect(fetcher.FetchOnce(ctx)).To(Succeed())
+
+			_, token := fakePolicyClient.ListPoliciesArgsForCall(0)
+			Expect(token).To(Equal("fake-uaa-token"))
+			Expect(fakeSnapshotRepo.PutArgsForCall(0).Policies).To(Equal([]models.Policy{
+				{SourceAppGuid: "app-0-guid", DestinationAppGuid: "app-1-guid", Protocol: "tcp", StartPort: 8080, EndPort: 8080},
+				{SourceAppGuid: "app-1-guid", DestinationAppGuid: "app-2-guid", Protocol: "udp", StartPort: 5000, EndPort: 5010},
+			}))
+		})
+
+		Context("when the policy server returns an error", func() {
+			It("returns the error and does not put a snapshot", func() {
+				fakePolicyClient.ListPoliciesReturns(nil, errors.New("potato"))
+
+				Expect(fetcher.FetchOnce(ctx)).To(MatchError("policy server list policies: potato"))
+				Expect(fakeSnapshotRepo.PutCallCount()).To(Equal(0))
+			})
+		})
 	})
 
 	Context("when there is an error getting the token from UAA", func() {
 		It("returns the error", func() {
 			fakeUAAClient.GetTokenReturns("", errors.New("banana"))
-			err := fetcher.FetchOnce()
+			err := fetcher.FetchOnce(ctx)
 			Expect(err).To(MatchError("uaa get token: banana"))
 		})
 	})
 
+	Context("when Cloud Controller rejects the token", func() {
+		BeforeEach(func() {
+			fakeUAAClient.GetTokenReturnsOnCall(0, "revoked-token", nil)
+			fakeUAAClient.GetTokenReturnsOnCall(1, "fresh-token", nil)
+			fakeCCClient.ListRoutesReturnsOnCall(0, nil, &jsonclient.HTTPError{StatusCode: 401, Body: "invalid token"})
+			fakeCCClient.ListRoutesReturnsOnCall(1, routesList, nil)
+		})
+
+		It("invalidates the token and retries with a fresh one", func() {
+			err := fetcher.FetchOnce(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeUAAClient.InvalidateTokenCallCount()).To(Equal(1))
+			Expect(fakeUAAClient.InvalidateTokenArgsForCall(0)).To(Equal("revoked-token"))
+
+			Expect(fakeCCClient.ListRoutesCallCount()).To(Equal(2))
+			_, token := fakeCCClient.ListRoutesArgsForCall(1)
+			Expect(token).To(Equal("fresh-token"))
+			Expect(fakeSnapshotRepo.PutCallCount()).To(Equal(1))
+			Expect(fakeSnapshotRepo.PutArgsForCall(0)).To(Equal(expectedSnapshot))
+		})
+
+		Context("when the fresh token is rejected too", func() {
+			BeforeEach(func() {
+				fakeCCClient.ListRoutesReturnsOnCall(1, nil, &jsonclient.HTTPError{StatusCode: 401, Body: "invalid token"})
+			})
+
+			It("returns the error without retrying again", func() {
+				err := fetcher.FetchOnce(ctx)
+				Expect(err).To(MatchError("cc list routes: bad response, code 401: invalid token"))
+				Expect(fakeCCClient.ListRoutesCallCount()).To(Equal(2))
+				Expect(fakeSnapshotRepo.PutCallCount()).To(Equal(0))
+			})
+		})
+	})
+
 	Context("when there is an error getting Routes from Cloud Controller", func() {
 		It("returns the error", func() {
 			fakeCCClient.ListRoutesReturns(nil, errors.New("potato!"))
-			err := fetcher.FetchOnce()
+			err := fetcher.FetchOnce(ctx)
 			Expect(err).To(MatchError("cc list routes: potato!"))
+			Expect(fakeUAAClient.InvalidateTokenCallCount()).To(Equal(0))
 		})
 	})
 
 	Context("when there is an error getting Domains from Cloud Controller", func() {
 		It("returns the error", func() {
 			fakeCCClient.ListDomainsReturns(nil, errors.New("ohno!"))
-			err := fetcher.FetchOnce()
+			err := fetcher.FetchOnce(ctx)
 			Expect(err).To(MatchError("cc list domains: ohno!"))
 		})
 	})
 
+	Context("when there is an error getting route bindings from Cloud Controller", func() {
+		It("returns the error", func() {
+			fakeCCClient.ListRouteBindingsReturns(nil, errors.New("potato"))
+			err := fetcher.FetchOnce(ctx)
+			Expect(err).To(MatchError("cc list route bindings: potato"))
+		})
+	})
+
 	Context("when a route refers to a domain that was not found", func() {
 		It("returns an error", func() {
 			fakeCCClient.ListDomainsReturns([]ccclient.Domain{
@@ -312,7 +503,7 @@ var _ = Describe("Fetching once", func() {
 					Internal: true,
 				},
 			}, nil)
-			err := fetcher.FetchOnce()
+			err := fetcher.FetchOnce(ctx)
 			Expect(err).To(MatchError("route route-0-guid refers to missing domain domain-0-guid"))
 		})
 	})
@@ -320,7 +511,7 @@ var _ = Describe("Fetching once", func() {
 	Context("when there is an error getting Spaces from Cloud Controller", func() {
 		It("returns the error", func() {
 			fakeCCClient.ListSpacesReturns(nil, errors.New("ohno!"))
-			err := fetcher.FetchOnce()
+			err := fetcher.FetchOnce(ctx)
 			Expect(err).To(MatchError("cc list spaces: ohno!"))
 		})
 	})
@@ -332,8 +523,240 @@ var _ = Describe("Fetching once", func() {
 					Guid: "not-space-0",
 				},
 			}, nil)
-			err := fetcher.FetchOnce()
+			err := fetcher.FetchOnce(ctx)
 			Expect(err).To(MatchError("route route-0-guid refers to missing space space-0-guid"))
 		})
 	})
+
+	Context("when incremental sync is enabled", func() {
+		var (
+			now          time.Time
+			routeUpdated time.Time
+		)
+
+		BeforeEach(func() {
+			now = time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+			routeUpdated = now.Add(-time.Hour)
+			fetcher.FullSyncInterval = 10 * time.Minute
+			fetcher.Now = func() time.Time { return now }
+
+			for i := range routesList {
+				routesList[i].UpdatedAt = routeUpdated.Add(-time.Duration(i) * time.Minute)
+			}
+			fakeCCClient.ListRoutesReturns(routesList, nil)
+
+			Expect(fetcher.FetchOnce(ctx)).To(Succeed())
+			now = now.Add(time.Minute)
+		})
+
+		It("does a full fetch the first time", func() {
+			Expect(fakeCCClient.ListRoutesCallCount()).To(Equal(1))
+			Expect(fakeCCClient.ListDomainsCallCount()).To(Equal(1))
+			Expect(fakeCCClient.ListSpacesCallCount()).To(Equal(1))
+			Expect(fakeSnapshotRepo.PutArgsForCall(0).Routes).To(HaveLen(3))
+		})
+
+		It("only asks for resources updated since the newest ones already fetched", func() {
+			Expect(fetcher.FetchOnce(ctx)).To(Succeed())
+
+			Expect(fakeCCClient.ListRoutesCallCount()).To(Equal(1))
+			Expect(fakeCCClient.ListRoutesUpdatedAfterCallCount()).To(Equal(1))
+			_, token, after := fakeCCClient.ListRoutesUpdatedAfterArgsForCall(0)
+			Expect(token).To(Equal("fake-uaa-token"))
+			Expect(after).To(Equal(routeUpdated))
+
+			Expect(fakeCCClient.ListDomainsUpdatedAfterCallCount()).To(Equal(1))
+			Expect(fakeCCClient.ListSpacesUpdatedAfterCallCount()).To(Equal(1))
+			Expect(fakeCCClient.ListRouteBindingsUpdatedAfterCallCount()).To(Equal(1))
+		})
+
+		It("merges the updates into the previous snapshot", func() {
+			updatedRoute := routesList[1]
+			updatedRoute.Path = "/new-path"
+			updatedRoute.UpdatedAt = now
+			newRoute := ccclient.Route{Guid: "route-3-guid", Host: "route-3-host", UpdatedAt: now}
+			newRoute.Relationships.Domain.Data.Guid = "domain-2-guid"
+			newRoute.Relationships.Space.Data.Guid = "space-0-guid"
+			fakeCCClient.ListRoutesUpdatedAfterReturns([]ccclient.Route{updatedRoute, newRoute}, nil)
+			fakeCCClient.ListDomainsUpdatedAfterReturns([]ccclient.Domain{{Guid: "domain-2-guid", Name: "domain2.example.com"}}, nil)
+
+			Expect(fetcher.FetchOnce(ctx)).To(Succeed())
+
+			snapshot := fakeSnapshotRepo.PutArgsForCall(1)
+			Expect(snapshot.Routes).To(HaveLen(4))
+			Expect(snapshot.Routes[0]).To(Equal(expectedSnapshot.Routes[0]))
+			Expect(snapshot.Routes[1].Path).To(Equal("/new-path"))
+			Expect(snapshot.Routes[2]).To(Equal(expectedSnapshot.Routes[2]))
+			Expect(snapshot.Routes[3].Guid).To(Equal("route-3-guid"))
+			Expect(snapshot.Routes[3].Domain.Name).To(Equal("domain2.example.com"))
+		})
+
+		It("does a full fetch again once the full sync interval has passed", func() {
+			now = now.Add(10 * time.Minute)
+			fakeCCClient.ListRoutesReturns(routesList[:1], nil)
+
+			Expect(fetcher.FetchOnce(ctx)).To(Succeed())
+
+			Expect(fakeCCClient.ListRoutesCallCount()).To(Equal(2))
+			Expect(fakeCCClient.ListRoutesUpdatedAfterCallCount()).To(Equal(0))
+			Expect(fakeSnapshotRepo.PutArgsForCall(1).Routes).To(Equal(expectedSnapshot.Routes[:1]))
+		})
+
+		It("asks for the audit events since shortly before the full fetch", func() {
+			Expect(fetcher.FetchOnce(ctx)).To(Succeed())
+
+			Expect(fakeCCClient.ListAuditEventsCreatedAfterCallCount()).To(Equal(1))
+			_, token, types, after := fakeCCClient.ListAuditEventsCreatedAfterArgsForCall(0)
+			Expect(token).To(Equal("fake-uaa-token"))
+			Expect(types).To(ConsistOf(
+				ccclient.AuditEventTypeMapRoute,
+				ccclient.AuditEventTypeUnmapRoute,
+				ccclient.AuditEventTypeDeleteRoute,
+				ccclient.AuditEventTypeDeleteApp,
+				ccclient.AuditEventTypeDeleteSpace,
+				ccclient.AuditEventTypeDeleteRouteBinding,
+			))
+			Expect(after).To(Equal(time.Date(2020, 1, 1, 11, 59, 0, 0, time.UTC)))
+			Expect(fakeCCClient.ListRoutesByGuidsCallCount()).To(Equal(0))
+		})
+
+		Context("when apps were mapped to or unmapped from routes", func() {
+			var eventCreated time.Time
+
+			BeforeEach(func() {
+				eventCreated = now.Add(-30 * time.Second)
+				mapEvent := ccclient.AuditEvent{Type: ccclient.AuditEventTypeMapRoute, CreatedAt: eventCreated}
+				mapEvent.Data.RouteGuid = "route-1-guid"
+				unmapEvent := ccclient.AuditEvent{Type: ccclient.AuditEventTypeUnmapRoute, CreatedAt: eventCreated.Add(-time.Second)}
+				unmapEvent.Data.RouteGuid = "route-2-guid"
+				fakeCCClient.ListAuditEventsCreatedAfterReturnsOnCall(0, []ccclient.AuditEvent{mapEvent, unmapEvent}, nil)
+
+				remappedRoute := routesList[1]
+				newDestination := ccclient.Destination{Guid: "route-1-dest-1-guid", Port: 8080}
+				newDestination.App.Guid = "new-app-guid"
+				remappedRoute.Destinations = append(remappedRoute.Destinations, newDestination)
+				// route-2 was deleted in the meantime, so it is not found anymore
+				fakeCCClient.ListRoutesByGuidsReturns([]ccclient.Route{remappedRoute}, nil)
+			})
+
+			It("fetches these routes again", func() {
+				Expect(fetcher.FetchOnce(ctx)).To(Succeed())
+
+				Expect(fakeCCClient.ListRoutesByGuidsCallCount()).To(Equal(1))
+				_, token, guids := fakeCCClient.ListRoutesByGuidsArgsForCall(0)
+				Expect(token).To(Equal("fake-uaa-token"))
+				Expect(guids).To(Equal([]string{"route-1-guid", "route-2-guid"}))
+
+				snapshot := fakeSnapshotRepo.PutArgsForCall(1)
+				Expect(snapshot.Routes).To(HaveLen(2))
+				Expect(snapshot.Routes[0]).To(Equal(expectedSnapshot.Routes[0]))
+				Expect(snapshot.Routes[1].Guid).To(Equal("route-1-guid"))
+				Expect(snapshot.Routes[1].Destinations).To(HaveLen(2))
+				Expect(snapshot.Routes[1].Destinations[1].App.Guid).To(Equal("new-app-guid"))
+			})
+
+			It("only asks for audit events since the newest one already applied", func() {
+				Expect(fetcher.FetchOnce(ctx)).To(Succeed())
+				Expect(fetcher.FetchOnce(ctx)).To(Succeed())
+
+				Expect(fakeCCClient.ListAuditEventsCreatedAfterCallCount()).To(Equal(2))
+				_, _, _, after := fakeCCClient.ListAuditEventsCreatedAfterArgsForCall(1)
+				Expect(after).To(Equal(eventCreated))
+			})
+
+			Context("when the routes cannot be fetched again", func() {
+				BeforeEach(func() {
+					fakeCCClient.ListRoutesByGuidsReturns(nil, errors.New("potato"))
+				})
+
+				It("returns the error and asks for the same audit events on the next fetch", func() {
+					err := fetcher.FetchOnce(ctx)
+					Expect(err).To(MatchError("cc list routes by guids: potato"))
+
+					fakeCCClient.ListRoutesByGuidsReturns(nil, nil)
+					Expect(fetcher.FetchOnce(ctx)).To(Succeed())
+					_, _, _, after := fakeCCClient.ListAuditEventsCreatedAfterArgsForCall(1)
+					Expect(after).To(Equal(time.Date(2020, 1, 1, 11, 59, 0, 0, time.UTC)))
+				})
+			})
+		})
+
+		Context("when routes, apps, spaces or route bindings were deleted", func() {
+			It("removes routes that are being deleted", func() {
+				event := ccclient.AuditEvent{Type: ccclient.AuditEventTypeDeleteRoute, CreatedAt: now}
+				event.Target.Guid = "route-1-guid"
+				fakeCCClient.ListAuditEventsCreatedAfterReturns([]ccclient.AuditEvent{event}, nil)
+
+				Expect(fetcher.FetchOnce(ctx)).To(Succeed())
+
+				snapshot := fakeSnapshotRepo.PutArgsForCall(1)
+				Expect(snapshot.Routes).To(Equal([]models.Route{expectedSnapshot.Routes[0], expectedSnapshot.Routes[2]}))
+			})
+
+			It("removes the routes of spaces that are being deleted", func() {
+				event := ccclient.AuditEvent{Type: ccclient.AuditEventTypeDeleteSpace, CreatedAt: now}
+				event.Target.Guid = "space-0-guid"
+				fakeCCClient.ListAuditEventsCreatedAfterReturns([]ccclient.AuditEvent{event}, nil)
+
+				Expect(fetcher.FetchOnce(ctx)).To(Succeed())
+
+				snapshot := fakeSnapshotRepo.PutArgsForCall(1)
+				Expect(snapshot.Routes).To(Equal(expectedSnapshot.Routes[1:]))
+			})
+
+			It("removes route bindings that were deleted", func() {
+				binding := ccclient.RouteBinding{Guid: "binding-guid", RouteServiceUrl: "https://route-service.example.com", UpdatedAt: now}
+				binding.Relationships.Route.Data.Guid = "route-1-guid"
+				fakeCCClient.ListRouteBindingsUpdatedAfterReturnsOnCall(0, []ccclient.RouteBinding{binding}, nil)
+				Expect(fetcher.FetchOnce(ctx)).To(Succeed())
+				Expect(fakeSnapshotRepo.PutArgsForCall(1).Routes[1].RouteServiceUrl).To(Equal("https://route-service.example.com"))
+
+				event := ccclient.AuditEvent{Type: ccclient.AuditEventTypeDeleteRouteBinding, CreatedAt: now}
+				event.Target.Guid = "binding-guid"
+				fakeCCClient.ListAuditEventsCreatedAfterReturns([]ccclient.AuditEvent{event}, nil)
+
+				Expect(fetcher.FetchOnce(ctx)).To(Succeed())
+
+				Expect(fakeSnapshotRepo.PutArgsForCall(2)).To(Equal(expectedSnapshot))
+			})
+
+			It("removes the destinations of apps that are being deleted", func() {
+				event := ccclient.AuditEvent{Type: ccclient.AuditEventTypeDeleteApp, CreatedAt: now}
+				event.Target.Guid = "route-0-dest-1-app-1-guid"
+				fakeCCClient.ListAuditEventsCreatedAfterReturns([]ccclient.AuditEvent{event}, nil)
+
+				Expect(fetcher.FetchOnce(ctx)).To(Succeed())
+
+				snapshot := fakeSnapshotRepo.PutArgsForCall(1)
+				Expect(snapshot.Routes[0].Destinations).To(Equal(expectedSnapshot.Routes[0].Destinations[:1]))
+				Expect(snapshot.Routes[1:]).To(Equal(expectedSnapshot.Routes[1:]))
+			})
+		})
+
+		Context("when there is an error getting audit events from Cloud Controller", func() {
+			It("returns the error", func() {
+				fakeCCClient.ListAuditEventsCreatedAfterReturns(nil, errors.New("potato"))
+				err := fetcher.FetchOnce(ctx)
+				Expect(err).To(MatchError("cc list audit events: potato"))
+			})
+		})
+
+		Context("when the updates cannot be merged into a consistent snapshot", func() {
+			BeforeEach(func() {
+				orphanRoute := ccclient.Route{Guid: "orphan-route-guid", UpdatedAt: now}
+				orphanRoute.Relationships.Domain.Data.Guid = "not-yet-fetched-domain-guid"
+				fakeCCClient.ListRoutesUpdatedAfterReturnsOnCall(0, []ccclient.Route{orphanRoute}, nil)
+			})
+
+			It("returns an error and does not keep any of the updates", func() {
+				err := fetcher.FetchOnce(ctx)
+				Expect(err).To(MatchError("route orphan-route-guid refers to missing domain not-yet-fetched-domain-guid"))
+
+				Expect(fetcher.FetchOnce(ctx)).To(Succeed())
+				_, _, after := fakeCCClient.ListRoutesUpdatedAfterArgsForCall(1)
+				Expect(after).To(Equal(routeUpdated))
+				Expect(fakeSnapshotRepo.PutArgsForCall(1)).To(Equal(expectedSnapshot))
+			})
+		})
+	})
 })
@@ -2,30 +2,35 @@
 package fakes
 
 import (
+	"context"
 	"sync"
+	"time"
 
 	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/ccclient"
 )
 
 type CCClient struct {
-	ListDestinationsForRouteStub        func(string, string) ([]ccclient.Destination, error)
-	listDestinationsForRouteMutex       sync.RWMutex
-	listDestinationsForRouteArgsForCall []struct {
-		arg1 string
+	ListAuditEventsCreatedAfterStub        func(context.Context, string, []string, time.Time) ([]ccclient.AuditEvent, error)
+	listAuditEventsCreatedAfterMutex       sync.RWMutex
+	listAuditEventsCreatedAfterArgsForCall []struct {
+		arg1 context.Context
 		arg2 string
+		arg3 []string
+		arg4 time.Time
 	}
-	listDestinationsForRouteReturns struct {
-		result1 []ccclient.Destination
+	listAuditEventsCreatedAfterReturns struct {
+		result1 []ccclient.AuditEvent
 		result2 error
 	}
-	listDestinationsForRouteReturnsOnCall map[int]struct {
-		result1 []ccclient.Destination
+	listAuditEventsCreatedAfterReturnsOnCall map[int]struct {
+		result1 []ccclient.AuditEvent
 		result2 error
 	}
-	ListDomainsStub        func(string) ([]ccclient.Domain, error)
+	ListDomainsStub        func(context.Context, string) ([]ccclient.Domain, error)
 	listDomainsMutex       sync.RWMutex
 	listDomainsArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
+		arg2 string
 	}
 	listDomainsReturns struct {
 		result1 []ccclient.Domain
@@ -35,10 +40,55 @@ type CCClient struct {
 		result1 []ccclient.Domain
 		result2 error
 	}
-	ListRoutesStub        func(string) ([]ccclient.Route, error)
+	ListDomainsUpdatedAfterStub        func(context.Context, string, time.Time) ([]ccclient.Domain, error)
+	listDomainsUpdatedAfterMutex       sync.RWMutex
+	listDomainsUpdatedAfterArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 time.Time
+	}
+	listDomainsUpdatedAfterReturns struct {
+		result1 []ccclient.Domain
+		result2 error
+	}
+	listDomainsUpdatedAfterReturnsOnCall map[int]struct {
+		result1 []ccclient.Domain
+		result2 error
+	}
+	ListRouteBindingsStub        func(context.Context, string) ([]ccclient.RouteBinding, error)
+	listRouteBindingsMutex       sync.RWMutex
+	listRouteBindingsArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	listRouteBindingsReturns struct {
+		result1 []ccclient.RouteBinding
+		result2 error
+	}
+	listRouteBindingsReturnsOnCall map[int]struct {
+		result1 []ccclient.RouteBinding
+		result2 error
+	}
+	ListRouteBindingsUpdatedAfterStub        func(context.Context, string, time.Time) ([]ccclient.RouteBinding, error)
+	listRouteBindingsUpdatedAfterMutex       sync.RWMutex
+	listRouteBindingsUpdatedAfterArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 time.Time
+	}
+	listRouteBindingsUpdatedAfterReturns struct {
+		result1 []ccclient.RouteBinding
+		result2 error
+	}
+	listRouteBindingsUpdatedAfterReturnsOnCall map[int]struct {
+		result1 []ccclient.RouteBinding
+		result2 error
+	}
+	ListRoutesStub        func(context.Context, string) ([]ccclient.Route, error)
 	listRoutesMutex       sync.RWMutex
 	listRoutesArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
+		arg2 string
 	}
 	listRoutesReturns struct {
 		result1 []ccclient.Route
@@ -48,10 +98,41 @@ type CCClient struct {
 		result1 []ccclient.Route
 		result2 error
 	}
-	ListSpacesStub        func(string) ([]ccclient.Space, error)
+	ListRoutesByGuidsStub        func(context.Context, string, []string) ([]ccclient.Route, error)
+	listRoutesByGuidsMutex       sync.RWMutex
+	listRoutesByGuidsArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 []string
+	}
+	listRoutesByGuidsReturns struct {
+		result1 []ccclient.Route
+		result2 error
+	}
+	listRoutesByGuidsReturnsOnCall map[int]struct {
+		result1 []ccclient.Route
+		result2 error
+	}
+	ListRoutesUpdatedAfterStub        func(context.Context, string, time.Time) ([]ccclient.Route, error)
+	listRoutesUpdatedAfterMutex       sync.RWMutex
+	listRoutesUpdatedAfterArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 time.Time
+	}
+	listRoutesUpdatedAfterReturns struct {
+		result1 []ccclient.Route
+		result2 error
+	}
+	listRoutesUpdatedAfterReturnsOnCall map[int]struct {
+		result1 []ccclient.Route
+		result2 error
+	}
+	ListSpacesStub        func(context.Context, string) ([]ccclient.Space, error)
 	listSpacesMutex       sync.RWMutex
 	listSpacesArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
+		arg2 string
 	}
 	listSpacesReturns struct {
 		result1 []ccclient.Space
@@ -61,84 +142,107 @@ type CCClient struct {
 		result1 []ccclient.Space
 		result2 error
 	}
+	ListSpacesUpdatedAfterStub        func(context.Context, string, time.Time) ([]ccclient.Space, error)
+	listSpacesUpdatedAfterMutex       sync.RWMutex
+	listSpacesUpdatedAfterArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 time.Time
+	}
+	listSpacesUpdatedAfterReturns struct {
+		result1 []ccclient.Space
+		result2 error
+	}
+	listSpacesUpdatedAfterReturnsOnCall map[int]struct {
+		result1 []ccclient.Space
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *CCClient) ListDestinationsForRoute(arg1 string, arg2 string) ([]ccclient.Destination, error) {
-	fake.listDestinationsForRouteMutex.Lock()
-	ret, specificReturn := fake.listDestinationsForRouteReturnsOnCall[len(fake.listDestinationsForRouteArgsForCall)]
-	fake.listDestinationsForRouteArgsForCall = append(fake.listDestinationsForRouteArgsForCall, struct {
-		arg1 string
+func (fake *CCClient) ListAuditEventsCreatedAfter(arg1 context.Context, arg2 string, arg3 []string, arg4 time.Time) ([]ccclient.AuditEvent, error) {
+	var arg3Copy []string
+	if arg3 != nil {
+		arg3Copy = make([]string, len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.listAuditEventsCreatedAfterMutex.Lock()
+	ret, specificReturn := fake.listAuditEventsCreatedAfterReturnsOnCall[len(fake.listAuditEventsCreatedAfterArgsForCall)]
+	fake.listAuditEventsCreatedAfterArgsForCall = append(fake.listAuditEventsCreatedAfterArgsForCall, struct {
+		arg1 context.Context
 		arg2 string
-	}{arg1, arg2})
-	fake.recordInvocation("ListDestinationsForRoute", []interface{}{arg1, arg2})
-	fake.listDestinationsForRouteMutex.Unlock()
-	if fake.ListDestinationsForRouteStub != nil {
-		return fake.ListDestinationsForRouteStub(arg1, arg2)
+		arg3 []string
+		arg4 time.Time
+	}{arg1, arg2, arg3Copy, arg4})
+	fake.recordInvocation("ListAuditEventsCreatedAfter", []interface{}{arg1, arg2, arg3Copy, arg4})
+	fake.listAuditEventsCreatedAfterMutex.Unlock()
+	if fake.ListAuditEventsCreatedAfterStub != nil {
+		return fake.ListAuditEventsCreatedAfterStub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
 	}
-	fakeReturns := fake.listDestinationsForRouteReturns
+	fakeReturns := fake.listAuditEventsCreatedAfterReturns
 	return fakeReturns.result1, fakeReturns.result2
 }
 
-func (fake *CCClient) ListDestinationsForRouteCallCount() int {
-	fake.listDestinationsForRouteMutex.RLock()
-	defer fake.listDestinationsForRouteMutex.RUnlock()
-	return len(fake.listDestinationsForRouteArgsForCall)
+func (fake *CCClient) ListAuditEventsCreatedAfterCallCount() int {
+	fake.listAuditEventsCreatedAfterMutex.RLock()
+	defer fake.listAuditEventsCreatedAfterMutex.RUnlock()
+	return len(fake.listAuditEventsCreatedAfterArgsForCall)
 }
 
-func (fake *CCClient) ListDestinationsForRouteCalls(stub func(string, string) ([]ccclient.Destination, error)) {
-	fake.listDestinationsForRouteMutex.Lock()
-	defer fake.listDestinationsForRouteMutex.Unlock()
-	fake.ListDestinationsForRouteStub = stub
+func (fake *CCClient) ListAuditEventsCreatedAfterCalls(stub func(context.Context, string, []string, time.Time) ([]ccclient.AuditEvent, error)) {
+	fake.listAuditEventsCreatedAfterMutex.Lock()
+	defer fake.listAuditEventsCreatedAfterMutex.Unlock()
+	fake.ListAuditEventsCreatedAfterStub = stub
 }
 
-func (fake *CCClient) ListDestinationsForRouteArgsForCall(i int) (string, string) {
-	fake.listDestinationsForRouteMutex.RLock()
-	defer fake.listDestinationsForRouteMutex.RUnlock()
-	argsForCall := fake.listDestinationsForRouteArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+func (fake *CCClient) ListAuditEventsCreatedAfterArgsForCall(i int) (context.Context, string, []string, time.Time) {
+	fake.listAuditEventsCreatedAfterMutex.RLock()
+	defer fake.listAuditEventsCreatedAfterMutex.RUnlock()
+	argsForCall := fake.listAuditEventsCreatedAfterArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
-func (fake *CCClient) ListDestinationsForRouteReturns(result1 []ccclient.Destination, result2 error) {
-	fake.listDestinationsForRouteMutex.Lock()
-	defer fake.listDestinationsForRouteMutex.Unlock()
-	fake.ListDestinationsForRouteStub = nil
-	fake.listDestinationsForRouteReturns = struct {
-		result1 []ccclient.Destination
+func (fake *CCClient) ListAuditEventsCreatedAfterReturns(result1 []ccclient.AuditEvent, result2 error) {
+	fake.listAuditEventsCreatedAfterMutex.Lock()
+	defer fake.listAuditEventsCreatedAfterMutex.Unlock()
+	fake.ListAuditEventsCreatedAfterStub = nil
+	fake.listAuditEventsCreatedAfterReturns = struct {
+		result1 []ccclient.AuditEvent
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *CCClient) ListDestinationsForRouteReturnsOnCall(i int, result1 []ccclient.Destination, result2 error) {
-	fake.listDestinationsForRouteMutex.Lock()
-	defer fake.listDestinationsForRouteMutex.Unlock()
-	fake.ListDestinationsForRouteStub = nil
-	if fake.listDestinationsForRouteReturnsOnCall == nil {
-		fake.listDestinationsForRouteReturnsOnCall = make(map[int]struct {
-			result1 []ccclient.Destination
+func (fake *CCClient) ListAuditEventsCreatedAfterReturnsOnCall(i int, result1 []ccclient.AuditEvent, result2 error) {
+	fake.listAuditEventsCreatedAfterMutex.Lock()
+	defer fake.listAuditEventsCreatedAfterMutex.Unlock()
+	fake.ListAuditEventsCreatedAfterStub = nil
+	if fake.listAuditEventsCreatedAfterReturnsOnCall == nil {
+		fake.listAuditEventsCreatedAfterReturnsOnCall = make(map[int]struct {
+			result1 []ccclient.AuditEvent
 			result2 error
 		})
 	}
-	fake.listDestinationsForRouteReturnsOnCall[i] = struct {
-		result1 []ccclient.Destination
+	fake.listAuditEventsCreatedAfterReturnsOnCall[i] = struct {
+		result1 []ccclient.AuditEvent
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *CCClient) ListDomains(arg1 string) ([]ccclient.Domain, error) {
+func (fake *CCClient) ListDomains(arg1 context.Context, arg2 string) ([]ccclient.Domain, error) {
 	fake.listDomainsMutex.Lock()
 	ret, specificReturn := fake.listDomainsReturnsOnCall[len(fake.listDomainsArgsForCall)]
 	fake.listDomainsArgsForCall = append(fake.listDomainsArgsForCall, struct {
-		arg1 string
-	}{arg1})
-	fake.recordInvocation("ListDomains", []interface{}{arg1})
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	fake.recordInvocation("ListDomains", []interface{}{arg1, arg2})
 	fake.listDomainsMutex.Unlock()
 	if fake.ListDomainsStub != nil {
-		return fake.ListDomainsStub(arg1)
+		return fake.ListDomainsStub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -153,17 +257,17 @@ func (fake *CCClient) ListDomainsCallCount() int {
 	return len(fake.listDomainsArgsForCall)
 }
 
-func (fake *CCClient) ListDomainsCalls(stub func(string) ([]ccclient.Domain, error)) {
+func (fake *CCClient) ListDomainsCalls(stub func(context.Context, string) ([]ccclient.Domain, error)) {
 	fake.listDomainsMutex.Lock()
 	defer fake.listDomainsMutex.Unlock()
 	fake.ListDomainsStub = stub
 }
 
-func (fake *CCClient) ListDomainsArgsForCall(i int) string {
+func (fake *CCClient) ListDomainsArgsForCall(i int) (context.Context, string) {
 	fake.listDomainsMutex.RLock()
 	defer fake.listDomainsMutex.RUnlock()
 	argsForCall := fake.listDomainsArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *CCClient) ListDomainsReturns(result1 []ccclient.Domain, result2 error) {
@@ -192,16 +296,211 @@ func (fake *CCClient) ListDomainsReturnsOnCall(i int, result1 []ccclient.Domain,
 	}{result1, result2}
 }
 
-func (fake *CCClient) ListRoutes(arg1 string) ([]ccclient.Route, error) {
+func (fake *CCClient) ListDomainsUpdatedAfter(arg1 context.Context, arg2 string, arg3 time.Time) ([]ccclient.Domain, error) {
+	fake.listDomainsUpdatedAfterMutex.Lock()
+	ret, specificReturn := fake.listDomainsUpdatedAfterReturnsOnCall[len(fake.listDomainsUpdatedAfterArgsForCall)]
+	fake.listDomainsUpdatedAfterArgsForCall = append(fake.listDomainsUpdatedAfterArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 time.Time
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("ListDomainsUpdatedAfter", []interface{}{arg1, arg2, arg3})
+	fake.listDomainsUpdatedAfterMutex.Unlock()
+	if fake.ListDomainsUpdatedAfterStub != nil {
+		return fake.ListDomainsUpdatedAfterStub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.listDomainsUpdatedAfterReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CCClient) ListDomainsUpdatedAfterCallCount() int {
+	fake.listDomainsUpdatedAfterMutex.RLock()
+	defer fake.listDomainsUpdatedAfterMutex.RUnlock()
+	return len(fake.listDomainsUpdatedAfterArgsForCall)
+}
+
+func (fake *CCClient) ListDomainsUpdatedAfterCalls(stub func(context.Context, string, time.Time) ([]ccclient.Domain, error)) {
+	fake.listDomainsUpdatedAfterMutex.Lock()
+	defer fake.listDomainsUpdatedAfterMutex.Unlock()
+	fake.ListDomainsUpdatedAfterStub = stub
+}
+
+func (fake *CCClient) ListDomainsUpdatedAfterArgsForCall(i int) (context.Context, string, time.Time) {
+	fake.listDomainsUpdatedAfterMutex.RLock()
+	defer fake.listDomainsUpdatedAfterMutex.RUnlock()
+	argsForCall := fake.listDomainsUpdatedAfterArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CCClient) ListDomainsUpdatedAfterReturns(result1 []ccclient.Domain, result2 error) {
+	fake.listDomainsUpdatedAfterMutex.Lock()
+	defer fake.listDomainsUpdatedAfterMutex.Unlock()
+	fake.ListDomainsUpdatedAfterStub = nil
+	fake.listDomainsUpdatedAfterReturns = struct {
+		result1 []ccclient.Domain
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CCClient) ListDomainsUpdatedAfterReturnsOnCall(i int, result1 []ccclient.Domain, result2 error) {
+	fake.listDomainsUpdatedAfterMutex.Lock()
+	defer fake.listDomainsUpdatedAfterMutex.Unlock()
+	fake.ListDomainsUpdatedAfterStub = nil
+	if fake.listDomainsUpdatedAfterReturnsOnCall == nil {
+		fake.listDomainsUpdatedAfterReturnsOnCall = make(map[int]struct {
+			result1 []ccclient.Domain
+			result2 error
+		})
+	}
+	fake.listDomainsUpdatedAfterReturnsOnCall[i] = struct {
+		result1 []ccclient.Domain
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CCClient) ListRouteBindings(arg1 context.Context, arg2 string) ([]ccclient.RouteBinding, error) {
+	fake.listRouteBindingsMutex.Lock()
+	ret, specificReturn := fake.listRouteBindingsReturnsOnCall[len(fake.listRouteBindingsArgsForCall)]
+	fake.listRouteBindingsArgsForCall = append(fake.listRouteBindingsArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	fake.recordInvocation("ListRouteBindings", []interface{}{arg1, arg2})
+	fake.listRouteBindingsMutex.Unlock()
+	if fake.ListRouteBindingsStub != nil {
+		return fake.ListRouteBindingsStub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.listRouteBindingsReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CCClient) ListRouteBindingsCallCount() int {
+	fake.listRouteBindingsMutex.RLock()
+	defer fake.listRouteBindingsMutex.RUnlock()
+	return len(fake.listRouteBindingsArgsForCall)
+}
+
+func (fake *CCClient) ListRouteBindingsCalls(stub func(context.Context, string) ([]ccclient.RouteBinding, error)) {
+	fake.listRouteBindingsMutex.Lock()
+	defer fake.listRouteBindingsMutex.Unlock()
+	fake.ListRouteBindingsStub = stub
+}
+
+func (fake *CCClient) ListRouteBindingsArgsForCall(i int) (context.Context, string) {
+	fake.listRouteBindingsMutex.RLock()
+	defer fake.listRouteBindingsMutex.RUnlock()
+	argsForCall := fake.listRouteBindingsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *CCClient) ListRouteBindingsReturns(result1 []ccclient.RouteBinding, result2 error) {
+	fake.listRouteBindingsMutex.Lock()
+	defer fake.listRouteBindingsMutex.Unlock()
+	fake.ListRouteBindingsStub = nil
+	fake.listRouteBindingsReturns = struct {
+		result1 []ccclient.RouteBinding
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CCClient) ListRouteBindingsReturnsOnCall(i int, result1 []ccclient.RouteBinding, result2 error) {
+	fake.listRouteBindingsMutex.Lock()
+	defer fake.listRouteBindingsMutex.Unlock()
+	fake.ListRouteBindingsStub = nil
+	if fake.listRouteBindingsReturnsOnCall == nil {
+		fake.listRouteBindingsReturnsOnCall = make(map[int]struct {
+			result1 []ccclient.RouteBinding
+			result2 error
+		})
+	}
+	fake.listRouteBindingsReturnsOnCall[i] = struct {
+		result1 []ccclient.RouteBinding
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CCClient) ListRouteBindingsUpdatedAfter(arg1 context.Context, arg2 string, arg3 time.Time) ([]ccclient.RouteBinding, error) {
+	fake.listRouteBindingsUpdatedAfterMutex.Lock()
+	ret, specificReturn := fake.listRouteBindingsUpdatedAfterReturnsOnCall[len(fake.listRouteBindingsUpdatedAfterArgsForCall)]
+	fake.listRouteBindingsUpdatedAfterArgsForCall = append(fake.listRouteBindingsUpdatedAfterArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 time.Time
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("ListRouteBindingsUpdatedAfter", []interface{}{arg1, arg2, arg3})
+	fake.listRouteBindingsUpdatedAfterMutex.Unlock()
+	if fake.ListRouteBindingsUpdatedAfterStub != nil {
+		return fake.ListRouteBindingsUpdatedAfterStub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.listRouteBindingsUpdatedAfterReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CCClient) ListRouteBindingsUpdatedAfterCallCount() int {
+	fake.listRouteBindingsUpdatedAfterMutex.RLock()
+	defer fake.listRouteBindingsUpdatedAfterMutex.RUnlock()
+	return len(fake.listRouteBindingsUpdatedAfterArgsForCall)
+}
+
+func (fake *CCClient) ListRouteBindingsUpdatedAfterCalls(stub func(context.Context, string, time.Time) ([]ccclient.RouteBinding, error)) {
+	fake.listRouteBindingsUpdatedAfterMutex.Lock()
+	defer fake.listRouteBindingsUpdatedAfterMutex.Unlock()
+	fake.ListRouteBindingsUpdatedAfterStub = stub
+}
+
+func (fake *CCClient) ListRouteBindingsUpdatedAfterArgsForCall(i int) (context.Context, string, time.Time) {
+	fake.listRouteBindingsUpdatedAfterMutex.RLock()
+	defer fake.listRouteBindingsUpdatedAfterMutex.RUnlock()
+	argsForCall := fake.listRouteBindingsUpdatedAfterArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CCClient) ListRouteBindingsUpdatedAfterReturns(result1 []ccclient.RouteBinding, result2 error) {
+	fake.listRouteBindingsUpdatedAfterMutex.Lock()
+	defer fake.listRouteBindingsUpdatedAfterMutex.Unlock()
+	fake.ListRouteBindingsUpdatedAfterStub = nil
+	fake.listRouteBindingsUpdatedAfterReturns = struct {
+		result1 []ccclient.RouteBinding
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CCClient) ListRouteBindingsUpdatedAfterReturnsOnCall(i int, result1 []ccclient.RouteBinding, result2 error) {
+	fake.listRouteBindingsUpdatedAfterMutex.Lock()
+	defer fake.listRouteBindingsUpdatedAfterMutex.Unlock()
+	fake.ListRouteBindingsUpdatedAfterStub = nil
+	if fake.listRouteBindingsUpdatedAfterReturnsOnCall == nil {
+		fake.listRouteBindingsUpdatedAfterReturnsOnCall = make(map[int]struct {
+			result1 []ccclient.RouteBinding
+			result2 error
+		})
+	}
+	fake.listRouteBindingsUpdatedAfterReturnsOnCall[i] = struct {
+		result1 []ccclient.RouteBinding
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CCClient) ListRoutes(arg1 context.Context, arg2 string) ([]ccclient.Route, error) {
 	fake.listRoutesMutex.Lock()
 	ret, specificReturn := fake.listRoutesReturnsOnCall[len(fake.listRoutesArgsForCall)]
 	fake.listRoutesArgsForCall = append(fake.listRoutesArgsForCall, struct {
-		arg1 string
-	}{arg1})
-	fake.recordInvocation("ListRoutes", []interface{}{arg1})
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	fake.recordInvocation("ListRoutes", []interface{}{arg1, arg2})
 	fake.listRoutesMutex.Unlock()
 	if fake.ListRoutesStub != nil {
-		return fake.ListRoutesStub(arg1)
+		return fake.ListRoutesStub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -216,17 +515,17 @@ func (fake *CCClient) ListRoutesCallCount() int {
 	return len(fake.listRoutesArgsForCall)
 }
 
-func (fake *CCClient) ListRoutesCalls(stub func(string) ([]ccclient.Route, error)) {
+func (fake *CCClient) ListRoutesCalls(stub func(context.Context, string) ([]ccclient.Route, error)) {
 	fake.listRoutesMutex.Lock()
 	defer fake.listRoutesMutex.Unlock()
 	fake.ListRoutesStub = stub
 }
 
-func (fake *CCClient) ListRoutesArgsForCall(i int) string {
+func (fake *CCClient) ListRoutesArgsForCall(i int) (context.Context, string) {
 	fake.listRoutesMutex.RLock()
 	defer fake.listRoutesMutex.RUnlock()
 	argsForCall := fake.listRoutesArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *CCClient) ListRoutesReturns(result1 []ccclient.Route, result2 error) {
@@ -255,16 +554,154 @@ func (fake *CCClient) ListRoutesReturnsOnCall(i int, result1 []ccclient.Route, r
 	}{result1, result2}
 }
 
-func (fake *CCClient) ListSpaces(arg1 string) ([]ccclient.Space, error) {
+func (fake *CCClient) ListRoutesByGuids(arg1 context.Context, arg2 string, arg3 []string) ([]ccclient.Route, error) {
+	var arg3Copy []string
+	if arg3 != nil {
+		arg3Copy = make([]string, len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.listRoutesByGuidsMutex.Lock()
+	ret, specificReturn := fake.listRoutesByGuidsReturnsOnCall[len(fake.listRoutesByGuidsArgsForCall)]
+	fake.listRoutesByGuidsArgsForCall = append(fake.listRoutesByGuidsArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 []string
+	}{arg1, arg2, arg3Copy})
+	fake.recordInvocation("ListRoutesByGuids", []interface{}{arg1, arg2, arg3Copy})
+	fake.listRoutesByGuidsMutex.Unlock()
+	if fake.ListRoutesByGuidsStub != nil {
+		return fake.ListRoutesByGuidsStub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.listRoutesByGuidsReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CCClient) ListRoutesByGuidsCallCount() int {
+	fake.listRoutesByGuidsMutex.RLock()
+	defer fake.listRoutesByGuidsMutex.RUnlock()
+	return len(fake.listRoutesByGuidsArgsForCall)
+}
+
+func (fake *CCClient) ListRoutesByGuidsCalls(stub func(context.Context, string, []string) ([]ccclient.Route, error)) {
+	fake.listRoutesByGuidsMutex.Lock()
+	defer fake.listRoutesByGuidsMutex.Unlock()
+	fake.ListRoutesByGuidsStub = stub
+}
+
+func (fake *CCClient) ListRoutesByGuidsArgsForCall(i int) (context.Context, string, []string) {
+	fake.listRoutesByGuidsMutex.RLock()
+	defer fake.listRoutesByGuidsMutex.RUnlock()
+	argsForCall := fake.listRoutesByGuidsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CCClient) ListRoutesByGuidsReturns(result1 []ccclient.Route, result2 error) {
+	fake.listRoutesByGuidsMutex.Lock()
+	defer fake.listRoutesByGuidsMutex.Unlock()
+	fake.ListRoutesByGuidsStub = nil
+	fake.listRoutesByGuidsReturns = struct {
+		result1 []ccclient.Route
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CCClient) ListRoutesByGuidsReturnsOnCall(i int, result1 []ccclient.Route, result2 error) {
+	fake.listRoutesByGuidsMutex.Lock()
+	defer fake.listRoutesByGuidsMutex.Unlock()
+	fake.ListRoutesByGuidsStub = nil
+	if fake.listRoutesByGuidsReturnsOnCall == nil {
+		fake.listRoutesByGuidsReturnsOnCall = make(map[int]struct {
+			result1 []ccclient.Route
+			result2 error
+		})
+	}
+	fake.listRoutesByGuidsReturnsOnCall[i] = struct {
+		result1 []ccclient.Route
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CCClient) ListRoutesUpdatedAfter(arg1 context.Context, arg2 string, arg3 time.Time) ([]ccclient.Route, error) {
+	fake.listRoutesUpdatedAfterMutex.Lock()
+	ret, specificReturn := fake.listRoutesUpdatedAfterReturnsOnCall[len(fake.listRoutesUpdatedAfterArgsForCall)]
+	fake.listRoutesUpdatedAfterArgsForCall = append(fake.listRoutesUpdatedAfterArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 time.Time
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("ListRoutesUpdatedAfter", []interface{}{arg1, arg2, arg3})
+	fake.listRoutesUpdatedAfterMutex.Unlock()
+	if fake.ListRoutesUpdatedAfterStub != nil {
+		return fake.ListRoutesUpdatedAfterStub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.listRoutesUpdatedAfterReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CCClient) ListRoutesUpdatedAfterCallCount() int {
+	fake.listRoutesByGuidsMutex.RLock()
+	defer fake.listRoutesByGuidsMutex.RUnlock()
+	fake.listRoutesUpdatedAfterMutex.RLock()
+	defer fake.listRoutesUpdatedAfterMutex.RUnlock()
+	return len(fake.listRoutesUpdatedAfterArgsForCall)
+}
+
+func (fake *CCClient) ListRoutesUpdatedAfterCalls(stub func(context.Context, string, time.Time) ([]ccclient.Route, error)) {
+	fake.listRoutesUpdatedAfterMutex.Lock()
+	defer fake.listRoutesUpdatedAfterMutex.Unlock()
+	fake.ListRoutesUpdatedAfterStub = stub
+}
+
+func (fake *CCClient) ListRoutesUpdatedAfterArgsForCall(i int) (context.Context, string, time.Time) {
+	fake.listRoutesUpdatedAfterMutex.RLock()
+	defer fake.listRoutesUpdatedAfterMutex.RUnlock()
+	argsForCall := fake.listRoutesUpdatedAfterArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CCClient) ListRoutesUpdatedAfterReturns(result1 []ccclient.Route, result2 error) {
+	fake.listRoutesUpdatedAfterMutex.Lock()
+	defer fake.listRoutesUpdatedAfterMutex.Unlock()
+	fake.ListRoutesUpdatedAfterStub = nil
+	fake.listRoutesUpdatedAfterReturns = struct {
+		result1 []ccclient.Route
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CCClient) ListRoutesUpdatedAfterReturnsOnCall(i int, result1 []ccclient.Route, result2 error) {
+	fake.listRoutesUpdatedAfterMutex.Lock()
+	defer fake.listRoutesUpdatedAfterMutex.Unlock()
+	fake.ListRoutesUpdatedAfterStub = nil
+	if fake.listRoutesUpdatedAfterReturnsOnCall == nil {
+		fake.listRoutesUpdatedAfterReturnsOnCall = make(map[int]struct {
+			result1 []ccclient.Route
+			result2 error
+		})
+	}
+	fake.listRoutesUpdatedAfterReturnsOnCall[i] = struct {
+		result1 []ccclient.Route
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CCClient) ListSpaces(arg1 context.Context, arg2 string) ([]ccclient.Space, error) {
 	fake.listSpacesMutex.Lock()
 	ret, specificReturn := fake.listSpacesReturnsOnCall[len(fake.listSpacesArgsForCall)]
 	fake.listSpacesArgsForCall = append(fake.listSpacesArgsForCall, struct {
-		arg1 string
-	}{arg1})
-	fake.recordInvocation("ListSpaces", []interface{}{arg1})
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	fake.recordInvocation("ListSpaces", []interface{}{arg1, arg2})
 	fake.listSpacesMutex.Unlock()
 	if fake.ListSpacesStub != nil {
-		return fake.ListSpacesStub(arg1)
+		return fake.ListSpacesStub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -279,17 +716,17 @@ func (fake *CCClient) ListSpacesCallCount() int {
 	return len(fake.listSpacesArgsForCall)
 }
 
-func (fake *CCClient) ListSpacesCalls(stub func(string) ([]ccclient.Space, error)) {
+func (fake *CCClient) ListSpacesCalls(stub func(context.Context, string) ([]ccclient.Space, error)) {
 	fake.listSpacesMutex.Lock()
 	defer fake.listSpacesMutex.Unlock()
 	fake.ListSpacesStub = stub
 }
 
-func (fake *CCClient) ListSpacesArgsForCall(i int) string {
+func (fake *CCClient) ListSpacesArgsForCall(i int) (context.Context, string) {
 	fake.listSpacesMutex.RLock()
 	defer fake.listSpacesMutex.RUnlock()
 	argsForCall := fake.listSpacesArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *CCClient) ListSpacesReturns(result1 []ccclient.Space, result2 error) {
@@ -318,17 +755,92 @@ func (fake *CCClient) ListSpacesReturnsOnCall(i int, result1 []ccclient.Space, r
 	}{result1, result2}
 }
 
+func (fake *CCClient) ListSpacesUpdatedAfter(arg1 context.Context, arg2 string, arg3 time.Time) ([]ccclient.Space, error) {
+	fake.listSpacesUpdatedAfterMutex.Lock()
+	ret, specificReturn := fake.listSpacesUpdatedAfterReturnsOnCall[len(fake.listSpacesUpdatedAfterArgsForCall)]
+	fake.listSpacesUpdatedAfterArgsForCall = append(fake.listSpacesUpdatedAfterArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 time.Time
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("ListSpacesUpdatedAfter", []interface{}{arg1, arg2, arg3})
+	fake.listSpacesUpdatedAfterMutex.Unlock()
+	if fake.ListSpacesUpdatedAfterStub != nil {
+		return fake.ListSpacesUpdatedAfterStub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.listSpacesUpdatedAfterReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CCClient) ListSpacesUpdatedAfterCallCount() int {
+	fake.listSpacesUpdatedAfterMutex.RLock()
+	defer fake.listSpacesUpdatedAfterMutex.RUnlock()
+	return len(fake.listSpacesUpdatedAfterArgsForCall)
+}
+
+func (fake *CCClient) ListSpacesUpdatedAfterCalls(stub func(context.Context, string, time.Time) ([]ccclient.Space, error)) {
+	fake.listSpacesUpdatedAfterMutex.Lock()
+	defer fake.listSpacesUpdatedAfterMutex.Unlock()
+	fake.ListSpacesUpdatedAfterStub = stub
+}
+
+func (fake *CCClient) ListSpacesUpdatedAfterArgsForCall(i int) (context.Context, string, time.Time) {
+	fake.listSpacesUpdatedAfterMutex.RLock()
+	defer fake.listSpacesUpdatedAfterMutex.RUnlock()
+	argsForCall := fake.listSpacesUpdatedAfterArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CCClient) ListSpacesUpdatedAfterReturns(result1 []ccclient.Space, result2 error) {
+	fake.listSpacesUpdatedAfterMutex.Lock()
+	defer fake.listSpacesUpdatedAfterMutex.Unlock()
+	fake.ListSpacesUpdatedAfterStub = nil
+	fake.listSpacesUpdatedAfterReturns = struct {
+		result1 []ccclient.Space
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CCClient) ListSpacesUpdatedAfterReturnsOnCall(i int, result1 []ccclient.Space, result2 error) {
+	fake.listSpacesUpdatedAfterMutex.Lock()
+	defer fake.listSpacesUpdatedAfterMutex.Unlock()
+	fake.ListSpacesUpdatedAfterStub = nil
+	if fake.listSpacesUpdatedAfterReturnsOnCall == nil {
+		fake.listSpacesUpdatedAfterReturnsOnCall = make(map[int]struct {
+			result1 []ccclient.Space
+			result2 error
+		})
+	}
+	fake.listSpacesUpdatedAfterReturnsOnCall[i] = struct {
+		result1 []ccclient.Space
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *CCClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
-	fake.listDestinationsForRouteMutex.RLock()
-	defer fake.listDestinationsForRouteMutex.RUnlock()
+	fake.listAuditEventsCreatedAfterMutex.RLock()
+	defer fake.listAuditEventsCreatedAfterMutex.RUnlock()
 	fake.listDomainsMutex.RLock()
 	defer fake.listDomainsMutex.RUnlock()
+	fake.listDomainsUpdatedAfterMutex.RLock()
+	defer fake.listDomainsUpdatedAfterMutex.RUnlock()
+	fake.listRouteBindingsMutex.RLock()
+	defer fake.listRouteBindingsMutex.RUnlock()
+	fake.listRouteBindingsUpdatedAfterMutex.RLock()
+	defer fake.listRouteBindingsUpdatedAfterMutex.RUnlock()
 	fake.listRoutesMutex.RLock()
 	defer fake.listRoutesMutex.RUnlock()
+	fake.listRoutesUpdatedAfterMutex.RLock()
+	defer fake.listRoutesUpdatedAfterMutex.RUnlock()
 	fake.listSpacesMutex.RLock()
 	defer fake.listSpacesMutex.RUnlock()
+	fake.listSpacesUpdatedAfterMutex.RLock()
+	defer fake.listSpacesUpdatedAfterMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -2,13 +2,15 @@
 package fakes
 
 import (
+	"context"
 	"sync"
 )
 
 type UAAClient struct {
-	GetTokenStub        func() (string, error)
+	GetTokenStub        func(context.Context) (string, error)
 	getTokenMutex       sync.RWMutex
 	getTokenArgsForCall []struct {
+		arg1 context.Context
 	}
 	getTokenReturns struct {
 		result1 string
@@ -18,19 +20,25 @@ type UAAClient struct {
 		result1 string
 		result2 error
 	}
+	InvalidateTokenStub        func(string)
+	invalidateTokenMutex       sync.RWMutex
+	invalidateTokenArgsForCall []struct {
+		arg1 string
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *UAAClient) GetToken() (string, error) {
+func (fake *UAAClient) GetToken(arg1 context.Context) (string, error) {
 	fake.getTokenMutex.Lock()
 	ret, specificReturn := fake.getTokenReturnsOnCall[len(fake.getTokenArgsForCall)]
 	fake.getTokenArgsForCall = append(fake.getTokenArgsForCall, struct {
-	}{})
-	fake.recordInvocation("GetToken", []interface{}{})
+		arg1 context.Context
+	}{arg1})
+	fake.recordInvocation("GetToken", []interface{}{arg1})
 	fake.getTokenMutex.Unlock()
 	if fake.GetTokenStub != nil {
-		return fake.GetTokenStub()
+		return fake.GetTokenStub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -45,12 +53,19 @@ func (fake *UAAClient) GetTokenCallCount() int {
 	return len(fake.getTokenArgsForCall)
 }
 
-func (fake *UAAClient) GetTokenCalls(stub func() (string, error)) {
+func (fake *UAAClient) GetTokenCalls(stub func(context.Context) (string, error)) {
 	fake.getTokenMutex.Lock()
 	defer fake.getTokenMutex.Unlock()
 	fake.GetTokenStub = stub
 }
 
+func (fake *UAAClient) GetTokenArgsForCall(i int) context.Context {
+	fake.getTokenMutex.RLock()
+	defer fake.getTokenMutex.RUnlock()
+	argsForCall := fake.getTokenArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *UAAClient) GetTokenReturns(result1 string, result2 error) {
 	fake.getTokenMutex.Lock()
 	defer fake.getTokenMutex.Unlock()
@@ -77,11 +92,44 @@ func (fake *UAAClient) GetTokenReturnsOnCall(i int, result1 string, result2 erro
 	}{result1, result2}
 }
 
+func (fake *UAAClient) InvalidateToken(arg1 string) {
+	fake.invalidateTokenMutex.Lock()
+	fake.invalidateTokenArgsForCall = append(fake.invalidateTokenArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.recordInvocation("InvalidateToken", []interface{}{arg1})
+	fake.invalidateTokenMutex.Unlock()
+	if fake.InvalidateTokenStub != nil {
+		fake.InvalidateTokenStub(arg1)
+	}
+}
+
+func (fake *UAAClient) InvalidateTokenCallCount() int {
+	fake.invalidateTokenMutex.RLock()
+	defer fake.invalidateTokenMutex.RUnlock()
+	return len(fake.invalidateTokenArgsForCall)
+}
+
+func (fake *UAAClient) InvalidateTokenCalls(stub func(string)) {
+	fake.invalidateTokenMutex.Lock()
+	defer fake.invalidateTokenMutex.Unlock()
+	fake.InvalidateTokenStub = stub
+}
+
+func (fake *UAAClient) InvalidateTokenArgsForCall(i int) string {
+	fake.invalidateTokenMutex.RLock()
+	defer fake.invalidateTokenMutex.RUnlock()
+	argsForCall := fake.invalidateTokenArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *UAAClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.getTokenMutex.RLock()
 	defer fake.getTokenMutex.RUnlock()
+	fake.invalidateTokenMutex.RLock()
+	defer fake.invalidateTokenMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
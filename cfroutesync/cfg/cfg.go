@@ -2,11 +2,14 @@ package cfg
 
 import (
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
@@ -30,11 +33,109 @@ type Config struct {
 
 		// Certificate authority that signed the Cloud Controller server cert
 		CA *x509.CertPool
+
+		// Maximum number of list pages to fetch from Cloud Controller concurrently, defaults to 1
+		MaxConcurrentPages int
+
+		// When set, only changed resources and audit events are fetched from Cloud Controller in
+		// between full fetches that happen this often. Deleted domains are only picked up by the
+		// full fetches. Defaults to 0, which always fetches everything.
+		FullSyncInterval time.Duration
+
+		// Time between fetches from Cloud Controller, defaults to 3s, must be positive
+		FetchInterval time.Duration
+
+		// Upper bound for the time between fetches while they keep failing, defaults to 1m,
+		// must be at least FetchInterval
+		MaxBackoff time.Duration
+	}
+
+	// Either SyncModeMetacontroller or SyncModeController, defaults to SyncModeMetacontroller
+	SyncMode string
+
+	// Path of a file to persist the latest snapshot to, so that it can be served after a restart.
+	// Snapshots are only kept in memory when this is empty.
+	SnapshotPath string
+
+	// Secret shared with nothing but cfroutesync itself, to sign the requests sent through route services
+	// and verify the ones they send back. Routes bound to a route service are not routed when it is empty.
+	RouteServiceSecret string
+
+	// Readiness fails and reports the snapshot as stale when the last successful sync is older than this.
+	// 0 never reports it as stale.
+	ReadinessStaleThreshold time.Duration
+
+	LeaderElection struct {
+		// Run several replicas, of which only the elected leader fetches from Cloud Controller
+		Enabled bool
+
+		// Name and namespace of the pod cfroutesync runs in, the Lease is created in the same namespace
+		PodName      string
+		PodNamespace string
+
+		// IP address the other replicas replicate the snapshot from while this replica is the leader
+		PodIP string
+
+		// Secret shared by the replicas, the followers present it to the leader to replicate its snapshot
+		ReplicationToken string
+	}
+
+	NetworkPolicies struct {
+		// Enforce the container to container network policies of the CF policy server with NetworkPolicies.
+		// The policy server is reached at the Cloud Controller URL, and the UAA client needs the network.admin scope.
+		Enabled bool
+
+		// Labels of the namespaces whose pods can always connect to app pods, like the ingress gateway
+		AllowedNamespaceSelector map[string]string
+	}
+
+	// One of the RoutingBackend constants, defaults to RoutingBackendIstio
+	RoutingBackend string
+
+	// Namespace of the app pods and the Services selecting them, defaults to cf-workloads
+	WorkloadsNamespace string
+
+	GatewayAPI struct {
+		// Gateways the HTTPRoutes and TCPRoutes attach to, required for RoutingBackendGatewayAPI
+		ParentRefs []NamespacedName
+	}
+
+	Ingress struct {
+		// Class of the ingress controller that serves the Ingresses of RoutingBackendIngress,
+		// empty uses the default class of the cluster
+		ClassName string
+	}
+
+	XDS struct {
+		// Port the xDS gRPC server listens on for Envoys, defaults to 18000
+		Port int
+
+		// Port Envoy listens on for HTTP traffic, defaults to 8080
+		HTTPListenerPort int
 	}
 
 	Istio struct {
 		// List of Istio Gateway names to use for workload ingress
 		Gateways []string
+
+		// Labels of the ingress gateway pods that serve TCP routes
+		IngressGatewaySelector map[string]string
+
+		// Cookie the sticky sessions of HTTP routes hash on, empty uses webhook.DefaultSessionCookieName,
+		// the JSESSIONID of gorouter
+		StickySessionCookieName string
+
+		HTTPS struct {
+			// Build a Gateway with an HTTPS server for every domain of the routes
+			Enabled bool
+
+			// Names of the TLS secrets in the namespace of the ingress gateway by domain,
+			// domains without one use the secret named by webhook.TLSSecretName
+			TLSSecrets map[string]string
+
+			// Redirect plain HTTP requests for the domains to HTTPS
+			Redirect bool
+		}
 	}
 }
 
@@ -45,6 +146,62 @@ const (
 	FileUAACA           = "uaaCA"
 	FileCCBaseURL       = "ccBaseURL"
 	FileCCCA            = "ccCA"
+
+	FileCCMaxConcurrentPages    = "ccMaxConcurrentPages"
+	FileCCFullSyncInterval      = "ccFullSyncInterval"
+	FileCCFetchInterval         = "ccFetchInterval"
+	FileCCMaxBackoff            = "ccMaxBackoff"
+	FileSnapshotPath            = "snapshotPath"
+	FileReadinessStaleThreshold = "readinessStaleThreshold"
+	FileRouteServiceSecret      = "routeServiceSecret"
+	FileRoutingBackend          = "routingBackend"
+	FileWorkloadsNamespace      = "workloadsNamespace"
+	FileGatewayAPIParentRefs    = "gatewayAPIParentRefs"
+	FileIngressClassName        = "ingressClassName"
+	FileXDSPort                 = "xdsPort"
+	FileXDSHTTPListenerPort     = "xdsHTTPListenerPort"
+	FileSyncMode                = "syncMode"
+	FileLeaderElection          = "leaderElection"
+	FilePodName                 = "podName"
+	FilePodNamespace            = "podNamespace"
+	FilePodIP                   = "podIP"
+	FileReplicationToken        = "replicationToken"
+	FileNetworkPolicies         = "networkPolicies"
+	FileHTTPSGateway            = "httpsGateway"
+	FileTLSSecrets              = "tlsSecrets"
+	FileHTTPSRedirect           = "httpsRedirect"
+	FileStickySessionCookieName = "stickySessionCookieName"
+)
+
+const (
+	// Routes are Istio VirtualServices
+	RoutingBackendIstio = "istio"
+
+	// Routes are Kubernetes Gateway API HTTPRoutes and TCPRoutes
+	RoutingBackendGatewayAPI = "gateway-api"
+
+	// Routes are Contour HTTPProxies
+	RoutingBackendContour = "contour"
+
+	// Routes are Kubernetes Ingresses
+	RoutingBackendIngress = "ingress"
+
+	// Routes are served directly to Envoy by the built-in xDS server
+	RoutingBackendXDS = "xds"
+)
+
+// NamespacedName refers to a resource in a namespace, an empty namespace is the namespace of the referring resource
+type NamespacedName struct {
+	Namespace string
+	Name      string
+}
+
+const (
+	// Children are created by metacontroller calling the /sync webhook
+	SyncModeMetacontroller = "metacontroller"
+
+	// Children are applied to the API server by cfroutesync itself
+	SyncModeController = "controller"
 )
 
 // Load loads a Config from environment variables or files within a directory on disk
@@ -80,6 +237,138 @@ func Load(configDir string) (*Config, error) {
 		return nil, err
 	}
 
+	ccMaxConcurrentPages, err := loadOptionalInt(configDir, FileCCMaxConcurrentPages, 1)
+	if err != nil {
+		return nil, err
+	}
+	if ccMaxConcurrentPages < 1 {
+		return nil, fmt.Errorf("invalid %s %d, must be at least 1", FileCCMaxConcurrentPages, ccMaxConcurrentPages)
+	}
+
+	ccFullSyncInterval, err := loadOptionalDuration(configDir, FileCCFullSyncInterval, 0)
+	if err != nil {
+		return nil, err
+	}
+	if ccFullSyncInterval < 0 {
+		return nil, fmt.Errorf("invalid %s %s, must not be negative", FileCCFullSyncInterval, ccFullSyncInterval)
+	}
+
+	ccFetchInterval, err := loadOptionalDuration(configDir, FileCCFetchInterval, 3*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	ccMaxBackoff, err := loadOptionalDuration(configDir, FileCCMaxBackoff, time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if ccFetchInterval <= 0 {
+		return nil, fmt.Errorf("invalid %s %s, must be positive", FileCCFetchInterval, ccFetchInterval)
+	}
+	if ccMaxBackoff < ccFetchInterval {
+		// the backoff starts at the fetch interval and only grows from there
+		return nil, fmt.Errorf("invalid %s %s, must be at least %s %s", FileCCMaxBackoff, ccMaxBackoff, FileCCFetchInterval, ccFetchInterval)
+	}
+
+	readinessStaleThreshold, err := loadOptionalDuration(configDir, FileReadinessStaleThreshold, 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	routeServiceSecret, err := loadOptionalValue(configDir, FileRouteServiceSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotPath, err := loadOptionalValue(configDir, FileSnapshotPath)
+	if err != nil {
+		return nil, err
+	}
+
+	syncMode, err := loadOptionalValue(configDir, FileSyncMode)
+	if err != nil {
+		return nil, err
+	}
+	switch syncMode {
+	case "":
+		syncMode = SyncModeMetacontroller
+	case SyncModeMetacontroller, SyncModeController:
+	default:
+		return nil, fmt.Errorf("invalid %s %q, must be %q or %q", FileSyncMode, syncMode, SyncModeMetacontroller, SyncModeController)
+	}
+
+	routingBackend, err := loadOptionalValue(configDir, FileRoutingBackend)
+	if err != nil {
+		return nil, err
+	}
+	var gatewayAPIParentRefs []NamespacedName
+	switch routingBackend {
+	case "":
+		routingBackend = RoutingBackendIstio
+	case RoutingBackendIstio, RoutingBackendContour, RoutingBackendIngress, RoutingBackendXDS:
+	case RoutingBackendGatewayAPI:
+		gatewayAPIParentRefs, err = loadNamespacedNames(configDir, FileGatewayAPIParentRefs)
+		if err != nil {
+			return nil, fmt.Errorf("routing backend %s requires %s: %w", routingBackend, FileGatewayAPIParentRefs, err)
+		}
+	default:
+		return nil, fmt.Errorf("invalid %s %q, must be %q, %q, %q, %q or %q", FileRoutingBackend, routingBackend,
+			RoutingBackendIstio, RoutingBackendGatewayAPI, RoutingBackendContour, RoutingBackendIngress, RoutingBackendXDS)
+	}
+
+	workloadsNamespace, err := loadOptionalValue(configDir, FileWorkloadsNamespace)
+	if err != nil {
+		return nil, err
+	}
+	if workloadsNamespace == "" {
+		workloadsNamespace = "cf-workloads"
+	}
+
+	ingressClassName, err := loadOptionalValue(configDir, FileIngressClassName)
+	if err != nil {
+		return nil, err
+	}
+
+	xdsPort, err := loadOptionalInt(configDir, FileXDSPort, 18000)
+	if err != nil {
+		return nil, err
+	}
+
+	xdsHTTPListenerPort, err := loadOptionalInt(configDir, FileXDSHTTPListenerPort, 8080)
+	if err != nil {
+		return nil, err
+	}
+
+	leaderElection, err := loadOptionalBool(configDir, FileLeaderElection, false)
+	if err != nil {
+		return nil, err
+	}
+
+	networkPolicies, err := loadOptionalBool(configDir, FileNetworkPolicies, false)
+	if err != nil {
+		return nil, err
+	}
+
+	httpsGateway, err := loadOptionalBool(configDir, FileHTTPSGateway, false)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsSecrets, err := loadOptionalMapping(configDir, FileTLSSecrets)
+	if err != nil {
+		return nil, err
+	}
+
+	httpsRedirect, err := loadOptionalBool(configDir, FileHTTPSRedirect, false)
+	if err != nil {
+		return nil, err
+	}
+
+	stickySessionCookieName, err := loadOptionalValue(configDir, FileStickySessionCookieName)
+	if err != nil {
+		return nil, err
+	}
+
 	c := &Config{}
 	c.UAA.BaseURL = uaaBaseURL
 	c.UAA.ClientName = clientName
@@ -87,7 +376,45 @@ func Load(configDir string) (*Config, error) {
 	c.UAA.CA = uaaCA
 	c.CC.BaseURL = ccBaseUrl
 	c.CC.CA = ccCA
+	c.CC.MaxConcurrentPages = ccMaxConcurrentPages
+	c.CC.FullSyncInterval = ccFullSyncInterval
+	c.CC.FetchInterval = ccFetchInterval
+	c.CC.MaxBackoff = ccMaxBackoff
+	c.SyncMode = syncMode
+	c.ReadinessStaleThreshold = readinessStaleThreshold
+	c.RouteServiceSecret = routeServiceSecret
+	c.RoutingBackend = routingBackend
+	c.WorkloadsNamespace = workloadsNamespace
+	c.GatewayAPI.ParentRefs = gatewayAPIParentRefs
+	c.Ingress.ClassName = ingressClassName
+	c.XDS.Port = xdsPort
+	c.XDS.HTTPListenerPort = xdsHTTPListenerPort
+	c.LeaderElection.Enabled = leaderElection
+	if leaderElection {
+		for key, value := range map[string]*string{
+			FilePodName:          &c.LeaderElection.PodName,
+			FilePodNamespace:     &c.LeaderElection.PodNamespace,
+			FilePodIP:            &c.LeaderElection.PodIP,
+			FileReplicationToken: &c.LeaderElection.ReplicationToken,
+		} {
+			*value, err = loadValue(configDir, key)
+			if err == nil && *value == "" {
+				err = errors.New("empty value")
+			}
+			if err != nil {
+				return nil, fmt.Errorf("leader election requires %s: %w", key, err)
+			}
+		}
+	}
+	c.SnapshotPath = snapshotPath
+	c.NetworkPolicies.Enabled = networkPolicies
+	c.NetworkPolicies.AllowedNamespaceSelector = map[string]string{"cf-for-k8s.cloudfoundry.org/istio-system-ns": ""}
 	c.Istio.Gateways = []string{"istio-ingress"}
+	c.Istio.IngressGatewaySelector = map[string]string{"istio": "ingressgateway"}
+	c.Istio.HTTPS.Enabled = httpsGateway
+	c.Istio.HTTPS.TLSSecrets = tlsSecrets
+	c.Istio.HTTPS.Redirect = httpsRedirect
+	c.Istio.StickySessionCookieName = stickySessionCookieName
 	return c, nil
 }
 
@@ -105,6 +432,111 @@ func loadCert(configDir string, key string) (*x509.CertPool, error) {
 	return caCertPool, nil
 }
 
+// loadNamespacedNames loads a comma separated list of namespace/name or name
+func loadNamespacedNames(configDir string, key string) ([]NamespacedName, error) {
+	value, err := loadValue(configDir, key)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []NamespacedName
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.Split(item, "/")
+		switch {
+		case len(parts) == 1:
+			names = append(names, NamespacedName{Name: parts[0]})
+		case len(parts) == 2 && parts[0] != "" && parts[1] != "":
+			names = append(names, NamespacedName{Namespace: parts[0], Name: parts[1]})
+		default:
+			return nil, fmt.Errorf("parsing %s: invalid name %q", key, item)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("parsing %s: no names", key)
+	}
+	return names, nil
+}
+
+// loadOptionalMapping loads a comma separated list of key=value pairs
+func loadOptionalMapping(configDir string, key string) (map[string]string, error) {
+	value, err := loadOptionalValue(configDir, key)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping := map[string]string{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.Split(item, "=")
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("parsing %s: invalid pair %q", key, item)
+		}
+		mapping[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return mapping, nil
+}
+
+func loadOptionalInt(configDir string, key string, defaultValue int) (int, error) {
+	value, err := loadOptionalValue(configDir, key)
+	if err != nil {
+		return 0, err
+	}
+	if value == "" {
+		return defaultValue, nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("parsing %s: %w", key, err)
+	}
+	return i, nil
+}
+
+func loadOptionalBool(configDir string, key string, defaultValue bool) (bool, error) {
+	value, err := loadOptionalValue(configDir, key)
+	if err != nil {
+		return false, err
+	}
+	if value == "" {
+		return defaultValue, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("parsing %s: %w", key, err)
+	}
+	return b, nil
+}
+
+func loadOptionalDuration(configDir string, key string, defaultValue time.Duration) (time.Duration, error) {
+	value, err := loadOptionalValue(configDir, key)
+	if err != nil {
+		return 0, err
+	}
+	if value == "" {
+		return defaultValue, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("parsing %s: %w", key, err)
+	}
+	return d, nil
+}
+
+// loadOptionalValue behaves like loadValue but returns an empty string when the value is not set
+func loadOptionalValue(configDir string, key string) (string, error) {
+	value, err := loadValue(configDir, key)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	return value, err
+}
+
 func loadValue(configDir string, key string) (string, error) {
 	value, exists := os.LookupEnv(key)
 	if exists {
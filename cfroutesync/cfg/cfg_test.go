@@ -0,0 +1,286 @@
+package cfg_test
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/cfg"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Load", func() {
+	var configDir string
+
+	write := func(key, value string) {
+		Expect(ioutil.WriteFile(filepath.Join(configDir, key), []byte(value), 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		configDir, err = ioutil.TempDir("", "cfg-test")
+		Expect(err).NotTo(HaveOccurred())
+
+		server := httptest.NewTLSServer(nil)
+		ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+		server.Close()
+
+		write(cfg.FileUAABaseURL, "https://uaa.example.com")
+		write(cfg.FileUAAClientName, "some-client")
+		write(cfg.FileUAAClientSecret, "some-secret\n")
+		write(cfg.FileUAACA, string(ca))
+		write(cfg.FileCCBaseURL, "https://api.example.com")
+		write(cfg.FileCCCA, string(ca))
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(configDir)
+	})
+
+	It("loads the required values and defaults the optional ones", func() {
+		config, err := cfg.Load(configDir)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(config.UAA.BaseURL).To(Equal("https://uaa.example.com"))
+		Expect(config.UAA.ClientName).To(Equal("some-client"))
+		Expect(config.UAA.ClientSecret).To(Equal("some-secret"))
+		Expect(config.UAA.CA).NotTo(BeNil())
+		Expect(config.CC.BaseURL).To(Equal("https://api.example.com"))
+		Expect(config.CC.CA).NotTo(BeNil())
+
+		Expect(config.CC.MaxConcurrentPages).To(Equal(1))
+		Expect(config.CC.FullSyncInterval).To(Equal(time.Duration(0)))
+		Expect(config.CC.FetchInterval).To(Equal(3 * time.Second))
+		Expect(config.CC.MaxBackoff).To(Equal(time.Minute))
+		Expect(config.SyncMode).To(Equal(cfg.SyncModeMetacontroller))
+		Expect(config.ReadinessStaleThreshold).To(Equal(5 * time.Minute))
+		Expect(config.RouteServiceSecret).To(BeEmpty())
+		Expect(config.RoutingBackend).To(Equal(cfg.RoutingBackendIstio))
+		Expect(config.WorkloadsNamespace).To(Equal("cf-workloads"))
+		Expect(config.XDS.Port).To(Equal(18000))
+		Expect(config.XDS.HTTPListenerPort).To(Equal(8080))
+		Expect(config.LeaderElection.Enabled).To(BeFalse())
+		Expect(config.NetworkPolicies.Enabled).To(BeFalse())
+		Expect(config.Istio.HTTPS.Enabled).To(BeFalse())
+		Expect(config.Istio.HTTPS.TLSSecrets).To(BeEmpty())
+		Expect(config.Istio.StickySessionCookieName).To(BeEmpty())
+	})
+
+	It("loads the optional values", func() {
+		write(cfg.FileCCMaxConcurrentPages, "4")
+		write(cfg.FileCCFullSyncInterval, "10m")
+		write(cfg.FileCCFetchInterval, "5s")
+		write(cfg.FileCCMaxBackoff, "2m")
+		write(cfg.FileSyncMode, cfg.SyncModeController)
+		write(cfg.FileReadinessStaleThreshold, "1h")
+		write(cfg.FileRouteServiceSecret, "some-route-service-secret")
+		write(cfg.FileSnapshotPath, "/var/cfroutesync/snapshot.json")
+		write(cfg.FileRoutingBackend, cfg.RoutingBackendGatewayAPI)
+		write(cfg.FileGatewayAPIParentRefs, "cf-gateway, other-namespace/other-gateway")
+		write(cfg.FileWorkloadsNamespace, "some-namespace")
+		write(cfg.FileLeaderElection, "true")
+		write(cfg.FilePodName, "cfroutesync-0")
+		write(cfg.FilePodNamespace, "cf-system")
+		write(cfg.FilePodIP, "10.0.0.1")
+		write(cfg.FileReplicationToken, "some-replication-token")
+		write(cfg.FileHTTPSGateway, "true")
+		write(cfg.FileTLSSecrets, "example.com=example-com-tls, other.com=other-com-tls")
+		write(cfg.FileStickySessionCookieName, "JSESSIONID")
+
+		config, err := cfg.Load(configDir)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(config.CC.MaxConcurrentPages).To(Equal(4))
+		Expect(config.CC.FullSyncInterval).To(Equal(10 * time.Minute))
+		Expect(config.CC.FetchInterval).To(Equal(5 * time.Second))
+		Expect(config.CC.MaxBackoff).To(Equal(2 * time.Minute))
+		Expect(config.SyncMode).To(Equal(cfg.SyncModeController))
+		Expect(config.ReadinessStaleThreshold).To(Equal(time.Hour))
+		Expect(config.RouteServiceSecret).To(Equal("some-route-service-secret"))
+		Expect(config.SnapshotPath).To(Equal("/var/cfroutesync/snapshot.json"))
+		Expect(config.RoutingBackend).To(Equal(cfg.RoutingBackendGatewayAPI))
+		Expect(config.GatewayAPI.ParentRefs).To(Equal([]cfg.NamespacedName{
+			{Name: "cf-gateway"},
+			{Namespace: "other-namespace", Name: "other-gateway"},
+		}))
+		Expect(config.WorkloadsNamespace).To(Equal("some-namespace"))
+		Expect(config.LeaderElection.Enabled).To(BeTrue())
+		Expect(config.LeaderElection.PodName).To(Equal("cfroutesync-0"))
+		Expect(config.LeaderElection.PodNamespace).To(Equal("cf-system"))
+		Expect(config.LeaderElection.PodIP).To(Equal("10.0.0.1"))
+		Expect(config.LeaderElection.ReplicationToken).To(Equal("some-replication-token"))
+		Expect(config.Istio.HTTPS.Enabled).To(BeTrue())
+		Expect(config.Istio.HTTPS.TLSSecrets).To(Equal(map[string]string{
+			"example.com": "example-com-tls",
+			"other.com":   "other-com-tls",
+		}))
+		Expect(config.Istio.StickySessionCookieName).To(Equal("JSESSIONID"))
+	})
+
+	Context("when a required value is missing", func() {
+		It("returns an error", func() {
+			Expect(os.Remove(filepath.Join(configDir, cfg.FileCCBaseURL))).To(Succeed())
+
+			_, err := cfg.Load(configDir)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("when a CA is not a certificate", func() {
+		It("returns an error", func() {
+			write(cfg.FileCCCA, "potato")
+
+			_, err := cfg.Load(configDir)
+			Expect(err).To(MatchError("unable to load CA certificate"))
+		})
+	})
+
+	Context("when a number cannot be parsed", func() {
+		It("returns an error", func() {
+			write(cfg.FileXDSPort, "potato")
+
+			_, err := cfg.Load(configDir)
+			Expect(err).To(MatchError(ContainSubstring("parsing xdsPort")))
+		})
+	})
+
+	Context("when a duration cannot be parsed", func() {
+		It("returns an error", func() {
+			write(cfg.FileCCFullSyncInterval, "potato")
+
+			_, err := cfg.Load(configDir)
+			Expect(err).To(MatchError(ContainSubstring("parsing ccFullSyncInterval")))
+		})
+	})
+
+	Context("when a flag cannot be parsed", func() {
+		It("returns an error", func() {
+			write(cfg.FileNetworkPolicies, "potato")
+
+			_, err := cfg.Load(configDir)
+			Expect(err).To(MatchError(ContainSubstring("parsing networkPolicies")))
+		})
+	})
+
+	Context("when fewer than one page may be fetched at once", func() {
+		It("returns an error", func() {
+			write(cfg.FileCCMaxConcurrentPages, "0")
+
+			_, err := cfg.Load(configDir)
+			Expect(err).To(MatchError("invalid ccMaxConcurrentPages 0, must be at least 1"))
+		})
+	})
+
+	Context("when the full sync interval is negative", func() {
+		It("returns an error", func() {
+			write(cfg.FileCCFullSyncInterval, "-10m")
+
+			_, err := cfg.Load(configDir)
+			Expect(err).To(MatchError("invalid ccFullSyncInterval -10m0s, must not be negative"))
+		})
+	})
+
+	Context("when the fetch interval is not positive", func() {
+		It("returns an error", func() {
+			write(cfg.FileCCFetchInterval, "0s")
+
+			_, err := cfg.Load(configDir)
+			Expect(err).To(MatchError("invalid ccFetchInterval 0s, must be positive"))
+		})
+	})
+
+	Context("when the max backoff is shorter than the fetch interval", func() {
+		It("returns an error", func() {
+			write(cfg.FileCCFetchInterval, "10s")
+			write(cfg.FileCCMaxBackoff, "5s")
+
+			_, err := cfg.Load(configDir)
+			Expect(err).To(MatchError("invalid ccMaxBackoff 5s, must be at least ccFetchInterval 10s"))
+		})
+
+		Context("because it is not positive", func() {
+			It("returns an error", func() {
+				write(cfg.FileCCMaxBackoff, "-1m")
+
+				_, err := cfg.Load(configDir)
+				Expect(err).To(MatchError("invalid ccMaxBackoff -1m0s, must be at least ccFetchInterval 3s"))
+			})
+		})
+	})
+
+	Context("when the sync mode is unknown", func() {
+		It("returns an error", func() {
+			write(cfg.FileSyncMode, "potato")
+
+			_, err := cfg.Load(configDir)
+			Expect(err).To(MatchError(ContainSubstring(`invalid syncMode "potato"`)))
+		})
+	})
+
+	Context("when the routing backend is unknown", func() {
+		It("returns an error", func() {
+			write(cfg.FileRoutingBackend, "potato")
+
+			_, err := cfg.Load(configDir)
+			Expect(err).To(MatchError(ContainSubstring(`invalid routingBackend "potato"`)))
+		})
+	})
+
+	Context("when the gateway api backend has no parent refs", func() {
+		It("returns an error", func() {
+			write(cfg.FileRoutingBackend, cfg.RoutingBackendGatewayAPI)
+
+			_, err := cfg.Load(configDir)
+			Expect(err).To(MatchError(ContainSubstring("routing backend gateway-api requires gatewayAPIParentRefs")))
+		})
+
+		Context("or they are invalid", func() {
+			It("returns an error", func() {
+				write(cfg.FileRoutingBackend, cfg.RoutingBackendGatewayAPI)
+				write(cfg.FileGatewayAPIParentRefs, "a/b/c")
+
+				_, err := cfg.Load(configDir)
+				Expect(err).To(MatchError(ContainSubstring(`invalid name "a/b/c"`)))
+			})
+		})
+	})
+
+	Context("when leader election is enabled without the pod name", func() {
+		It("returns an error", func() {
+			write(cfg.FileLeaderElection, "true")
+			write(cfg.FilePodNamespace, "cf-system")
+			write(cfg.FilePodIP, "10.0.0.1")
+			write(cfg.FileReplicationToken, "some-replication-token")
+
+			_, err := cfg.Load(configDir)
+			Expect(err).To(MatchError(ContainSubstring("leader election requires podName")))
+		})
+	})
+
+	Context("when leader election is enabled with an empty replication token", func() {
+		It("returns an error", func() {
+			write(cfg.FileLeaderElection, "true")
+			write(cfg.FilePodName, "cfroutesync-0")
+			write(cfg.FilePodNamespace, "cf-system")
+			write(cfg.FilePodIP, "10.0.0.1")
+			write(cfg.FileReplicationToken, "")
+
+			_, err := cfg.Load(configDir)
+			Expect(err).To(MatchError("leader election requires replicationToken: empty value"))
+		})
+	})
+
+	Context("when a tls secret is not a domain=secret pair", func() {
+		It("returns an error", func() {
+			write(cfg.FileTLSSecrets, "example.com")
+
+			_, err := cfg.Load(configDir)
+			Expect(err).To(MatchError(ContainSubstring(`parsing tlsSecrets: invalid pair "example.com"`)))
+		})
+	})
+})
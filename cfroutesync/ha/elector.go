@@ -0,0 +1,91 @@
+package ha
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coordinationv1client "k8s.io/client-go/kubernetes/typed/coordination/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// Elector uses a Lease to pick the single replica that fetches from Cloud Controller
+type Elector struct {
+	LeaseClient    coordinationv1client.LeasesGetter
+	LeaseNamespace string
+	LeaseName      string
+
+	// Identity of this replica, see Identity
+	Identity string
+
+	LeaseDuration time.Duration
+	RenewDeadline time.Duration
+	RetryPeriod   time.Duration
+
+	// Runs while this replica is the leader, its context is cancelled when leadership is lost
+	OnStartedLeading func(ctx context.Context)
+
+	// Called with the identity of every new leader, including this replica
+	OnNewLeader func(identity string)
+}
+
+// Run takes part in leader elections until the context is done
+func (e *Elector) Run(ctx context.Context) error {
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Namespace: e.LeaseNamespace,
+			Name:      e.LeaseName,
+		},
+		Client:     e.LeaseClient,
+		LockConfig: resourcelock.ResourceLockConfig{Identity: e.Identity},
+	}
+
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		Name:            e.LeaseName,
+		LeaseDuration:   e.LeaseDuration,
+		RenewDeadline:   e.RenewDeadline,
+		RetryPeriod:     e.RetryPeriod,
+		ReleaseOnCancel: true,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				log.WithFields(log.Fields{"identity": e.Identity}).Info("started leading")
+				e.OnStartedLeading(ctx)
+			},
+			OnStoppedLeading: func() {
+				log.WithFields(log.Fields{"identity": e.Identity}).Info("stopped leading")
+			},
+			OnNewLeader: func(identity string) {
+				log.WithFields(log.Fields{"leader": identity}).Info("new leader elected")
+				e.OnNewLeader(identity)
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("creating leader elector: %w", err)
+	}
+
+	// Run returns whenever leadership is lost, so keep trying to become leader again
+	for ctx.Err() == nil {
+		elector.Run(ctx)
+	}
+	return nil
+}
+
+// Identity identifies a replica in the Lease and tells the other replicas where to replicate the snapshot from
+func Identity(podName string, address string) string {
+	return fmt.Sprintf("%s_%s", podName, address)
+}
+
+// LeaderAddress returns the address part of an identity, pod names cannot contain underscores
+func LeaderAddress(identity string) string {
+	parts := strings.SplitN(identity, "_", 2)
+	if len(parts) != 2 {
+		return ""
+	}
+	return parts[1]
+}
@@ -0,0 +1,20 @@
+package ha_test
+
+import (
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/ha"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Identity", func() {
+	It("can be turned back into the address of the replica", func() {
+		identity := ha.Identity("cfroutesync-5d8f7c9b4-x2x7z", "10.0.0.5:8080")
+		Expect(identity).To(Equal("cfroutesync-5d8f7c9b4-x2x7z_10.0.0.5:8080"))
+		Expect(ha.LeaderAddress(identity)).To(Equal("10.0.0.5:8080"))
+	})
+
+	It("has no address when the identity was not made by Identity", func() {
+		Expect(ha.LeaderAddress("some-other-holder")).To(BeEmpty())
+	})
+})
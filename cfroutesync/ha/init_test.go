@@ -0,0 +1,13 @@
+package ha_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestHA(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "HA Suite")
+}
@@ -0,0 +1,80 @@
+package ha
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+)
+
+//go:generate counterfeiter -o fakes/json_client.go --fake-name JSONClient . jsonClient
+type jsonClient interface {
+	MakeRequest(*http.Request, interface{}) error
+}
+
+//go:generate counterfeiter -o fakes/snapshot_putter.go --fake-name SnapshotPutter . snapshotPutter
+type snapshotPutter interface {
+	Put(snapshot *models.RouteSnapshot)
+}
+
+// Replicator keeps the snapshot of a follower warm by copying it from the leader,
+// so that every replica can answer the webhook without fetching from Cloud Controller
+type Replicator struct {
+	JSONClient   jsonClient
+	SnapshotRepo snapshotPutter
+
+	// Secret shared by the replicas, see SnapshotHandler
+	Token string
+
+	mutex     sync.RWMutex
+	leaderURL string
+}
+
+// SetLeaderAddress sets the address of the leader to replicate from.
+// An empty address stops replication, e.g. when this replica is the leader.
+func (r *Replicator) SetLeaderAddress(address string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if address == "" {
+		r.leaderURL = ""
+		return
+	}
+	r.leaderURL = fmt.Sprintf("http://%s/snapshot", address)
+}
+
+// Following reports whether there is a leader to replicate from
+func (r *Replicator) Following() bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.leaderURL != ""
+}
+
+// ReplicateOnce copies the snapshot of the leader into the repo, if there is a leader to replicate from
+func (r *Replicator) ReplicateOnce(ctx context.Context) error {
+	r.mutex.RLock()
+	leaderURL := r.leaderURL
+	r.mutex.RUnlock()
+
+	if leaderURL == "" {
+		return nil
+	}
+
+	request, err := http.NewRequestWithContext(ctx, "GET", leaderURL, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+r.Token)
+
+	snapshot := &models.RouteSnapshot{}
+	err = r.JSONClient.MakeRequest(request, snapshot)
+	if err != nil {
+		return fmt.Errorf("replicating snapshot from leader: %w", err)
+	}
+
+	// the repo numbers and hashes the snapshot itself, everything else is the leader's
+	r.SnapshotRepo.Put(snapshot)
+	return nil
+}
@@ -0,0 +1,89 @@
+package ha_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/ha"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/ha/fakes"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Replicator", func() {
+	var (
+		replicator   *ha.Replicator
+		jsonClient   *fakes.JSONClient
+		snapshotRepo *fakes.SnapshotPutter
+	)
+
+	BeforeEach(func() {
+		jsonClient = &fakes.JSONClient{}
+		jsonClient.MakeRequestStub = func(req *http.Request, responseStruct interface{}) error {
+			return json.Unmarshal([]byte(`{"Routes": [{"Guid": "route-0"}], "Policies": [{"SourceAppGuid": "app-0", "DestinationAppGuid": "app-1", "Protocol": "tcp", "StartPort": 8080, "EndPort": 8080}], "Generation": 7, "Hash": "leader-hash"}`), responseStruct)
+		}
+		snapshotRepo = &fakes.SnapshotPutter{}
+		replicator = &ha.Replicator{
+			JSONClient:   jsonClient,
+			SnapshotRepo: snapshotRepo,
+			Token:        "some-token",
+		}
+	})
+
+	It("does nothing until there is a leader to replicate from", func() {
+		Expect(replicator.ReplicateOnce(context.Background())).To(Succeed())
+		Expect(jsonClient.MakeRequestCallCount()).To(Equal(0))
+		Expect(snapshotRepo.PutCallCount()).To(Equal(0))
+		Expect(replicator.Following()).To(BeFalse())
+	})
+
+	Context("when there is a leader", func() {
+		BeforeEach(func() {
+			replicator.SetLeaderAddress("10.0.0.5:8080")
+		})
+
+		It("is following", func() {
+			Expect(replicator.Following()).To(BeTrue())
+		})
+
+		It("puts the routes and policies of the leader's snapshot into the repo", func() {
+			Expect(replicator.ReplicateOnce(context.Background())).To(Succeed())
+
+			request, _ := jsonClient.MakeRequestArgsForCall(0)
+			Expect(request.Method).To(Equal("GET"))
+			Expect(request.URL.String()).To(Equal("http://10.0.0.5:8080/snapshot"))
+			Expect(request.Header.Get("Authorization")).To(Equal("Bearer some-token"))
+
+			Expect(snapshotRepo.PutCallCount()).To(Equal(1))
+			snapshot := snapshotRepo.PutArgsForCall(0)
+			Expect(snapshot.Routes).To(Equal([]models.Route{{Guid: "route-0"}}))
+			Expect(snapshot.Policies).To(Equal([]models.Policy{
+				{SourceAppGuid: "app-0", DestinationAppGuid: "app-1", Protocol: "tcp", StartPort: 8080, EndPort: 8080},
+			}))
+		})
+
+		It("stops replicating when the leader is cleared", func() {
+			replicator.SetLeaderAddress("")
+			Expect(replicator.ReplicateOnce(context.Background())).To(Succeed())
+			Expect(jsonClient.MakeRequestCallCount()).To(Equal(0))
+			Expect(replicator.Following()).To(BeFalse())
+		})
+
+		Context("when the leader cannot be reached", func() {
+			BeforeEach(func() {
+				jsonClient.MakeRequestReturns(errors.New("potato"))
+				jsonClient.MakeRequestStub = nil
+			})
+
+			It("returns a helpful error and keeps the current snapshot", func() {
+				err := replicator.ReplicateOnce(context.Background())
+				Expect(err).To(MatchError("replicating snapshot from leader: potato"))
+				Expect(snapshotRepo.PutCallCount()).To(Equal(0))
+			})
+		})
+	})
+})
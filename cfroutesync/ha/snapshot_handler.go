@@ -0,0 +1,54 @@
+package ha
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+)
+
+//go:generate counterfeiter -o fakes/snapshot_getter.go --fake-name SnapshotGetter . snapshotGetter
+type snapshotGetter interface {
+	Get() (*models.RouteSnapshot, bool)
+}
+
+// SnapshotHandler serves the current snapshot, so that followers can replicate it from the leader.
+// The snapshot holds every route and route service URL, so it is only served to requests with the Token.
+type SnapshotHandler struct {
+	SnapshotRepo snapshotGetter
+
+	// Secret shared by the replicas, sent by the Replicator as a bearer token
+	Token string
+}
+
+func (h *SnapshotHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if !h.authorized(req) {
+		rw.WriteHeader(http.StatusUnauthorized)
+		rw.Write([]byte(`{"error": "unauthorized"}`))
+		return
+	}
+
+	snapshot, ok := h.SnapshotRepo.Get()
+	if !ok {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+		rw.Write([]byte(`{"error": "no snapshot yet"}`))
+		return
+	}
+
+	bytes, err := json.Marshal(snapshot)
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		rw.Write([]byte(`{"error": "failed to marshal snapshot"}`))
+		return
+	}
+	rw.Write(bytes)
+}
+
+func (h *SnapshotHandler) authorized(req *http.Request) bool {
+	if h.Token == "" {
+		return false
+	}
+	expected := "Bearer " + h.Token
+	return subtle.ConstantTimeCompare([]byte(req.Header.Get("Authorization")), []byte(expected)) == 1
+}
@@ -0,0 +1,82 @@
+package ha_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/ha"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/ha/fakes"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SnapshotHandler", func() {
+	var (
+		handler      *ha.SnapshotHandler
+		snapshotRepo *fakes.SnapshotGetter
+		resp         *httptest.ResponseRecorder
+		request      *http.Request
+	)
+
+	BeforeEach(func() {
+		snapshotRepo = &fakes.SnapshotGetter{}
+		handler = &ha.SnapshotHandler{SnapshotRepo: snapshotRepo, Token: "some-token"}
+		resp = httptest.NewRecorder()
+		request = httptest.NewRequest("GET", "/snapshot", nil)
+		request.Header.Set("Authorization", "Bearer some-token")
+	})
+
+	It("serves the current snapshot", func() {
+		snapshot := &models.RouteSnapshot{
+			Routes:     []models.Route{{Guid: "route-0"}},
+			Generation: 7,
+			Hash:       "some-hash",
+		}
+		snapshotRepo.GetReturns(snapshot, true)
+
+		handler.ServeHTTP(resp, request)
+
+		Expect(resp.Code).To(Equal(http.StatusOK))
+		served := &models.RouteSnapshot{}
+		Expect(json.Unmarshal(resp.Body.Bytes(), served)).To(Succeed())
+		Expect(served).To(Equal(snapshot))
+	})
+
+	Context("when there is no snapshot yet", func() {
+		It("responds with 503", func() {
+			snapshotRepo.GetReturns(nil, false)
+
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(resp.Body.String()).To(MatchJSON(`{"error": "no snapshot yet"}`))
+		})
+	})
+
+	Context("when the request does not have the token", func() {
+		It("responds with 401 without the snapshot", func() {
+			snapshotRepo.GetReturns(&models.RouteSnapshot{Routes: []models.Route{{Guid: "route-0"}}}, true)
+			request.Header.Set("Authorization", "Bearer other-token")
+
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusUnauthorized))
+			Expect(resp.Body.String()).To(MatchJSON(`{"error": "unauthorized"}`))
+			Expect(snapshotRepo.GetCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when no token is configured", func() {
+		It("serves the snapshot to nobody", func() {
+			handler.Token = ""
+			request.Header.Set("Authorization", "Bearer ")
+
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusUnauthorized))
+		})
+	})
+})
@@ -0,0 +1,107 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+)
+
+//go:generate counterfeiter -o fakes/snapshot_getter.go --fake-name SnapshotGetter . snapshotGetter
+type snapshotGetter interface {
+	Get() (*models.RouteSnapshot, bool)
+}
+
+//go:generate counterfeiter -o fakes/sync_status_getter.go --fake-name SyncStatusGetter . syncStatusGetter
+type syncStatusGetter interface {
+	Status() SyncStatus
+}
+
+// Handler reports the sync state as JSON. For liveness it always responds with 200.
+// For readiness it responds with 503 until there is a snapshot to serve, either from
+// a successful sync or restored from disk, and again once that snapshot was last synced
+// longer than StaleThreshold ago.
+type Handler struct {
+	SnapshotRepo snapshotGetter
+	SyncState    syncStatusGetter
+
+	// Respond with 503 when not ready
+	Readiness bool
+
+	// How long after the last successful sync the snapshot counts as stale and not ready, 0 never goes stale
+	StaleThreshold time.Duration
+
+	Now func() time.Time
+}
+
+type response struct {
+	Ready         bool       `json:"ready"`
+	Stale         bool       `json:"stale"`
+	Reason        string     `json:"reason,omitempty"`
+	LastSuccess   *time.Time `json:"lastSuccess"`
+	LastError     string     `json:"lastError,omitempty"`
+	LastErrorTime *time.Time `json:"lastErrorTime,omitempty"`
+	RouteCount    int        `json:"routeCount"`
+	Generation    uint64     `json:"generation"`
+}
+
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	status := h.SyncState.Status()
+	resp := response{
+		LastSuccess:   optionalTime(status.LastSuccess),
+		LastError:     status.LastError,
+		LastErrorTime: optionalTime(status.LastErrorTime),
+	}
+
+	restored := false
+	var confirmedAt time.Time
+	if snapshot, ok := h.SnapshotRepo.Get(); ok {
+		resp.RouteCount = len(snapshot.Routes)
+		resp.Generation = snapshot.Generation
+		restored = snapshot.Stale
+		confirmedAt = snapshot.ConfirmedAt
+	}
+
+	switch {
+	case status.LastSuccess.IsZero() && restored && h.isStale(confirmedAt):
+		resp.Stale = true
+		resp.Reason = "snapshot restored from disk was confirmed longer than " + h.StaleThreshold.String() + " ago"
+	case status.LastSuccess.IsZero() && restored:
+		// keep serving the snapshot restored from disk, e.g. after a restart during a CC outage
+		resp.Ready = true
+		resp.Reason = "serving the snapshot restored from disk until the first successful sync"
+	case status.LastSuccess.IsZero():
+		resp.Reason = "no successful sync yet"
+	case h.isStale(status.LastSuccess):
+		resp.Stale = true
+		resp.Reason = "last successful sync is older than " + h.StaleThreshold.String()
+	default:
+		resp.Ready = true
+	}
+
+	bytes, err := json.Marshal(resp)
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		rw.Write([]byte(`{"error": "failed to marshal health status"}`))
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if h.Readiness && !resp.Ready {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}
+	rw.Write(bytes)
+}
+
+// isStale returns true when the given sync time is older than StaleThreshold
+func (h *Handler) isStale(synced time.Time) bool {
+	return h.StaleThreshold > 0 && h.Now().Sub(synced) > h.StaleThreshold
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,196 @@
+package health_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/health"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/health/fakes"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Handler", func() {
+	var (
+		handler      *health.Handler
+		snapshotRepo *fakes.SnapshotGetter
+		syncState    *fakes.SyncStatusGetter
+		resp         *httptest.ResponseRecorder
+		request      *http.Request
+		now          time.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+		snapshotRepo = &fakes.SnapshotGetter{}
+		syncState = &fakes.SyncStatusGetter{}
+		handler = &health.Handler{
+			SnapshotRepo:   snapshotRepo,
+			SyncState:      syncState,
+			Readiness:      true,
+			StaleThreshold: time.Minute,
+			Now:            func() time.Time { return now },
+		}
+		resp = httptest.NewRecorder()
+		request = httptest.NewRequest("GET", "/readyz", nil)
+	})
+
+	Context("when the last sync succeeded recently", func() {
+		BeforeEach(func() {
+			syncState.StatusReturns(health.SyncStatus{
+				LastSuccess:   now.Add(-10 * time.Second),
+				LastError:     "potato",
+				LastErrorTime: now.Add(-20 * time.Second),
+			})
+			snapshotRepo.GetReturns(&models.RouteSnapshot{
+				Routes:     []models.Route{{Guid: "route-0"}, {Guid: "route-1"}},
+				Generation: 3,
+			}, true)
+		})
+
+		It("is ready and reports the details", func() {
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(resp.Body.String()).To(MatchJSON(`{
+				"ready": true,
+				"stale": false,
+				"lastSuccess": "2020-01-01T11:59:50Z",
+				"lastError": "potato",
+				"lastErrorTime": "2020-01-01T11:59:40Z",
+				"routeCount": 2,
+				"generation": 3
+			}`))
+		})
+	})
+
+	Context("when there has been no successful sync yet", func() {
+		BeforeEach(func() {
+			syncState.StatusReturns(health.SyncStatus{
+				LastError:     "potato",
+				LastErrorTime: now,
+			})
+		})
+
+		It("is not ready", func() {
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(resp.Body.String()).To(MatchJSON(`{
+				"ready": false,
+				"stale": false,
+				"reason": "no successful sync yet",
+				"lastSuccess": null,
+				"lastError": "potato",
+				"lastErrorTime": "2020-01-01T12:00:00Z",
+				"routeCount": 0,
+				"generation": 0
+			}`))
+		})
+
+		Context("when a snapshot restored from disk is being served", func() {
+			BeforeEach(func() {
+				snapshotRepo.GetReturns(&models.RouteSnapshot{
+					Routes:      []models.Route{{Guid: "route-0"}},
+					Generation:  1,
+					Stale:       true,
+					ConfirmedAt: now.Add(-30 * time.Second),
+				}, true)
+			})
+
+			It("is ready so that the snapshot keeps being served", func() {
+				handler.ServeHTTP(resp, request)
+
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Body.String()).To(MatchJSON(`{
+					"ready": true,
+					"stale": false,
+					"reason": "serving the snapshot restored from disk until the first successful sync",
+					"lastSuccess": null,
+					"lastError": "potato",
+					"lastErrorTime": "2020-01-01T12:00:00Z",
+					"routeCount": 1,
+					"generation": 1
+				}`))
+			})
+
+			Context("when Cloud Controller confirmed it longer ago than the stale threshold", func() {
+				BeforeEach(func() {
+					snapshotRepo.GetReturns(&models.RouteSnapshot{
+						Routes:      []models.Route{{Guid: "route-0"}},
+						Generation:  1,
+						Stale:       true,
+						ConfirmedAt: now.Add(-2 * time.Minute),
+					}, true)
+				})
+
+				It("responds with 503 and reports the snapshot as stale", func() {
+					handler.ServeHTTP(resp, request)
+
+					Expect(resp.Code).To(Equal(http.StatusServiceUnavailable))
+					Expect(resp.Body.String()).To(ContainSubstring(`"ready":false,"stale":true,"reason":"snapshot restored from disk was confirmed longer than 1m0s ago"`))
+				})
+
+				Context("when the stale threshold is 0", func() {
+					It("stays ready and does not report the snapshot as stale", func() {
+						handler.StaleThreshold = 0
+
+						handler.ServeHTTP(resp, request)
+
+						Expect(resp.Code).To(Equal(http.StatusOK))
+						Expect(resp.Body.String()).To(ContainSubstring(`"stale":false`))
+					})
+				})
+			})
+		})
+
+		Context("when serving liveness", func() {
+			It("responds with 200 and the details", func() {
+				handler.Readiness = false
+
+				handler.ServeHTTP(resp, request)
+
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Body.String()).To(ContainSubstring(`"ready":false`))
+			})
+		})
+	})
+
+	Context("when the last success is older than the stale threshold", func() {
+		BeforeEach(func() {
+			syncState.StatusReturns(health.SyncStatus{LastSuccess: now.Add(-2 * time.Minute)})
+		})
+
+		It("responds with 503 and reports the snapshot as stale", func() {
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(resp.Body.String()).To(ContainSubstring(`"ready":false,"stale":true,"reason":"last successful sync is older than 1m0s"`))
+		})
+
+		Context("when serving liveness", func() {
+			It("responds with 200", func() {
+				handler.Readiness = false
+
+				handler.ServeHTTP(resp, request)
+
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Body.String()).To(ContainSubstring(`"ready":false,"stale":true`))
+			})
+		})
+
+		Context("when the stale threshold is 0", func() {
+			It("stays ready and does not report the snapshot as stale", func() {
+				handler.StaleThreshold = 0
+
+				handler.ServeHTTP(resp, request)
+
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Body.String()).To(ContainSubstring(`"stale":false`))
+			})
+		})
+	})
+})
@@ -0,0 +1,40 @@
+package health
+
+import (
+	"sync"
+	"time"
+)
+
+// SyncStatus describes the outcome of the most recent attempts to sync routes
+type SyncStatus struct {
+	LastSuccess   time.Time
+	LastError     string
+	LastErrorTime time.Time
+}
+
+// SyncState records the results of fetching (or replicating) the route snapshot
+type SyncState struct {
+	Now func() time.Time
+
+	mutex  sync.Mutex
+	status SyncStatus
+}
+
+// Record stores the result of a sync attempt, a nil error counts as success
+func (s *SyncState) Record(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err != nil {
+		s.status.LastError = err.Error()
+		s.status.LastErrorTime = s.Now()
+		return
+	}
+	s.status.LastSuccess = s.Now()
+}
+
+func (s *SyncState) Status() SyncStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.status
+}
@@ -0,0 +1,35 @@
+package health_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/health"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SyncState", func() {
+	var (
+		state *health.SyncState
+		now   time.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+		state = &health.SyncState{Now: func() time.Time { return now }}
+	})
+
+	It("records successes and errors independently", func() {
+		state.Record(nil)
+		now = now.Add(time.Second)
+		state.Record(errors.New("potato"))
+
+		Expect(state.Status()).To(Equal(health.SyncStatus{
+			LastSuccess:   time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC),
+			LastError:     "potato",
+			LastErrorTime: time.Date(2020, 1, 1, 12, 0, 1, 0, time.UTC),
+		}))
+	})
+})
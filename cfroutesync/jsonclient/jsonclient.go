@@ -12,6 +12,16 @@ type HttpClient interface {
 	Do(*http.Request) (*http.Response, error)
 }
 
+// HTTPError is returned by MakeRequest when the server responds with a status code other than 200
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("bad response, code %d: %s", e.StatusCode, e.Body)
+}
+
 type JSONClient struct {
 	HTTPClient HttpClient
 }
@@ -29,7 +39,7 @@ func (c *JSONClient) MakeRequest(request *http.Request, response interface{}) er
 	}
 
 	if resp.StatusCode != 200 {
-		return fmt.Errorf("bad response, code %d: %s", resp.StatusCode, string(respBytes))
+		return &HTTPError{StatusCode: resp.StatusCode, Body: string(respBytes)}
 	}
 
 	err = json.Unmarshal(respBytes, &response)
@@ -49,6 +49,13 @@ var _ = Describe("JSON Client", func() {
 			err := client.MakeRequest(&http.Request{}, struct{}{})
 			Expect(err).To(MatchError("bad response, code 418: bad thing"))
 		})
+
+		It("returns an HTTPError carrying the status code", func() {
+			err := client.MakeRequest(&http.Request{}, struct{}{})
+			var httpErr *jsonclient.HTTPError
+			Expect(errors.As(err, &httpErr)).To(BeTrue())
+			Expect(httpErr.StatusCode).To(Equal(418))
+		})
 	})
 
 	Context("when the response body is not valid json", func() {
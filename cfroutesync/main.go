@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"math/rand"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/metrics"
@@ -13,14 +18,81 @@ import (
 	"code.cloudfoundry.org/cf-networking-helpers/marshal"
 	"code.cloudfoundry.org/tlsconfig"
 	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	coordinationv1client "k8s.io/client-go/kubernetes/typed/coordination/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 
 	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/ccclient"
 	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/ccroutefetcher"
 	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/cfg"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/ha"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/health"
 	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/jsonclient"
 	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/policyclient"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/poller"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/reconciler"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/routeservice"
 	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/uaaclient"
 	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/webhook"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/xds"
+)
+
+// how long before expiry the cached UAA token is replaced
+const uaaTokenRefreshMargin = 1 * time.Minute
+
+type snapshotRepo interface {
+	Get() (*models.RouteSnapshot, bool)
+	Put(snapshot *models.RouteSnapshot)
+	Subscribe() (<-chan *models.RouteSnapshot, func())
+}
+
+// the resources generated by the K8sResourceBuilders of each routing backend, which the reconciler garbage collects
+var childResources = map[string][]schema.GroupVersionResource{
+	cfg.RoutingBackendIstio: {
+		{Version: "v1", Resource: "services"},
+		{Group: "networking.istio.io", Version: "v1alpha3", Resource: "virtualservices"},
+		{Group: "networking.istio.io", Version: "v1alpha3", Resource: "gateways"},
+		{Group: "networking.istio.io", Version: "v1alpha3", Resource: "destinationrules"},
+		{Group: "networking.istio.io", Version: "v1alpha3", Resource: "serviceentries"},
+		{Group: "networking.istio.io", Version: "v1alpha3", Resource: "envoyfilters"},
+	},
+	cfg.RoutingBackendGatewayAPI: {
+		{Version: "v1", Resource: "services"},
+		{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"},
+		{Group: "gateway.networking.k8s.io", Version: "v1alpha2", Resource: "tcproutes"},
+	},
+	cfg.RoutingBackendContour: {
+		{Version: "v1", Resource: "services"},
+		{Group: "projectcontour.io", Version: "v1", Resource: "httpproxies"},
+	},
+	cfg.RoutingBackendIngress: {
+		{Version: "v1", Resource: "services"},
+		{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+	},
+	cfg.RoutingBackendXDS: {
+		{Version: "v1", Resource: "services"},
+	},
+}
+
+// how long in-flight webhook requests get to finish on shutdown
+const serverShutdownTimeout = 10 * time.Second
+
+// how often the reconciler re-applies all children when nothing changed
+const reconcilerResyncInterval = 30 * time.Second
+
+// leader election settings, a new leader takes over within seconds of the old one disappearing
+const (
+	leaseName          = "cfroutesync"
+	leaseDuration      = 15 * time.Second
+	leaseRenewDeadline = 10 * time.Second
+	leaseRetryPeriod   = 2 * time.Second
+	replicationTimeout = 5 * time.Second
 )
 
 func main() {
@@ -69,63 +141,380 @@ func mainWithError() error {
 		return fmt.Errorf("building CC TLS config: %w", err)
 	}
 
-	snapshotRepo := &models.SnapshotRepo{}
+	var snapshotRepo snapshotRepo = &models.SnapshotRepo{}
+	if config.SnapshotPath != "" {
+		persistentRepo := &models.PersistentSnapshotRepo{Path: config.SnapshotPath, Now: time.Now}
+		if err := persistentRepo.Load(); err != nil {
+			log.WithError(err).Error("restoring snapshot from disk")
+		}
+		snapshotRepo = persistentRepo
+	}
+
+	ccJSONClient := &jsonclient.JSONClient{
+		HTTPClient: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: ccTLSConfig,
+			},
+		},
+	}
 
 	fetcher := &ccroutefetcher.Fetcher{
 		CCClient: &ccclient.Client{
-			BaseURL: config.CC.BaseURL,
-			JSONClient: &jsonclient.JSONClient{
-				HTTPClient: &http.Client{
-					Transport: &http.Transport{
-						TLSClientConfig: ccTLSConfig,
-					},
-				},
-			},
+			BaseURL:            config.CC.BaseURL,
+			MaxConcurrentPages: config.CC.MaxConcurrentPages,
+			JSONClient:         ccJSONClient,
 		},
-		UAAClient: &uaaclient.Client{
-			BaseURL: config.UAA.BaseURL,
-			Name:    config.UAA.ClientName,
-			Secret:  config.UAA.ClientSecret,
-			JSONClient: &jsonclient.JSONClient{
-				HTTPClient: &http.Client{
-					Transport: &http.Transport{
-						TLSClientConfig: uaaTLSConfig,
+		UAAClient: &uaaclient.TokenCache{
+			Client: &uaaclient.Client{
+				BaseURL: config.UAA.BaseURL,
+				Name:    config.UAA.ClientName,
+				Secret:  config.UAA.ClientSecret,
+				JSONClient: &jsonclient.JSONClient{
+					HTTPClient: &http.Client{
+						Transport: &http.Transport{
+							TLSClientConfig: uaaTLSConfig,
+						},
 					},
 				},
 			},
+			RefreshMargin: uaaTokenRefreshMargin,
+			Now:           time.Now,
 		},
-		SnapshotRepo: snapshotRepo,
+		SnapshotRepo:     snapshotRepo,
+		FullSyncInterval: config.CC.FullSyncInterval,
+		Now:              time.Now,
+	}
+
+	if config.CC.FullSyncInterval > 0 {
+		log.WithFields(log.Fields{"interval": config.CC.FullSyncInterval.String()}).
+			Info("only fetching changed routes and audit events in between full fetches")
+	}
+
+	lineage := &webhook.Lineage{
+		RouteSnapshotRepo:   snapshotRepo,
+		K8sResourceBuilders: resourceBuilders(config),
+	}
+
+	children := childResources[config.RoutingBackend]
+	if config.NetworkPolicies.Enabled {
+		fetcher.PolicyClient = &policyclient.Client{
+			BaseURL:    config.CC.BaseURL,
+			JSONClient: ccJSONClient,
+		}
+		lineage.K8sPolicyBuilders = []webhook.K8sPolicyBuilder{
+			&webhook.NetworkPolicyBuilder{AllowedNamespaceSelector: config.NetworkPolicies.AllowedNamespaceSelector},
+		}
+		children = append(children, schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"})
 	}
 
 	webhookMux := http.NewServeMux()
 	webhookMux.Handle("/sync", &webhook.SyncHandler{
 		Marshaler:   marshal.MarshalFunc(json.Marshal),
 		Unmarshaler: marshal.UnmarshalFunc(json.Unmarshal),
-		Syncer: &webhook.Lineage{
-			RouteSnapshotRepo: snapshotRepo,
-			K8sResourceBuilders: []webhook.K8sResourceBuilder{
-				&webhook.ServiceBuilder{},
-				&webhook.VirtualServiceBuilder{IstioGateways: config.Istio.Gateways},
-			},
-		},
+		Syncer:      lineage,
 	})
 
 	webhookMux.Handle("/metrics", metrics.DefaultMetrics.Handler)
+	if config.LeaderElection.Enabled {
+		webhookMux.Handle("/snapshot", &ha.SnapshotHandler{
+			SnapshotRepo: snapshotRepo,
+			Token:        config.LeaderElection.ReplicationToken,
+		})
+	}
+	if config.RoutingBackend == cfg.RoutingBackendIstio && config.RouteServiceSecret != "" {
+		// every replica answers the checks of the ingress gateway, not just the leader
+		webhookMux.Handle(routeservice.CheckPathPrefix+"/", &routeservice.CheckHandler{
+			Signer: &routeservice.Signer{Key: routeservice.Key(config.RouteServiceSecret)},
+			Now:    time.Now,
+		})
+	}
+
+	syncState := &health.SyncState{Now: time.Now}
+	webhookMux.Handle("/healthz", &health.Handler{
+		SnapshotRepo: snapshotRepo,
+		SyncState:    syncState,
+		Now:          time.Now,
+	})
+	webhookMux.Handle("/readyz", &health.Handler{
+		SnapshotRepo:   snapshotRepo,
+		SyncState:      syncState,
+		Readiness:      true,
+		StaleThreshold: config.ReadinessStaleThreshold,
+		Now:            time.Now,
+	})
+
+	server := &http.Server{Addr: listenAddr, Handler: webhookMux}
+	go func() {
+		log.Info("starting webhook server")
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.WithError(err).Fatal("serving webhook")
+		}
+	}()
+
+	ctx := shutdownContext()
+	defer shutdownServer(server)
+
+	var restConfig *rest.Config
+	if config.SyncMode == cfg.SyncModeController || config.LeaderElection.Enabled || config.RoutingBackend == cfg.RoutingBackendXDS {
+		restConfig, err = rest.InClusterConfig()
+		if err != nil {
+			return fmt.Errorf("loading in-cluster config: %w", err)
+		}
+	}
 
-	log.Info("starting webhook server")
-	go http.ListenAndServe(listenAddr, webhookMux)
+	if config.RoutingBackend == cfg.RoutingBackendXDS {
+		// every replica serves xDS from its own copy of the snapshot
+		if err := startXDS(ctx, config, restConfig, snapshotRepo); err != nil {
+			return fmt.Errorf("starting xds server: %w", err)
+		}
+	}
 
-	log.Info("starting cc fetch loop")
-	for {
-		err := fetcher.FetchOnce()
+	var r *reconciler.Reconciler
+	if config.SyncMode == cfg.SyncModeController {
+		r, err = newReconciler(restConfig, lineage, snapshotRepo, children)
 		if err != nil {
-			log.WithError(err).Errorf("fetching")
+			return fmt.Errorf("building reconciler: %w", err)
 		}
+	}
 
-		if snapshot, ok := snapshotRepo.Get(); ok {
-			metrics.Update(snapshot)
+	fetchPoller := &poller.Poller{
+		Name:       "cc fetch",
+		Interval:   config.CC.FetchInterval,
+		MaxBackoff: config.CC.MaxBackoff,
+		After:      time.After,
+		Random:     rand.New(rand.NewSource(time.Now().UnixNano())).Float64,
+		Poll: func(ctx context.Context) error {
+			err := fetcher.FetchOnce(ctx)
+			if ctx.Err() == nil {
+				syncState.Record(err)
+			}
+			if err == nil {
+				metrics.Synced()
+			}
+			if snapshot, ok := snapshotRepo.Get(); ok {
+				metrics.Update(snapshot)
+				metrics.InvalidAnnotations(len(webhook.InvalidAnnotations(snapshot.Routes)))
+			}
+			return err
+		},
+	}
+
+	// the leader fetches from CC and, in controller mode, writes to the API server
+	lead := func(ctx context.Context) {
+		if r != nil {
+			log.Info("starting reconciler")
+			go r.Run(ctx)
 		}
+		log.Info("starting cc fetch loop")
+		fetchPoller.Run(ctx)
+		log.Info("stopped cc fetch loop")
+	}
 
-		time.Sleep(3 * time.Second)
+	if !config.LeaderElection.Enabled {
+		lead(ctx)
+		return nil
+	}
+
+	_, listenPort, err := net.SplitHostPort(listenAddr)
+	if err != nil {
+		return fmt.Errorf("parsing listen address: %w", err)
 	}
+	identity := ha.Identity(config.LeaderElection.PodName, net.JoinHostPort(config.LeaderElection.PodIP, listenPort))
+
+	replicator := &ha.Replicator{
+		JSONClient: &jsonclient.JSONClient{
+			HTTPClient: &http.Client{Timeout: replicationTimeout},
+		},
+		SnapshotRepo: snapshotRepo,
+		Token:        config.LeaderElection.ReplicationToken,
+	}
+	replicationPoller := &poller.Poller{
+		Name:       "replication",
+		Interval:   config.CC.FetchInterval,
+		MaxBackoff: config.CC.MaxBackoff,
+		After:      time.After,
+		Random:     rand.New(rand.NewSource(time.Now().UnixNano())).Float64,
+		Poll: func(ctx context.Context) error {
+			// the leader's own fetches are recorded by the fetch poller
+			following := replicator.Following()
+			err := replicator.ReplicateOnce(ctx)
+			if following && ctx.Err() == nil {
+				syncState.Record(err)
+			}
+			if following && err == nil {
+				metrics.Synced()
+			}
+			return err
+		},
+	}
+	go replicationPoller.Run(ctx)
+
+	leaseClient, err := coordinationv1client.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("building lease client: %w", err)
+	}
+
+	elector := &ha.Elector{
+		LeaseClient:      leaseClient,
+		LeaseNamespace:   config.LeaderElection.PodNamespace,
+		LeaseName:        leaseName,
+		Identity:         identity,
+		LeaseDuration:    leaseDuration,
+		RenewDeadline:    leaseRenewDeadline,
+		RetryPeriod:      leaseRetryPeriod,
+		OnStartedLeading: lead,
+		OnNewLeader: func(leader string) {
+			if leader == identity {
+				replicator.SetLeaderAddress("")
+			} else {
+				replicator.SetLeaderAddress(ha.LeaderAddress(leader))
+			}
+		},
+	}
+	log.WithFields(log.Fields{"identity": identity}).Info("starting leader election")
+	return elector.Run(ctx)
+}
+
+// shutdownContext returns a context that is cancelled on SIGTERM or SIGINT
+func shutdownContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	go func() {
+		sig := <-signals
+		log.WithFields(log.Fields{"signal": sig.String()}).Info("shutting down")
+		cancel()
+	}()
+	return ctx
+}
+
+// shutdownServer stops accepting connections and waits for in-flight requests to finish
+func shutdownServer(server *http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.WithError(err).Error("shutting down webhook server")
+	}
+}
+
+// resourceBuilders returns the builders for the children of the configured routing backend
+func resourceBuilders(config *cfg.Config) []webhook.K8sResourceBuilder {
+	switch config.RoutingBackend {
+	case cfg.RoutingBackendGatewayAPI:
+		var parentRefs []webhook.ParentReference
+		for _, ref := range config.GatewayAPI.ParentRefs {
+			parentRefs = append(parentRefs, webhook.ParentReference{Namespace: ref.Namespace, Name: ref.Name})
+		}
+		return []webhook.K8sResourceBuilder{
+			&webhook.ServiceBuilder{},
+			&webhook.GatewayAPIBuilder{ParentRefs: parentRefs},
+		}
+	case cfg.RoutingBackendContour:
+		return []webhook.K8sResourceBuilder{
+			&webhook.ServiceBuilder{},
+			&webhook.HTTPProxyBuilder{},
+		}
+	case cfg.RoutingBackendIngress:
+		return []webhook.K8sResourceBuilder{
+			&webhook.ServiceBuilder{},
+			&webhook.IngressBuilder{IngressClassName: config.Ingress.ClassName},
+		}
+	case cfg.RoutingBackendXDS:
+		// routes are served over xDS with the endpoints of the app pods, the Services
+		// keep the destinations reachable from inside the cluster
+		return []webhook.K8sResourceBuilder{
+			&webhook.ServiceBuilder{},
+		}
+	default:
+		builders := []webhook.K8sResourceBuilder{
+			&webhook.ServiceBuilder{},
+			&webhook.VirtualServiceBuilder{
+				IstioGateways: config.Istio.Gateways,
+				HTTPSGateway:  config.Istio.HTTPS.Enabled,
+				RouteServices: config.RouteServiceSecret != "",
+			},
+			&webhook.TCPRouteBuilder{GatewaySelector: config.Istio.IngressGatewaySelector},
+			&webhook.DestinationRuleBuilder{SessionCookieName: config.Istio.StickySessionCookieName},
+			&webhook.RouteServiceBuilder{},
+			&webhook.RequestSizeBuilder{},
+			&webhook.InternalRouteBuilder{ServiceNamespace: config.WorkloadsNamespace},
+		}
+		if config.Istio.HTTPS.Enabled {
+			builders = append(builders, &webhook.HTTPSGatewayBuilder{
+				GatewaySelector: config.Istio.IngressGatewaySelector,
+				TLSSecrets:      config.Istio.HTTPS.TLSSecrets,
+				HTTPSRedirect:   config.Istio.HTTPS.Redirect,
+			})
+		}
+		return builders
+	}
+}
+
+// startXDS serves the routes of the snapshot repo and the endpoints of their app pods to Envoys
+// over xDS until the context is done
+func startXDS(ctx context.Context, config *cfg.Config, restConfig *rest.Config, snapshotRepo snapshotRepo) error {
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("building kubernetes client: %w", err)
+	}
+	podEndpoints, err := xds.NewPodEndpoints(ctx, clientset, config.WorkloadsNamespace)
+	if err != nil {
+		return fmt.Errorf("watching app pods: %w", err)
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", config.XDS.Port))
+	if err != nil {
+		return err
+	}
+
+	snapshotCache := xds.NewSnapshotCache()
+	grpcServer := xds.NewGRPCServer(ctx, snapshotCache)
+	go func() {
+		log.WithFields(log.Fields{"port": config.XDS.Port}).Info("starting xds server")
+		if err := grpcServer.Serve(listener); err != nil {
+			log.WithError(err).Fatal("serving xds")
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		grpcServer.GracefulStop()
+	}()
+
+	pusher := &xds.Pusher{
+		Cache: snapshotCache,
+		Translator: &xds.Translator{
+			HTTPListenerPort: config.XDS.HTTPListenerPort,
+			Endpoints:        podEndpoints,
+		},
+		SnapshotRepo:    snapshotRepo,
+		EndpointChanges: podEndpoints.Changes(),
+	}
+	go pusher.Run(ctx)
+	return nil
+}
+
+// newReconciler builds a reconciler that talks to the API server of the cluster cfroutesync runs in
+func newReconciler(restConfig *rest.Config, lineage *webhook.Lineage, snapshotRepo snapshotRepo, childResources []schema.GroupVersionResource) (*reconciler.Reconciler, error) {
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("building dynamic client: %w", err)
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("building discovery client: %w", err)
+	}
+
+	return &reconciler.Reconciler{
+		KubeClient: &reconciler.DynamicKubeClient{
+			Client:       dynamicClient,
+			Mapper:       restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient)),
+			FieldManager: "cfroutesync",
+		},
+		Syncer:         lineage,
+		SnapshotRepo:   snapshotRepo,
+		ChildResources: childResources,
+		ResyncInterval: reconcilerResyncInterval,
+		Now:            time.Now,
+	}, nil
 }
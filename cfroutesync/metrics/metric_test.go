@@ -12,8 +12,18 @@ var _ = Describe("Metrics", func() {
 		Expect(m.ObservedValues.LastUpdatedAt.Desc().String()).To(ContainSubstring("cfroutesync_last_updated_at"))
 	})
 
+	It("has a LastSyncedAt gauge", func() {
+		m := metrics.DefaultMetrics
+		Expect(m.ObservedValues.LastSyncedAt.Desc().String()).To(ContainSubstring("cfroutesync_last_synced_at"))
+	})
+
 	It("has a NumberOfRoutes gauge", func() {
 		m := metrics.DefaultMetrics
 		Expect(m.ObservedValues.NumberOfRoutes.Desc().String()).To(ContainSubstring("cfroutesync_fetched_routes"))
 	})
+
+	It("has a NumberOfInvalidAnnotations gauge", func() {
+		m := metrics.DefaultMetrics
+		Expect(m.ObservedValues.NumberOfInvalidAnnotations.Desc().String()).To(ContainSubstring("cfroutesync_invalid_route_annotations"))
+	})
 })
@@ -21,7 +21,10 @@ type Metrics struct {
 
 type ObservedValues struct {
 	LastUpdatedAt  prometheus.Gauge
+	LastSyncedAt   prometheus.Gauge
 	NumberOfRoutes prometheus.Gauge
+
+	NumberOfInvalidAnnotations prometheus.Gauge
 }
 
 func initMetrics() Metrics {
@@ -30,13 +33,19 @@ func initMetrics() Metrics {
 		ObservedValues: ObservedValues{
 			LastUpdatedAt: prometheus.NewGauge(
 				prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "last_updated_at", Help: "Unix timestamp indicating last successful sync"}),
+			LastSyncedAt: prometheus.NewGauge(
+				prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "last_synced_at", Help: "Unix timestamp of the last successful fetch from Cloud Controller or replication from the leader"}),
 			NumberOfRoutes: prometheus.NewGauge(
 				prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "fetched_routes", Help: "Number of routes fetched from Cloud Controller"}),
+			NumberOfInvalidAnnotations: prometheus.NewGauge(
+				prometheus.GaugeOpts{Namespace: metricsNamespace, Name: "invalid_route_annotations", Help: "Number of route annotations that are ignored because their values are invalid"}),
 		},
 	}
 
 	prometheus.MustRegister(m.ObservedValues.LastUpdatedAt)
+	prometheus.MustRegister(m.ObservedValues.LastSyncedAt)
 	prometheus.MustRegister(m.ObservedValues.NumberOfRoutes)
+	prometheus.MustRegister(m.ObservedValues.NumberOfInvalidAnnotations)
 
 	return m
 }
@@ -45,3 +54,13 @@ func Update(snapshot *models.RouteSnapshot) {
 	DefaultMetrics.ObservedValues.LastUpdatedAt.SetToCurrentTime()
 	DefaultMetrics.ObservedValues.NumberOfRoutes.Set(float64(len(snapshot.Routes)))
 }
+
+// Synced records a successful sync, so that a stale snapshot can be alerted on
+func Synced() {
+	DefaultMetrics.ObservedValues.LastSyncedAt.SetToCurrentTime()
+}
+
+// InvalidAnnotations records how many route annotations are ignored, so that app teams can be told about them
+func InvalidAnnotations(count int) {
+	DefaultMetrics.ObservedValues.NumberOfInvalidAnnotations.Set(float64(count))
+}
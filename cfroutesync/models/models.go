@@ -1,21 +1,80 @@
 package models
 
-import "fmt"
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
 
 type RouteSnapshot struct {
 	Routes []Route
+
+	// Container to container network policies, only fetched when network policies are enabled
+	Policies []Policy `json:",omitempty"`
+
+	// Incremented by the SnapshotRepo every time the content of its snapshot changes
+	Generation uint64
+
+	// Hash of the routes and policies, set by the SnapshotRepo
+	Hash string
+
+	// True when the snapshot was restored from disk and has not been confirmed by Cloud Controller since
+	Stale bool `json:"-"`
+
+	// When Cloud Controller last confirmed a snapshot restored from disk, zero unless Stale
+	ConfirmedAt time.Time `json:"-"`
 }
 
+// Route protocols
+const (
+	ProtocolHTTP = "http"
+	ProtocolTCP  = "tcp"
+)
+
+// Destination protocols, the protocol the app speaks on the destination port.
+// Cloud Controller v3 reports http1, http2 or tcp.
+const (
+	ProtocolHTTP1 = "http1"
+	ProtocolHTTP2 = "http2"
+)
+
 type Route struct {
-	Guid         string
-	Host         string
-	Path         string
-	Url          string
+	Guid     string
+	Host     string
+	Path     string
+	Url      string
+	Protocol string
+
+	// Port the route listens on, only set for TCP routes
+	Port int
+
+	Options RouteOptions
+
+	// Metadata set on the route in Cloud Controller, see the webhook package for the annotations it understands
+	Labels      map[string]string `json:",omitempty"`
+	Annotations map[string]string `json:",omitempty"`
+
+	// URL of the route service bound to the route, which all its traffic goes through, empty when not bound
+	RouteServiceUrl string
+
 	Domain       Domain
 	Space        Space
 	Destinations []Destination
 }
 
+// Load balancing algorithms of route options
+const (
+	LoadBalancingRoundRobin      = "round-robin"
+	LoadBalancingLeastConnection = "least-connection"
+)
+
+type RouteOptions struct {
+	// One of the LoadBalancing constants, empty when not set
+	LoadBalancing string
+}
+
 type Domain struct {
 	Guid     string
 	Name     string
@@ -32,10 +91,11 @@ type Organization struct {
 }
 
 type Destination struct {
-	Guid   string
-	App    App
-	Weight *int
-	Port   int
+	Guid     string
+	App      App
+	Weight   *int
+	Port     int
+	Protocol string
 }
 
 type App struct {
@@ -47,6 +107,29 @@ type Process struct {
 	Type string
 }
 
+// Policy allows the source app to connect to the destination app on a range of ports,
+// like a policy created with cf add-network-policy
+type Policy struct {
+	SourceAppGuid      string
+	DestinationAppGuid string
+
+	// Either tcp or udp
+	Protocol  string
+	StartPort int
+	EndPort   int
+}
+
+func (r Route) IsTCP() bool {
+	return r.Protocol == ProtocolTCP
+}
+
+// Host of wildcard routes, which match every host of their domain without a route of its own
+const WildcardHost = "*"
+
+func (r Route) IsWildcard() bool {
+	return r.Host == WildcardHost
+}
+
 func (r Route) FQDN() string {
 	if r.Host == "" {
 		return r.Domain.Name
@@ -54,6 +137,43 @@ func (r Route) FQDN() string {
 	return fmt.Sprintf("%s.%s", r.Host, r.Domain.Name)
 }
 
+// ContentHash returns a hash of the routes and policies in the snapshot that does not depend on their order
+func (s *RouteSnapshot) ContentHash() string {
+	routes := make([]Route, len(s.Routes))
+	copy(routes, s.Routes)
+	sort.SliceStable(routes, func(i, j int) bool {
+		return routes[i].Guid < routes[j].Guid
+	})
+
+	policies := make([]Policy, len(s.Policies))
+	copy(policies, s.Policies)
+	SortPolicies(policies)
+
+	// marshaling plain structs, slices and pointers cannot fail
+	bytes, _ := json.Marshal(RouteSnapshot{Routes: routes, Policies: policies})
+	return fmt.Sprintf("%x", sha256.Sum256(bytes))
+}
+
+// SortPolicies sorts by destination, then source, protocol and ports
+func SortPolicies(policies []Policy) {
+	sort.Slice(policies, func(i, j int) bool {
+		a, b := policies[i], policies[j]
+		if a.DestinationAppGuid != b.DestinationAppGuid {
+			return a.DestinationAppGuid < b.DestinationAppGuid
+		}
+		if a.SourceAppGuid != b.SourceAppGuid {
+			return a.SourceAppGuid < b.SourceAppGuid
+		}
+		if a.Protocol != b.Protocol {
+			return a.Protocol < b.Protocol
+		}
+		if a.StartPort != b.StartPort {
+			return a.StartPort < b.StartPort
+		}
+		return a.EndPort < b.EndPort
+	})
+}
+
 func IntPtr(x int) *int {
 	return &x
 }
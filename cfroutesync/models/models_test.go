@@ -36,4 +36,58 @@ var _ = Describe("Route", func() {
 			})
 		})
 	})
+
+	Describe("IsWildcard()", func() {
+		It("returns true only for the wildcard host", func() {
+			Expect(models.Route{Host: "*", Domain: models.Domain{Name: "example.com"}}.IsWildcard()).To(BeTrue())
+			Expect(models.Route{Host: "host", Domain: models.Domain{Name: "example.com"}}.IsWildcard()).To(BeFalse())
+			Expect(models.Route{Domain: models.Domain{Name: "example.com"}}.IsWildcard()).To(BeFalse())
+		})
+	})
+
+	Describe("ContentHash()", func() {
+		var snapshot *models.RouteSnapshot
+
+		BeforeEach(func() {
+			snapshot = &models.RouteSnapshot{
+				Routes: []models.Route{
+					{Guid: "route-0", Host: "host-0"},
+					{Guid: "route-1", Host: "host-1"},
+				},
+			}
+		})
+
+		It("does not depend on the order of the routes", func() {
+			reordered := &models.RouteSnapshot{
+				Routes: []models.Route{snapshot.Routes[1], snapshot.Routes[0]},
+			}
+			Expect(reordered.ContentHash()).To(Equal(snapshot.ContentHash()))
+		})
+
+		It("does not depend on the generation", func() {
+			hash := snapshot.ContentHash()
+			snapshot.Generation = 42
+			Expect(snapshot.ContentHash()).To(Equal(hash))
+		})
+
+		It("changes when a route changes", func() {
+			hash := snapshot.ContentHash()
+			snapshot.Routes[1].Path = "/some-path"
+			Expect(snapshot.ContentHash()).NotTo(Equal(hash))
+		})
+
+		It("changes when a policy changes, but not when policies are reordered", func() {
+			snapshot.Policies = []models.Policy{
+				{SourceAppGuid: "app-0", DestinationAppGuid: "app-1", Protocol: "tcp", StartPort: 8080, EndPort: 8080},
+				{SourceAppGuid: "app-1", DestinationAppGuid: "app-0", Protocol: "tcp", StartPort: 8080, EndPort: 8080},
+			}
+			hash := snapshot.ContentHash()
+
+			snapshot.Policies[0], snapshot.Policies[1] = snapshot.Policies[1], snapshot.Policies[0]
+			Expect(snapshot.ContentHash()).To(Equal(hash))
+
+			snapshot.Policies[1].EndPort = 8081
+			Expect(snapshot.ContentHash()).NotTo(Equal(hash))
+		})
+	})
 })
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// PersistentSnapshotRepo is a SnapshotRepo that also keeps its latest snapshot in a file,
+// so that the last known routes can be served right after a restart, even while
+// Cloud Controller is unavailable
+type PersistentSnapshotRepo struct {
+	SnapshotRepo
+
+	// Path of the file the snapshot is written to
+	Path string
+
+	// Time recorded as the modification time of the file when Cloud Controller confirms the snapshot
+	Now func() time.Time
+
+	writeMutex sync.Mutex
+}
+
+// Load restores the snapshot from the file, marked as stale until Cloud Controller confirms it.
+// The modification time of the file tells when Cloud Controller last confirmed the snapshot.
+// A missing file is not an error, since there is nothing to restore on the very first start.
+func (r *PersistentSnapshotRepo) Load() error {
+	bytes, err := ioutil.ReadFile(r.Path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading snapshot: %w", err)
+	}
+
+	info, err := os.Stat(r.Path)
+	if err != nil {
+		return fmt.Errorf("stat snapshot: %w", err)
+	}
+
+	snapshot := &RouteSnapshot{}
+	err = json.Unmarshal(bytes, snapshot)
+	if err != nil {
+		return fmt.Errorf("unmarshal snapshot: %w", err)
+	}
+	snapshot.ConfirmedAt = info.ModTime()
+
+	if r.restore(snapshot) {
+		log.WithFields(log.Fields{
+			"path":       r.Path,
+			"generation": snapshot.Generation,
+			"routes":     len(snapshot.Routes),
+			"confirmed":  snapshot.ConfirmedAt,
+		}).Info("restored stale snapshot from disk")
+	}
+	return nil
+}
+
+// Put stores the snapshot and writes it to the file whenever the stored snapshot changed.
+// When it did not change, only the modification time of the file is updated to record
+// that Cloud Controller confirmed it.
+// Failing to write is logged rather than returned: the in-memory snapshot is still valid.
+func (r *PersistentSnapshotRepo) Put(snapshot *RouteSnapshot) {
+	r.writeMutex.Lock()
+	defer r.writeMutex.Unlock()
+
+	before, _ := r.SnapshotRepo.Get()
+	r.SnapshotRepo.Put(snapshot)
+	after, _ := r.SnapshotRepo.Get()
+
+	var err error
+	if after == before {
+		now := r.Now()
+		err = os.Chtimes(r.Path, now, now)
+		if os.IsNotExist(err) {
+			err = r.write(after)
+		}
+	} else {
+		err = r.write(after)
+	}
+	if err != nil {
+		log.WithError(err).WithFields(log.Fields{"path": r.Path}).Error("persisting snapshot")
+	}
+}
+
+// write replaces the file atomically, so that a crash mid-write never leaves a partial snapshot behind
+func (r *PersistentSnapshotRepo) write(snapshot *RouteSnapshot) error {
+	bytes, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("marshal snapshot: %w", err)
+	}
+
+	tmpFile, err := ioutil.TempFile(filepath.Dir(r.Path), filepath.Base(r.Path)+".tmp-")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.Write(bytes)
+	if err == nil {
+		err = tmpFile.Sync()
+	}
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("write temp file: %w", err)
+	}
+
+	err = os.Rename(tmpFile.Name(), r.Path)
+	if err != nil {
+		return fmt.Errorf("rename temp file: %w", err)
+	}
+	return nil
+}
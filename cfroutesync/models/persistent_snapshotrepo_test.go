@@ -0,0 +1,145 @@
+package models_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PersistentSnapshotRepo", func() {
+	var (
+		dir  string
+		path string
+		repo *models.PersistentSnapshotRepo
+
+		currentTime = time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+		now         = func() time.Time { return currentTime }
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "snapshots")
+		Expect(err).NotTo(HaveOccurred())
+		path = filepath.Join(dir, "snapshot.json")
+		repo = &models.PersistentSnapshotRepo{Path: path, Now: now}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("writes the snapshot to disk on Put", func() {
+		repo.Put(&models.RouteSnapshot{Routes: []models.Route{{Guid: "foo"}}})
+
+		Expect(path).To(BeAnExistingFile())
+		files, err := ioutil.ReadDir(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(HaveLen(1))
+	})
+
+	It("serves a restored snapshot after a restart, marked as stale", func() {
+		repo.Put(&models.RouteSnapshot{Routes: []models.Route{{Guid: "foo"}}})
+		repo.Put(&models.RouteSnapshot{Routes: []models.Route{{Guid: "bar"}}})
+
+		restarted := &models.PersistentSnapshotRepo{Path: path, Now: now}
+		Expect(restarted.Load()).To(Succeed())
+
+		snapshot, ok := restarted.Get()
+		Expect(ok).To(BeTrue())
+		Expect(snapshot.Routes).To(Equal([]models.Route{{Guid: "bar"}}))
+		Expect(snapshot.Generation).To(Equal(uint64(2)))
+		Expect(snapshot.Stale).To(BeTrue())
+	})
+
+	It("restores when Cloud Controller last confirmed the snapshot", func() {
+		repo.Put(&models.RouteSnapshot{Routes: []models.Route{{Guid: "foo"}}})
+		confirmed := currentTime.Add(-time.Hour)
+		Expect(os.Chtimes(path, confirmed, confirmed)).To(Succeed())
+
+		restarted := &models.PersistentSnapshotRepo{Path: path, Now: now}
+		Expect(restarted.Load()).To(Succeed())
+
+		snapshot, _ := restarted.Get()
+		Expect(snapshot.ConfirmedAt).To(BeTemporally("==", confirmed))
+	})
+
+	Context("when the same content is put again", func() {
+		It("records the confirmation without rewriting the file", func() {
+			repo.Put(&models.RouteSnapshot{Routes: []models.Route{{Guid: "foo"}}})
+			confirmed := currentTime.Add(-time.Hour)
+			Expect(os.Chtimes(path, confirmed, confirmed)).To(Succeed())
+
+			repo.Put(&models.RouteSnapshot{Routes: []models.Route{{Guid: "foo"}}})
+
+			info, err := os.Stat(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.ModTime()).To(BeTemporally("==", currentTime))
+		})
+	})
+
+	Context("after restoring a snapshot", func() {
+		var restarted *models.PersistentSnapshotRepo
+
+		BeforeEach(func() {
+			repo.Put(&models.RouteSnapshot{Routes: []models.Route{{Guid: "foo"}}})
+			restarted = &models.PersistentSnapshotRepo{Path: path, Now: now}
+			Expect(restarted.Load()).To(Succeed())
+		})
+
+		It("is no longer stale once the same content is fetched again", func() {
+			updates, unsubscribe := restarted.Subscribe()
+			defer unsubscribe()
+			Eventually(updates).Should(Receive())
+
+			restarted.Put(&models.RouteSnapshot{Routes: []models.Route{{Guid: "foo"}}})
+
+			snapshot, _ := restarted.Get()
+			Expect(snapshot.Stale).To(BeFalse())
+			Expect(snapshot.ConfirmedAt).To(BeZero())
+			Expect(snapshot.Generation).To(Equal(uint64(1)))
+			Consistently(updates).ShouldNot(Receive())
+		})
+
+		It("continues counting generations from the restored snapshot", func() {
+			restarted.Put(&models.RouteSnapshot{Routes: []models.Route{{Guid: "bar"}}})
+
+			snapshot, _ := restarted.Get()
+			Expect(snapshot.Stale).To(BeFalse())
+			Expect(snapshot.Generation).To(Equal(uint64(2)))
+		})
+	})
+
+	Context("when there is no snapshot on disk", func() {
+		It("loads nothing", func() {
+			Expect(repo.Load()).To(Succeed())
+			_, ok := repo.Get()
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Context("when the snapshot on disk is corrupt", func() {
+		It("returns a helpful error", func() {
+			Expect(ioutil.WriteFile(path, []byte("%%%"), 0600)).To(Succeed())
+			Expect(repo.Load()).To(MatchError(ContainSubstring("unmarshal snapshot")))
+		})
+	})
+
+	Context("when a snapshot has already been fetched", func() {
+		It("does not replace it with the one on disk", func() {
+			repo.Put(&models.RouteSnapshot{Routes: []models.Route{{Guid: "foo"}}})
+			other := &models.PersistentSnapshotRepo{Path: path, Now: now}
+			other.Put(&models.RouteSnapshot{Routes: []models.Route{{Guid: "bar"}}})
+
+			Expect(repo.Load()).To(Succeed())
+			snapshot, _ := repo.Get()
+			Expect(snapshot.Routes).To(Equal([]models.Route{{Guid: "foo"}}))
+			Expect(snapshot.Stale).To(BeFalse())
+		})
+	})
+})
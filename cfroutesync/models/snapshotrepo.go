@@ -1,10 +1,15 @@
 package models
 
-import "sync"
+import (
+	"sync"
+	"time"
+)
 
 type SnapshotRepo struct {
-	mutex    sync.RWMutex
-	snapshot *RouteSnapshot
+	mutex       sync.RWMutex
+	snapshot    *RouteSnapshot
+	subscribers map[int]chan *RouteSnapshot
+	nextID      int
 }
 
 func (r *SnapshotRepo) Get() (*RouteSnapshot, bool) {
@@ -16,8 +21,101 @@ func (r *SnapshotRepo) Get() (*RouteSnapshot, bool) {
 	return r.snapshot, true
 }
 
+// Put stores a copy of the snapshot with its Generation and Hash filled in.
+// If the content is the same as the current snapshot, the current one is kept
+// and subscribers are not notified.
 func (r *SnapshotRepo) Put(snapshot *RouteSnapshot) {
+	hash := snapshot.ContentHash()
+
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
-	r.snapshot = snapshot
+
+	var generation uint64
+	if r.snapshot != nil {
+		if r.snapshot.Hash == hash {
+			if r.snapshot.Stale {
+				// the restored snapshot turned out to be up to date
+				confirmed := *r.snapshot
+				confirmed.Stale = false
+				confirmed.ConfirmedAt = time.Time{}
+				r.snapshot = &confirmed
+			}
+			return
+		}
+		generation = r.snapshot.Generation
+	}
+
+	stored := *snapshot
+	stored.Generation = generation + 1
+	stored.Hash = hash
+	stored.Stale = false
+	stored.ConfirmedAt = time.Time{}
+	r.snapshot = &stored
+
+	r.notifyAll()
+}
+
+// restore stores a previously persisted snapshot, marked as stale, unless the repo already has one
+func (r *SnapshotRepo) restore(snapshot *RouteSnapshot) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.snapshot != nil {
+		return false
+	}
+
+	restored := *snapshot
+	restored.Hash = snapshot.ContentHash()
+	restored.Stale = true
+	r.snapshot = &restored
+
+	r.notifyAll()
+	return true
+}
+
+func (r *SnapshotRepo) notifyAll() {
+	for _, subscriber := range r.subscribers {
+		notify(subscriber, r.snapshot)
+	}
+}
+
+// Subscribe returns a channel that receives the snapshot every time its content changes,
+// starting with the current snapshot if there is one. A subscriber that falls behind
+// only receives the latest snapshot. Calling the returned function unsubscribes and
+// closes the channel.
+func (r *SnapshotRepo) Subscribe() (<-chan *RouteSnapshot, func()) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.subscribers == nil {
+		r.subscribers = make(map[int]chan *RouteSnapshot)
+	}
+	id := r.nextID
+	r.nextID++
+
+	subscriber := make(chan *RouteSnapshot, 1)
+	r.subscribers[id] = subscriber
+	if r.snapshot != nil {
+		notify(subscriber, r.snapshot)
+	}
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			r.mutex.Lock()
+			defer r.mutex.Unlock()
+			delete(r.subscribers, id)
+			close(subscriber)
+		})
+	}
+	return subscriber, unsubscribe
+}
+
+// notify replaces any snapshot the subscriber has not received yet with the given one
+func notify(subscriber chan *RouteSnapshot, snapshot *RouteSnapshot) {
+	select {
+	case <-subscriber:
+	default:
+	}
+	subscriber <- snapshot
 }
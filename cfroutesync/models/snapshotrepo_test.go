@@ -23,7 +23,111 @@ var _ = Describe("SnapshotRepo", func() {
 		snapshot, ok := repo.Get()
 
 		Expect(ok).To(BeTrue())
-		Expect(snapshot).To(Equal(thing))
+		Expect(snapshot.Routes).To(Equal(thing.Routes))
+	})
+
+	Describe("versioning", func() {
+		var repo *models.SnapshotRepo
+
+		BeforeEach(func() {
+			repo = &models.SnapshotRepo{}
+			repo.Put(&models.RouteSnapshot{Routes: []models.Route{{Guid: "foo"}}})
+		})
+
+		It("sets the generation and content hash of the stored snapshot", func() {
+			snapshot, _ := repo.Get()
+			Expect(snapshot.Generation).To(Equal(uint64(1)))
+			Expect(snapshot.Hash).To(Equal(snapshot.ContentHash()))
+			Expect(snapshot.Hash).NotTo(BeEmpty())
+		})
+
+		It("does not modify the snapshot that was put in", func() {
+			thing := &models.RouteSnapshot{Routes: []models.Route{{Guid: "bar"}}}
+			repo.Put(thing)
+			Expect(thing.Generation).To(BeZero())
+			Expect(thing.Hash).To(BeEmpty())
+		})
+
+		It("increments the generation when the content changes", func() {
+			repo.Put(&models.RouteSnapshot{Routes: []models.Route{{Guid: "foo"}, {Guid: "bar"}}})
+
+			snapshot, _ := repo.Get()
+			Expect(snapshot.Generation).To(Equal(uint64(2)))
+			Expect(snapshot.Routes).To(HaveLen(2))
+		})
+
+		It("keeps the current snapshot when the content is the same", func() {
+			before, _ := repo.Get()
+			repo.Put(&models.RouteSnapshot{Routes: []models.Route{{Guid: "foo"}}})
+
+			after, _ := repo.Get()
+			Expect(after).To(BeIdenticalTo(before))
+			Expect(after.Generation).To(Equal(uint64(1)))
+		})
+	})
+
+	Describe("Subscribe", func() {
+		var repo *models.SnapshotRepo
+
+		BeforeEach(func() {
+			repo = &models.SnapshotRepo{}
+		})
+
+		It("notifies subscribers when the content changes", func() {
+			updates, unsubscribe := repo.Subscribe()
+			defer unsubscribe()
+			Consistently(updates).ShouldNot(Receive())
+
+			repo.Put(&models.RouteSnapshot{Routes: []models.Route{{Guid: "foo"}}})
+
+			var snapshot *models.RouteSnapshot
+			Eventually(updates).Should(Receive(&snapshot))
+			Expect(snapshot.Generation).To(Equal(uint64(1)))
+			Expect(snapshot.Routes).To(Equal([]models.Route{{Guid: "foo"}}))
+		})
+
+		It("sends the current snapshot to new subscribers", func() {
+			repo.Put(&models.RouteSnapshot{Routes: []models.Route{{Guid: "foo"}}})
+
+			updates, unsubscribe := repo.Subscribe()
+			defer unsubscribe()
+
+			var snapshot *models.RouteSnapshot
+			Eventually(updates).Should(Receive(&snapshot))
+			Expect(snapshot.Generation).To(Equal(uint64(1)))
+		})
+
+		It("does not notify subscribers when the content is unchanged", func() {
+			repo.Put(&models.RouteSnapshot{Routes: []models.Route{{Guid: "foo"}}})
+			updates, unsubscribe := repo.Subscribe()
+			defer unsubscribe()
+			Eventually(updates).Should(Receive())
+
+			repo.Put(&models.RouteSnapshot{Routes: []models.Route{{Guid: "foo"}}})
+			Consistently(updates).ShouldNot(Receive())
+		})
+
+		It("only keeps the latest snapshot for subscribers that fall behind", func() {
+			updates, unsubscribe := repo.Subscribe()
+			defer unsubscribe()
+
+			repo.Put(&models.RouteSnapshot{Routes: []models.Route{{Guid: "foo"}}})
+			repo.Put(&models.RouteSnapshot{Routes: []models.Route{{Guid: "bar"}}})
+
+			var snapshot *models.RouteSnapshot
+			Eventually(updates).Should(Receive(&snapshot))
+			Expect(snapshot.Generation).To(Equal(uint64(2)))
+			Consistently(updates).ShouldNot(Receive())
+		})
+
+		It("closes the channel on unsubscribe", func() {
+			updates, unsubscribe := repo.Subscribe()
+			unsubscribe()
+			Eventually(updates).Should(BeClosed())
+
+			repo.Put(&models.RouteSnapshot{Routes: []models.Route{{Guid: "foo"}}})
+			unsubscribe()
+		})
 	})
 
 	Context("when no snapshot has been Put into the repo", func() {
@@ -65,6 +169,15 @@ var _ = Describe("SnapshotRepo", func() {
 			complete.Done()
 		}(repo)
 
+		complete.Add(1)
+		go func(repo *models.SnapshotRepo) {
+			for i := 0; i < numCalls; i++ {
+				_, unsubscribe := repo.Subscribe()
+				unsubscribe()
+			}
+			complete.Done()
+		}(repo)
+
 		complete.Wait()
 		// if we've made it this far without a race detected, then
 		// the snapshotRepo is safe for concurrent use!
@@ -0,0 +1,65 @@
+package policyclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Client lists the container to container network policies of the CF network policy server
+type Client struct {
+	JSONClient jsonClient
+
+	// Base URL of the external policy server API, usually the same as the Cloud Controller one
+	BaseURL string
+}
+
+//go:generate counterfeiter -o fakes/json_client.go --fake-name JSONClient . jsonClient
+type jsonClient interface {
+	MakeRequest(*http.Request, interface{}) error
+}
+
+type Policy struct {
+	Source      Source
+	Destination Destination
+}
+
+type Source struct {
+	ID string
+}
+
+type Destination struct {
+	ID       string
+	Protocol string
+	Ports    Ports
+}
+
+type Ports struct {
+	Start int
+	End   int
+}
+
+type listPoliciesResponse struct {
+	TotalPolicies int `json:"total_policies"`
+	Policies      []Policy
+}
+
+// ListPolicies returns all policies, which requires a token with the network.admin scope
+func (c *Client) ListPolicies(ctx context.Context, token string) ([]Policy, error) {
+	reqURL := fmt.Sprintf("%s/networking/v1/external/policies", c.BaseURL)
+	request, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Authorization", "bearer "+token)
+
+	var response listPoliciesResponse
+	err = c.JSONClient.MakeRequest(request, &response)
+	if err != nil {
+		return nil, err
+	}
+	if response.Policies == nil {
+		return []Policy{}, nil
+	}
+	return response.Policies, nil
+}
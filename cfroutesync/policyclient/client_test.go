@@ -0,0 +1,109 @@
+package policyclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/policyclient"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/policyclient/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Policy Server Client", func() {
+	var (
+		client     *policyclient.Client
+		jsonClient *fakes.JSONClient
+		ctx        context.Context
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		jsonClient = &fakes.JSONClient{}
+		client = &policyclient.Client{
+			JSONClient: jsonClient,
+			BaseURL:    "https://api.example.com",
+		}
+
+		body := `{
+			"total_policies": 2,
+			"policies": [
+				{
+					"source": { "id": "app-0-guid" },
+					"destination": { "id": "app-1-guid", "protocol": "tcp", "ports": { "start": 8080, "end": 8080 } }
+				},
+				{
+					"source": { "id": "app-1-guid" },
+					"destination": { "id": "app-2-guid", "protocol": "udp", "ports": { "start": 5000, "end": 5010 } }
+				}
+			]
+		}`
+		jsonClient.MakeRequestStub = func(req *http.Request, responseStruct interface{}) error {
+			return json.Unmarshal([]byte(body), responseStruct)
+		}
+	})
+
+	Describe("ListPolicies", func() {
+		It("returns the policies", func() {
+			policies, err := client.ListPolicies(ctx, "fake-token")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(policies).To(Equal([]policyclient.Policy{
+				{
+					Source:      policyclient.Source{ID: "app-0-guid"},
+					Destination: policyclient.Destination{ID: "app-1-guid", Protocol: "tcp", Ports: policyclient.Ports{Start: 8080, End: 8080}},
+				},
+				{
+					Source:      policyclient.Source{ID: "app-1-guid"},
+					Destination: policyclient.Destination{ID: "app-2-guid", Protocol: "udp", Ports: policyclient.Ports{Start: 5000, End: 5010}},
+				},
+			}))
+		})
+
+		It("requests the external policies endpoint with the token and context", func() {
+			type key struct{}
+			ctx = context.WithValue(ctx, key{}, "some-value")
+
+			_, err := client.ListPolicies(ctx, "fake-token")
+			Expect(err).NotTo(HaveOccurred())
+
+			receivedRequest, _ := jsonClient.MakeRequestArgsForCall(0)
+			Expect(receivedRequest.Method).To(Equal("GET"))
+			Expect(receivedRequest.URL.String()).To(Equal("https://api.example.com/networking/v1/external/policies"))
+			Expect(receivedRequest.Header.Get("Authorization")).To(Equal("bearer fake-token"))
+			Expect(receivedRequest.Context().Value(key{})).To(Equal("some-value"))
+		})
+
+		Context("when there are no policies", func() {
+			It("returns an empty list", func() {
+				jsonClient.MakeRequestStub = nil
+
+				policies, err := client.ListPolicies(ctx, "fake-token")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(policies).To(BeEmpty())
+			})
+		})
+
+		Context("when the json client returns an error", func() {
+			It("returns the error", func() {
+				jsonClient.MakeRequestStub = nil
+				jsonClient.MakeRequestReturns(errors.New("potato"))
+
+				_, err := client.ListPolicies(ctx, "fake-token")
+				Expect(err).To(MatchError("potato"))
+			})
+		})
+
+		Context("when the url is malformed", func() {
+			It("returns an error", func() {
+				client.BaseURL = "%%%%%%%"
+
+				_, err := client.ListPolicies(ctx, "fake-token")
+				Expect(err).To(MatchError(ContainSubstring("invalid URL escape")))
+			})
+		})
+	})
+})
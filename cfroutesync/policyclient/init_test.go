@@ -0,0 +1,13 @@
+package policyclient_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestPolicyClient(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "PolicyClient Suite")
+}
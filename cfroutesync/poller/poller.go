@@ -0,0 +1,72 @@
+package poller
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Poller calls Poll every Interval until its context is done, backing off exponentially while Poll keeps failing
+type Poller struct {
+	// Name used in log messages
+	Name string
+
+	Poll func(ctx context.Context) error
+
+	// Time between the end of one successful poll and the start of the next, must be positive
+	Interval time.Duration
+
+	// Upper bound for the time between polls after failures, must be at least Interval
+	MaxBackoff time.Duration
+
+	// Returns a channel that fires after the duration, e.g. time.After
+	After func(time.Duration) <-chan time.Time
+
+	// Returns a random number in [0.0,1.0) to jitter the backoff with, e.g. the Float64 of a *rand.Rand.
+	// The source should be seeded differently in every replica, so that they do not retry in lockstep.
+	Random func() float64
+}
+
+// Run polls until the context is done
+func (p *Poller) Run(ctx context.Context) {
+	log.WithFields(log.Fields{"poller": p.Name, "interval": p.Interval.String()}).Info("starting poller")
+
+	failures := 0
+	for {
+		err := p.Poll(ctx)
+		if err != nil && ctx.Err() == nil {
+			failures++
+			log.WithError(err).WithFields(log.Fields{"poller": p.Name, "failures": failures}).Error("polling failed")
+		} else {
+			failures = 0
+		}
+
+		select {
+		case <-ctx.Done():
+			log.WithFields(log.Fields{"poller": p.Name}).Info("stopped poller")
+			return
+		case <-p.After(p.delay(failures)):
+		}
+	}
+}
+
+// delay returns Interval after a success. After failures it doubles for every consecutive failure,
+// up to MaxBackoff, with jitter so that replicas do not retry in lockstep.
+func (p *Poller) delay(failures int) time.Duration {
+	if failures == 0 {
+		return p.Interval
+	}
+
+	backoff := p.Interval
+	for i := 0; i < failures && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+
+	// wait somewhere between half and all of the backoff
+	half := backoff / 2
+	return half + time.Duration(p.Random()*float64(backoff-half))
+}
@@ -0,0 +1,129 @@
+package poller_test
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/poller"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Poller", func() {
+	var (
+		p       *poller.Poller
+		ctx     context.Context
+		cancel  context.CancelFunc
+		delays  []time.Duration
+		results []error
+	)
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		delays = nil
+		results = nil
+
+		p = &poller.Poller{
+			Name:       "test",
+			Interval:   time.Second,
+			MaxBackoff: 10 * time.Second,
+			Random:     func() float64 { return 1.0 },
+			Poll: func(context.Context) error {
+				result := results[0]
+				results = results[1:]
+				return result
+			},
+			After: func(d time.Duration) <-chan time.Time {
+				delays = append(delays, d)
+				c := make(chan time.Time, 1)
+				if len(results) == 0 {
+					cancel()
+					return c
+				}
+				c <- time.Time{}
+				return c
+			},
+		}
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("polls at the interval while polls succeed", func() {
+		results = []error{nil, nil, nil}
+
+		p.Run(ctx)
+
+		Expect(delays).To(Equal([]time.Duration{time.Second, time.Second, time.Second}))
+	})
+
+	It("backs off exponentially on consecutive failures, up to the max backoff", func() {
+		failure := errors.New("potato")
+		results = []error{failure, failure, failure, failure, failure}
+
+		p.Run(ctx)
+
+		Expect(delays).To(Equal([]time.Duration{
+			2 * time.Second,
+			4 * time.Second,
+			8 * time.Second,
+			10 * time.Second,
+			10 * time.Second,
+		}))
+	})
+
+	It("resets to the interval after a success", func() {
+		failure := errors.New("potato")
+		results = []error{failure, failure, nil, failure}
+
+		p.Run(ctx)
+
+		Expect(delays).To(Equal([]time.Duration{
+			2 * time.Second,
+			4 * time.Second,
+			time.Second,
+			2 * time.Second,
+		}))
+	})
+
+	It("jitters the backoff between half and all of it", func() {
+		p.Random = func() float64 { return 0.0 }
+		results = []error{errors.New("potato"), errors.New("potato")}
+
+		p.Run(ctx)
+
+		Expect(delays).To(Equal([]time.Duration{time.Second, 2 * time.Second}))
+	})
+
+	It("jitters with the injected random source", func() {
+		p.Random = rand.New(rand.NewSource(42)).Float64
+		results = []error{errors.New("potato"), errors.New("potato")}
+
+		p.Run(ctx)
+
+		expected := rand.New(rand.NewSource(42))
+		Expect(delays).To(Equal([]time.Duration{
+			time.Second + time.Duration(expected.Float64()*float64(time.Second)),
+			2*time.Second + time.Duration(expected.Float64()*float64(2*time.Second)),
+		}))
+	})
+
+	It("stops once the context is done", func() {
+		cancel()
+		polls := 0
+		p.Poll = func(context.Context) error {
+			polls++
+			return nil
+		}
+		p.After = func(time.Duration) <-chan time.Time {
+			return make(chan time.Time)
+		}
+
+		p.Run(ctx)
+
+		Expect(polls).To(Equal(1))
+	})
+})
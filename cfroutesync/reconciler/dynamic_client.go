@@ -0,0 +1,117 @@
+package reconciler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/webhook"
+)
+
+// The RouteBulkSync custom resource
+var ParentResource = schema.GroupVersionResource{
+	Group:    "apps.cloudfoundry.org",
+	Version:  "v1alpha1",
+	Resource: "routebulksyncs",
+}
+
+// implemented by the DeferredDiscoveryRESTMapper, which caches what it discovered
+type resettableMapper interface {
+	Reset()
+}
+
+// DynamicKubeClient talks to the API server using server-side apply, so that only
+// the fields cfroutesync sets are owned by it
+type DynamicKubeClient struct {
+	Client dynamic.Interface
+	// Reset when a kind cannot be mapped if it is resettable, like the DeferredDiscoveryRESTMapper,
+	// so that CRDs installed after startup are discovered
+	Mapper meta.RESTMapper
+
+	// Field manager name recorded by server-side apply
+	FieldManager string
+}
+
+func (c *DynamicKubeClient) ListParents() ([]webhook.BulkSync, error) {
+	list, err := c.Client.Resource(ParentResource).Namespace(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	parents := []webhook.BulkSync{}
+	for _, item := range list.Items {
+		bytes, err := item.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		var parent webhook.BulkSync
+		err = json.Unmarshal(bytes, &parent)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshal route bulk sync %s: %w", item.GetName(), err)
+		}
+		parents = append(parents, parent)
+	}
+	return parents, nil
+}
+
+func (c *DynamicKubeClient) Apply(child *unstructured.Unstructured) error {
+	gvk := child.GroupVersionKind()
+	mapping, err := c.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		if resettable, ok := c.Mapper.(resettableMapper); ok {
+			resettable.Reset()
+			mapping, err = c.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("mapping %s: %w", gvk, err)
+	}
+
+	bytes, err := child.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	force := true
+	_, err = c.Client.Resource(mapping.Resource).Namespace(child.GetNamespace()).Patch(
+		child.GetName(),
+		types.ApplyPatchType,
+		bytes,
+		metav1.PatchOptions{FieldManager: c.FieldManager, Force: &force},
+	)
+	return err
+}
+
+func (c *DynamicKubeClient) ListChildren(resource schema.GroupVersionResource, namespace string, labelSelector string) ([]unstructured.Unstructured, error) {
+	list, err := c.Client.Resource(resource).Namespace(namespace).List(metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func (c *DynamicKubeClient) Delete(resource schema.GroupVersionResource, namespace string, name string) error {
+	return c.Client.Resource(resource).Namespace(namespace).Delete(name, &metav1.DeleteOptions{})
+}
+
+func (c *DynamicKubeClient) UpdateStatus(parent webhook.BulkSync, status webhook.BulkSyncStatus) error {
+	patch, err := json.Marshal(map[string]interface{}{"status": status})
+	if err != nil {
+		return err
+	}
+
+	_, err = c.Client.Resource(ParentResource).Namespace(parent.Namespace).Patch(
+		parent.Name,
+		types.MergePatchType,
+		patch,
+		metav1.PatchOptions{},
+		"status",
+	)
+	return err
+}
@@ -0,0 +1,86 @@
+package reconciler_test
+
+import (
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/reconciler"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// resettingMapper only knows the kinds added to it once it is reset, like a
+// discovery mapper that has not seen a CRD installed after startup
+type resettingMapper struct {
+	*meta.DefaultRESTMapper
+	addOnReset []schema.GroupVersionKind
+	resets     int
+}
+
+func (m *resettingMapper) Reset() {
+	m.resets++
+	for _, gvk := range m.addOnReset {
+		m.Add(gvk, meta.RESTScopeNamespace)
+	}
+}
+
+var _ = Describe("DynamicKubeClient", func() {
+	Describe("Apply", func() {
+		var (
+			client        *reconciler.DynamicKubeClient
+			dynamicClient *fake.FakeDynamicClient
+			mapper        *resettingMapper
+			child         *unstructured.Unstructured
+
+			virtualServiceKind = schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1alpha3", Kind: "VirtualService"}
+		)
+
+		BeforeEach(func() {
+			dynamicClient = fake.NewSimpleDynamicClient(runtime.NewScheme())
+			dynamicClient.PrependReactor("patch", "*", func(k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, nil
+			})
+
+			mapper = &resettingMapper{DefaultRESTMapper: meta.NewDefaultRESTMapper(nil)}
+			client = &reconciler.DynamicKubeClient{
+				Client:       dynamicClient,
+				Mapper:       mapper,
+				FieldManager: "cfroutesync",
+			}
+
+			child = &unstructured.Unstructured{}
+			child.SetGroupVersionKind(virtualServiceKind)
+			child.SetNamespace("cf-workloads")
+			child.SetName("vs-0")
+		})
+
+		Context("when the kind of the child was installed after the mapper discovered the kinds", func() {
+			BeforeEach(func() {
+				mapper.addOnReset = []schema.GroupVersionKind{virtualServiceKind}
+			})
+
+			It("resets the mapper and applies the child", func() {
+				Expect(client.Apply(child)).To(Succeed())
+				Expect(mapper.resets).To(Equal(1))
+
+				actions := dynamicClient.Actions()
+				Expect(actions).To(HaveLen(1))
+				Expect(actions[0].GetVerb()).To(Equal("patch"))
+				Expect(actions[0].GetResource().Resource).To(Equal("virtualservices"))
+			})
+		})
+
+		Context("when the kind of the child is not installed", func() {
+			It("returns the error", func() {
+				err := client.Apply(child)
+				Expect(err).To(MatchError(ContainSubstring("no matches for kind \"VirtualService\"")))
+				Expect(mapper.resets).To(Equal(1))
+				Expect(dynamicClient.Actions()).To(BeEmpty())
+			})
+		})
+	})
+})
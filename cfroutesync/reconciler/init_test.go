@@ -0,0 +1,15 @@
+package reconciler_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
+)
+
+func TestReconciler(t *testing.T) {
+	RegisterFailHandler(Fail)
+	log.SetOutput(GinkgoWriter)
+	RunSpecs(t, "Reconciler Suite")
+}
@@ -0,0 +1,234 @@
+package reconciler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/webhook"
+)
+
+// Label put on every child so that children of a RouteBulkSync can be found again for garbage collection
+const OwnerLabel = "apps.cloudfoundry.org/route-bulk-sync-uid"
+
+//go:generate counterfeiter -o fakes/kube_client.go --fake-name KubeClient . kubeClient
+type kubeClient interface {
+	ListParents() ([]webhook.BulkSync, error)
+	Apply(child *unstructured.Unstructured) error
+	ListChildren(resource schema.GroupVersionResource, namespace string, labelSelector string) ([]unstructured.Unstructured, error)
+	Delete(resource schema.GroupVersionResource, namespace string, name string) error
+	UpdateStatus(parent webhook.BulkSync, status webhook.BulkSyncStatus) error
+}
+
+//go:generate counterfeiter -o fakes/syncer.go --fake-name Syncer . syncer
+type syncer interface {
+	Sync(syncRequest webhook.SyncRequest) (*webhook.SyncResponse, error)
+}
+
+//go:generate counterfeiter -o fakes/snapshot_repo.go --fake-name SnapshotRepo . snapshotRepo
+type snapshotRepo interface {
+	Subscribe() (<-chan *models.RouteSnapshot, func())
+}
+
+// Reconciler applies the children generated for every RouteBulkSync directly to the API server,
+// so that cfroutesync can run on clusters without metacontroller
+type Reconciler struct {
+	KubeClient   kubeClient
+	Syncer       syncer
+	SnapshotRepo snapshotRepo
+
+	// Resources the children may be of, these are searched for children to garbage collect
+	ChildResources []schema.GroupVersionResource
+
+	// How often to reconcile when the snapshot does not change, which picks up
+	// new RouteBulkSyncs and reverts changes made to the children by others
+	ResyncInterval time.Duration
+
+	Now func() time.Time
+}
+
+// Run reconciles whenever the snapshot changes and every ResyncInterval, until the context is done
+func (r *Reconciler) Run(ctx context.Context) {
+	updates, unsubscribe := r.SnapshotRepo.Subscribe()
+	defer unsubscribe()
+
+	ticker := time.NewTicker(r.ResyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-updates:
+		case <-ticker.C:
+		}
+
+		if err := r.ReconcileOnce(); err != nil {
+			log.WithError(err).Error("reconciling route bulk syncs")
+		}
+	}
+}
+
+// ReconcileOnce brings the children of every RouteBulkSync in line with the current snapshot
+func (r *Reconciler) ReconcileOnce() error {
+	parents, err := r.KubeClient.ListParents()
+	if err != nil {
+		return fmt.Errorf("list route bulk syncs: %w", err)
+	}
+
+	failed := 0
+	for _, parent := range parents {
+		invalidAnnotations, err := r.reconcileParent(parent)
+		status := webhook.BulkSyncStatus{
+			ObservedGeneration: parent.Generation,
+			LastSyncTime:       metav1.NewTime(r.Now()),
+			InvalidAnnotations: invalidAnnotations,
+		}
+		if err != nil {
+			failed++
+			status.Error = err.Error()
+			log.WithError(err).WithFields(log.Fields{
+				"namespace": parent.Namespace,
+				"name":      parent.Name,
+			}).Error("reconciling route bulk sync")
+		}
+
+		if statusErr := r.KubeClient.UpdateStatus(parent, status); statusErr != nil {
+			log.WithError(statusErr).WithFields(log.Fields{
+				"namespace": parent.Namespace,
+				"name":      parent.Name,
+			}).Error("updating route bulk sync status")
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to reconcile %d of %d route bulk syncs", failed, len(parents))
+	}
+	return nil
+}
+
+// reconcileParent returns the invalid annotations of the routes of the parent along with the errors
+func (r *Reconciler) reconcileParent(parent webhook.BulkSync) ([]string, error) {
+	response, err := r.Syncer.Sync(webhook.SyncRequest{Parent: parent})
+	if err != nil {
+		return nil, fmt.Errorf("sync: %w", err)
+	}
+	invalidAnnotations := response.Status.InvalidAnnotations
+
+	// Errors are collected rather than returned right away, so that one child that cannot be applied,
+	// e.g. because its CRD is not installed, does not hold back the other children and garbage collection
+	var errs []error
+
+	desired := make(map[string]bool)
+	failedKinds := make(map[schema.GroupKind]bool)
+	for _, child := range response.Children {
+		obj, err := toUnstructured(child)
+		if err != nil {
+			return invalidAnnotations, err
+		}
+		adopt(obj, parent)
+		desired[childKey(obj)] = true
+
+		err = r.KubeClient.Apply(obj)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("apply %s %s: %w", obj.GetKind(), obj.GetName(), err))
+			failedKinds[obj.GroupVersionKind().GroupKind()] = true
+		}
+	}
+
+	selector := fmt.Sprintf("%s=%s", OwnerLabel, parent.UID)
+	for _, resource := range r.ChildResources {
+		existing, err := r.KubeClient.ListChildren(resource, parent.Namespace, selector)
+		if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+			// e.g. the experimental TCPRoute CRD is not installed, so there are no such children to collect
+			log.WithError(err).Debugf("skipping garbage collection of %s", resource.Resource)
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("list %s: %w", resource.Resource, err))
+			continue
+		}
+
+		for _, obj := range existing {
+			if desired[childKey(&obj)] {
+				continue
+			}
+			// Children of a kind that failed to apply are kept until their replacements are in place
+			if failedKinds[obj.GroupVersionKind().GroupKind()] {
+				continue
+			}
+			err := r.KubeClient.Delete(resource, obj.GetNamespace(), obj.GetName())
+			if err != nil {
+				errs = append(errs, fmt.Errorf("delete %s %s: %w", obj.GetKind(), obj.GetName(), err))
+				continue
+			}
+			log.WithFields(log.Fields{"kind": obj.GetKind(), "name": obj.GetName()}).Info("deleted orphaned child")
+		}
+	}
+	return invalidAnnotations, joinErrors(errs)
+}
+
+// joinErrors combines the errors into one, keeping a single error as it is
+func joinErrors(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return errors.New(strings.Join(messages, "; "))
+}
+
+func toUnstructured(child webhook.K8sResource) (*unstructured.Unstructured, error) {
+	bytes, err := json.Marshal(child)
+	if err != nil {
+		return nil, fmt.Errorf("marshal child: %w", err)
+	}
+	obj := &unstructured.Unstructured{}
+	err = obj.UnmarshalJSON(bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal child: %w", err)
+	}
+	return obj, nil
+}
+
+// adopt places the child in the namespace of the parent and marks it as owned by the parent
+func adopt(child *unstructured.Unstructured, parent webhook.BulkSync) {
+	child.SetNamespace(parent.Namespace)
+
+	labels := child.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[OwnerLabel] = string(parent.UID)
+	child.SetLabels(labels)
+
+	isController := true
+	child.SetOwnerReferences([]metav1.OwnerReference{{
+		APIVersion:         parent.APIVersion,
+		Kind:               parent.Kind,
+		Name:               parent.Name,
+		UID:                parent.UID,
+		Controller:         &isController,
+		BlockOwnerDeletion: &isController,
+	}})
+}
+
+func childKey(obj *unstructured.Unstructured) string {
+	return fmt.Sprintf("%s/%s", obj.GroupVersionKind().GroupKind(), obj.GetName())
+}
@@ -0,0 +1,326 @@
+package reconciler_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/reconciler"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/reconciler/fakes"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/webhook"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ = Describe("Reconciler", func() {
+	var (
+		kubeClient   *fakes.KubeClient
+		syncer       *fakes.Syncer
+		snapshotRepo *fakes.SnapshotRepo
+		r            *reconciler.Reconciler
+		parent       webhook.BulkSync
+		now          time.Time
+
+		servicesResource        = schema.GroupVersionResource{Version: "v1", Resource: "services"}
+		virtualServicesResource = schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1alpha3", Resource: "virtualservices"}
+	)
+
+	existingChild := func(apiVersion, kind, name string) unstructured.Unstructured {
+		obj := unstructured.Unstructured{}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		obj.SetNamespace("cf-workloads")
+		obj.SetName(name)
+		return obj
+	}
+
+	BeforeEach(func() {
+		now = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		parent = webhook.BulkSync{
+			TypeMeta: metav1.TypeMeta{APIVersion: "apps.cloudfoundry.org/v1alpha1", Kind: "RouteBulkSync"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "route-bulk-sync",
+				Namespace:  "cf-workloads",
+				UID:        "parent-uid",
+				Generation: 3,
+			},
+		}
+
+		kubeClient = &fakes.KubeClient{}
+		kubeClient.ListParentsReturns([]webhook.BulkSync{parent}, nil)
+
+		syncer = &fakes.Syncer{}
+		syncer.SyncReturns(&webhook.SyncResponse{
+			Children: []webhook.K8sResource{
+				webhook.Service{
+					ApiVersion: "v1",
+					Kind:       "Service",
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "s-destination-0",
+						Labels: map[string]string{"cloudfoundry.org/route-bulk-sync": "true"},
+					},
+				},
+				webhook.VirtualService{
+					ApiVersion: "networking.istio.io/v1alpha3",
+					Kind:       "VirtualService",
+					ObjectMeta: metav1.ObjectMeta{Name: "vs-0"},
+				},
+			},
+		}, nil)
+
+		snapshotRepo = &fakes.SnapshotRepo{}
+
+		r = &reconciler.Reconciler{
+			KubeClient:     kubeClient,
+			Syncer:         syncer,
+			SnapshotRepo:   snapshotRepo,
+			ChildResources: []schema.GroupVersionResource{servicesResource, virtualServicesResource},
+			ResyncInterval: time.Hour,
+			Now:            func() time.Time { return now },
+		}
+	})
+
+	Describe("ReconcileOnce", func() {
+		It("generates the children for every route bulk sync", func() {
+			Expect(r.ReconcileOnce()).To(Succeed())
+
+			Expect(syncer.SyncCallCount()).To(Equal(1))
+			Expect(syncer.SyncArgsForCall(0)).To(Equal(webhook.SyncRequest{Parent: parent}))
+		})
+
+		It("applies the children in the namespace of the parent, owned by the parent", func() {
+			Expect(r.ReconcileOnce()).To(Succeed())
+
+			Expect(kubeClient.ApplyCallCount()).To(Equal(2))
+			service := kubeClient.ApplyArgsForCall(0)
+			Expect(service.GetKind()).To(Equal("Service"))
+			Expect(service.GetName()).To(Equal("s-destination-0"))
+			Expect(service.GetNamespace()).To(Equal("cf-workloads"))
+			Expect(service.GetLabels()).To(Equal(map[string]string{
+				"cloudfoundry.org/route-bulk-sync": "true",
+				reconciler.OwnerLabel:              "parent-uid",
+			}))
+
+			ownerReferences := service.GetOwnerReferences()
+			Expect(ownerReferences).To(HaveLen(1))
+			Expect(ownerReferences[0].APIVersion).To(Equal("apps.cloudfoundry.org/v1alpha1"))
+			Expect(ownerReferences[0].Kind).To(Equal("RouteBulkSync"))
+			Expect(ownerReferences[0].Name).To(Equal("route-bulk-sync"))
+			Expect(ownerReferences[0].UID).To(BeEquivalentTo("parent-uid"))
+			Expect(*ownerReferences[0].Controller).To(BeTrue())
+
+			virtualService := kubeClient.ApplyArgsForCall(1)
+			Expect(virtualService.GetAPIVersion()).To(Equal("networking.istio.io/v1alpha3"))
+			Expect(virtualService.GetName()).To(Equal("vs-0"))
+			Expect(virtualService.GetLabels()).To(HaveKeyWithValue(reconciler.OwnerLabel, "parent-uid"))
+		})
+
+		It("deletes children of the parent that are no longer generated", func() {
+			kubeClient.ListChildrenStub = func(resource schema.GroupVersionResource, namespace, selector string) ([]unstructured.Unstructured, error) {
+				if resource == servicesResource {
+					return []unstructured.Unstructured{
+						existingChild("v1", "Service", "s-destination-0"),
+						existingChild("v1", "Service", "s-old-destination"),
+					}, nil
+				}
+				return []unstructured.Unstructured{existingChild("networking.istio.io/v1alpha3", "VirtualService", "vs-0")}, nil
+			}
+
+			Expect(r.ReconcileOnce()).To(Succeed())
+
+			Expect(kubeClient.ListChildrenCallCount()).To(Equal(2))
+			resource, namespace, selector := kubeClient.ListChildrenArgsForCall(0)
+			Expect(resource).To(Equal(servicesResource))
+			Expect(namespace).To(Equal("cf-workloads"))
+			Expect(selector).To(Equal(reconciler.OwnerLabel + "=parent-uid"))
+
+			Expect(kubeClient.DeleteCallCount()).To(Equal(1))
+			resource, namespace, name := kubeClient.DeleteArgsForCall(0)
+			Expect(resource).To(Equal(servicesResource))
+			Expect(namespace).To(Equal("cf-workloads"))
+			Expect(name).To(Equal("s-old-destination"))
+		})
+
+		Context("when a resource the children may be of is not installed", func() {
+			It("garbage collects the other resources", func() {
+				kubeClient.ListChildrenStub = func(resource schema.GroupVersionResource, namespace, selector string) ([]unstructured.Unstructured, error) {
+					if resource == servicesResource {
+						return nil, &meta.NoResourceMatchError{PartialResource: resource}
+					}
+					return []unstructured.Unstructured{existingChild("networking.istio.io/v1alpha3", "VirtualService", "vs-old")}, nil
+				}
+
+				Expect(r.ReconcileOnce()).To(Succeed())
+
+				Expect(kubeClient.DeleteCallCount()).To(Equal(1))
+				_, _, name := kubeClient.DeleteArgsForCall(0)
+				Expect(name).To(Equal("vs-old"))
+			})
+
+			Context("and the API server does not know the resource", func() {
+				It("garbage collects the other resources", func() {
+					kubeClient.ListChildrenStub = func(resource schema.GroupVersionResource, namespace, selector string) ([]unstructured.Unstructured, error) {
+						if resource == servicesResource {
+							return nil, apierrors.NewNotFound(resource.GroupResource(), "")
+						}
+						return []unstructured.Unstructured{existingChild("networking.istio.io/v1alpha3", "VirtualService", "vs-old")}, nil
+					}
+
+					Expect(r.ReconcileOnce()).To(Succeed())
+
+					Expect(kubeClient.DeleteCallCount()).To(Equal(1))
+				})
+			})
+		})
+
+		Context("when listing the children fails", func() {
+			It("reports the error on the parent", func() {
+				kubeClient.ListChildrenReturns(nil, errors.New("potato"))
+
+				Expect(r.ReconcileOnce()).NotTo(Succeed())
+				_, status := kubeClient.UpdateStatusArgsForCall(0)
+				Expect(status.Error).To(Equal("list services: potato; list virtualservices: potato"))
+			})
+		})
+
+		It("reports the status on the parent", func() {
+			Expect(r.ReconcileOnce()).To(Succeed())
+
+			Expect(kubeClient.UpdateStatusCallCount()).To(Equal(1))
+			updatedParent, status := kubeClient.UpdateStatusArgsForCall(0)
+			Expect(updatedParent).To(Equal(parent))
+			Expect(status).To(Equal(webhook.BulkSyncStatus{
+				ObservedGeneration: 3,
+				LastSyncTime:       metav1.NewTime(now),
+			}))
+		})
+
+		It("reports the invalid annotations of the routes on the parent", func() {
+			syncer.SyncReturns(&webhook.SyncResponse{
+				Status: webhook.SyncStatus{InvalidAnnotations: []string{"some invalid annotation"}},
+			}, nil)
+
+			Expect(r.ReconcileOnce()).To(Succeed())
+
+			_, status := kubeClient.UpdateStatusArgsForCall(0)
+			Expect(status.InvalidAnnotations).To(Equal([]string{"some invalid annotation"}))
+		})
+
+		Context("when the children cannot be generated", func() {
+			BeforeEach(func() {
+				syncer.SyncReturns(nil, webhook.UninitializedError)
+			})
+
+			It("reports the error on the parent and does not delete anything", func() {
+				err := r.ReconcileOnce()
+				Expect(err).To(MatchError("failed to reconcile 1 of 1 route bulk syncs"))
+
+				Expect(kubeClient.ApplyCallCount()).To(Equal(0))
+				Expect(kubeClient.DeleteCallCount()).To(Equal(0))
+				_, status := kubeClient.UpdateStatusArgsForCall(0)
+				Expect(status.Error).To(ContainSubstring("uninitialized"))
+			})
+		})
+
+		Context("when applying a child fails", func() {
+			BeforeEach(func() {
+				kubeClient.ApplyStub = func(child *unstructured.Unstructured) error {
+					if child.GetKind() == "Service" {
+						return errors.New("potato")
+					}
+					return nil
+				}
+				kubeClient.ListChildrenStub = func(resource schema.GroupVersionResource, namespace, selector string) ([]unstructured.Unstructured, error) {
+					if resource == servicesResource {
+						return []unstructured.Unstructured{existingChild("v1", "Service", "s-old")}, nil
+					}
+					return []unstructured.Unstructured{existingChild("networking.istio.io/v1alpha3", "VirtualService", "vs-old")}, nil
+				}
+			})
+
+			It("reports the error on the parent", func() {
+				Expect(r.ReconcileOnce()).NotTo(Succeed())
+				_, status := kubeClient.UpdateStatusArgsForCall(0)
+				Expect(status.Error).To(Equal("apply Service s-destination-0: potato"))
+			})
+
+			It("still applies the other children", func() {
+				Expect(r.ReconcileOnce()).NotTo(Succeed())
+				Expect(kubeClient.ApplyCallCount()).To(Equal(2))
+				Expect(kubeClient.ApplyArgsForCall(1).GetName()).To(Equal("vs-0"))
+			})
+
+			It("garbage collects only the kinds that were applied", func() {
+				Expect(r.ReconcileOnce()).NotTo(Succeed())
+				Expect(kubeClient.DeleteCallCount()).To(Equal(1))
+				resource, _, name := kubeClient.DeleteArgsForCall(0)
+				Expect(resource).To(Equal(virtualServicesResource))
+				Expect(name).To(Equal("vs-old"))
+			})
+
+			Context("and garbage collection fails as well", func() {
+				BeforeEach(func() {
+					kubeClient.DeleteReturns(errors.New("banana"))
+				})
+
+				It("reports all the errors on the parent", func() {
+					Expect(r.ReconcileOnce()).NotTo(Succeed())
+					_, status := kubeClient.UpdateStatusArgsForCall(0)
+					Expect(status.Error).To(Equal("apply Service s-destination-0: potato; delete VirtualService vs-old: banana"))
+				})
+			})
+		})
+
+		Context("when listing the parents fails", func() {
+			BeforeEach(func() {
+				kubeClient.ListParentsReturns(nil, errors.New("potato"))
+			})
+
+			It("returns the error", func() {
+				Expect(r.ReconcileOnce()).To(MatchError("list route bulk syncs: potato"))
+			})
+		})
+	})
+
+	Describe("Run", func() {
+		It("reconciles whenever the snapshot changes, until the context is done", func() {
+			updates := make(chan *models.RouteSnapshot)
+			unsubscribed := make(chan struct{})
+			snapshotRepo.SubscribeReturns(updates, func() { close(unsubscribed) })
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				r.Run(ctx)
+				close(done)
+			}()
+
+			updates <- &models.RouteSnapshot{}
+			Eventually(kubeClient.ListParentsCallCount).Should(Equal(1))
+			updates <- &models.RouteSnapshot{}
+			Eventually(kubeClient.ListParentsCallCount).Should(Equal(2))
+
+			cancel()
+			Eventually(done).Should(BeClosed())
+			Expect(unsubscribed).To(BeClosed())
+		})
+
+		It("reconciles periodically", func() {
+			snapshotRepo.SubscribeReturns(make(chan *models.RouteSnapshot), func() {})
+			r.ResyncInterval = 10 * time.Millisecond
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go r.Run(ctx)
+
+			Eventually(kubeClient.ListParentsCallCount).Should(BeNumerically(">=", 2))
+		})
+	})
+})
@@ -0,0 +1,118 @@
+package routeservice
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// CheckPathPrefix is the path the ingress gateway sends its ext_authz checks to,
+	// followed by the path of the checked request
+	CheckPathPrefix = "/routeservice/check"
+
+	// VerifiedHeader tells the VirtualService whether a request came back from the route service
+	// of its route. The ingress gateway strips it from client requests before the check.
+	VerifiedHeader = "x-cf-route-service-verified"
+
+	// DefaultTimeout is how long a signature is accepted after it was made, like in gorouter
+	DefaultTimeout = time.Minute
+)
+
+// CheckHandler is the HTTP ext_authz service of the ingress gateway for route services.
+//
+// A request without a signature is on its way to a route service: it is allowed and gets
+// a fresh signature for its URL, which the route service sends back with the forwarded request.
+// A request with a signature is allowed as verified when the signature opens with the key,
+// is younger than Timeout and was made for the host, path and query of the request. Forged and
+// expired signatures, and signatures for other paths of the host, are denied, so that they cannot
+// be replayed to skip the route service.
+type CheckHandler struct {
+	Signer *Signer
+
+	// How long a signature is accepted after it was made, defaults to DefaultTimeout
+	Timeout time.Duration
+
+	Now func() time.Time
+}
+
+func (h *CheckHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	proto := req.Header.Get("X-Forwarded-Proto")
+	if proto == "" {
+		proto = "http"
+	}
+	requestURI := strings.TrimPrefix(req.URL.RequestURI(), CheckPathPrefix)
+	if requestURI == "" {
+		requestURI = "/"
+	}
+	forwardedUrl := proto + "://" + req.Host + requestURI
+
+	signature := req.Header.Get(SignatureHeader)
+	if signature == "" {
+		h.sign(rw, forwardedUrl)
+		return
+	}
+
+	opened, err := h.Signer.Open(signature, req.Header.Get(MetadataHeader))
+	if err != nil {
+		log.WithError(err).Warnf("denying request to %s with an invalid route service signature", req.Host)
+		rw.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	age := h.Now().Sub(opened.RequestedTime)
+	if age < 0 || age > h.timeout() {
+		log.Warnf("denying request to %s with a route service signature of %s", req.Host, opened.RequestedTime)
+		rw.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if opened.ForwardedUrl != req.Header.Get(ForwardedUrlHeader) {
+		log.Warnf("denying request to %s with a route service signature for another url", req.Host)
+		rw.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	signedUrl, err := url.Parse(opened.ForwardedUrl)
+	if err != nil || signedUrl.Host != req.Host {
+		// e.g. the request of a route to a route service that is itself a route,
+		// its signature is for the route service to send back and is left as it is
+		rw.Header().Set(VerifiedHeader, "false")
+		rw.WriteHeader(http.StatusOK)
+		return
+	}
+	// the scheme is not compared, the route service may send the request back over another one
+	if signedUrl.RequestURI() != requestURI {
+		log.Warnf("denying request to %s with a route service signature for another path", req.Host)
+		rw.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	rw.Header().Set(VerifiedHeader, "true")
+	rw.WriteHeader(http.StatusOK)
+}
+
+func (h *CheckHandler) sign(rw http.ResponseWriter, forwardedUrl string) {
+	signature, metadata, err := h.Signer.Sign(forwardedUrl, h.Now())
+	if err != nil {
+		log.WithError(err).Error("signing route service request")
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set(VerifiedHeader, "false")
+	rw.Header().Set(ForwardedUrlHeader, forwardedUrl)
+	rw.Header().Set(SignatureHeader, signature)
+	rw.Header().Set(MetadataHeader, metadata)
+	rw.WriteHeader(http.StatusOK)
+}
+
+func (h *CheckHandler) timeout() time.Duration {
+	if h.Timeout == 0 {
+		return DefaultTimeout
+	}
+	return h.Timeout
+}
@@ -0,0 +1,201 @@
+package routeservice_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/routeservice"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CheckHandler", func() {
+	var (
+		handler *routeservice.CheckHandler
+		signer  *routeservice.Signer
+		now     time.Time
+		resp    *httptest.ResponseRecorder
+		request *http.Request
+	)
+
+	BeforeEach(func() {
+		now = time.Date(2020, 3, 4, 5, 6, 7, 0, time.UTC)
+		signer = &routeservice.Signer{Key: routeservice.Key("some-secret")}
+		handler = &routeservice.CheckHandler{
+			Signer: signer,
+			Now:    func() time.Time { return now },
+		}
+		resp = httptest.NewRecorder()
+		request = httptest.NewRequest("GET", "/routeservice/check/some/path?some=query", nil)
+		request.Host = "app.example.com"
+		request.Header.Set("X-Forwarded-Proto", "https")
+	})
+
+	signedRequest := func(forwardedUrl string, requestedTime time.Time) {
+		signature, metadata, err := signer.Sign(forwardedUrl, requestedTime)
+		Expect(err).NotTo(HaveOccurred())
+		request.Header.Set(routeservice.ForwardedUrlHeader, forwardedUrl)
+		request.Header.Set(routeservice.SignatureHeader, signature)
+		request.Header.Set(routeservice.MetadataHeader, metadata)
+	}
+
+	Context("when the request has no signature", func() {
+		It("allows it unverified with a fresh signature for the url of the request", func() {
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(resp.Header().Get(routeservice.VerifiedHeader)).To(Equal("false"))
+			Expect(resp.Header().Get(routeservice.ForwardedUrlHeader)).To(Equal("https://app.example.com/some/path?some=query"))
+
+			opened, err := signer.Open(resp.Header().Get(routeservice.SignatureHeader), resp.Header().Get(routeservice.MetadataHeader))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opened.ForwardedUrl).To(Equal("https://app.example.com/some/path?some=query"))
+			Expect(opened.RequestedTime).To(BeTemporally("==", now))
+		})
+	})
+
+	Context("when the route service sends the request back with its signature", func() {
+		It("allows it as verified", func() {
+			signedRequest("https://app.example.com/some/path?some=query", now.Add(-30*time.Second))
+
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(resp.Header().Get(routeservice.VerifiedHeader)).To(Equal("true"))
+			Expect(resp.Header().Get(routeservice.SignatureHeader)).To(BeEmpty())
+		})
+	})
+
+	Context("when the signature is for another host", func() {
+		It("allows it unverified and leaves the signature alone", func() {
+			signedRequest("https://other-app.example.com/some/path", now)
+
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(resp.Header().Get(routeservice.VerifiedHeader)).To(Equal("false"))
+			Expect(resp.Header().Get(routeservice.SignatureHeader)).To(BeEmpty())
+		})
+	})
+
+	Context("when the signature is replayed after the timeout", func() {
+		It("denies it", func() {
+			signedRequest("https://app.example.com/some/path?some=query", now.Add(-61*time.Second))
+
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusForbidden))
+			Expect(resp.Header().Get(routeservice.VerifiedHeader)).To(BeEmpty())
+		})
+
+		Context("and the timeout is configured", func() {
+			It("uses it", func() {
+				handler.Timeout = 2 * time.Minute
+				signedRequest("https://app.example.com/some/path?some=query", now.Add(-61*time.Second))
+
+				handler.ServeHTTP(resp, request)
+
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Header().Get(routeservice.VerifiedHeader)).To(Equal("true"))
+			})
+		})
+	})
+
+	Context("when the signature is made in the future", func() {
+		It("denies it", func() {
+			signedRequest("https://app.example.com/some/path?some=query", now.Add(time.Minute))
+
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusForbidden))
+		})
+	})
+
+	Context("when the signature is forged", func() {
+		It("denies it", func() {
+			forger := &routeservice.Signer{Key: routeservice.Key("potato")}
+			signature, metadata, err := forger.Sign("https://app.example.com/some/path?some=query", now)
+			Expect(err).NotTo(HaveOccurred())
+			request.Header.Set(routeservice.ForwardedUrlHeader, "https://app.example.com/some/path?some=query")
+			request.Header.Set(routeservice.SignatureHeader, signature)
+			request.Header.Set(routeservice.MetadataHeader, metadata)
+
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusForbidden))
+			Expect(resp.Header().Get(routeservice.VerifiedHeader)).To(BeEmpty())
+		})
+	})
+
+	Context("when the signature is garbage", func() {
+		It("denies it", func() {
+			request.Header.Set(routeservice.SignatureHeader, "potato")
+			request.Header.Set(routeservice.MetadataHeader, "potato")
+
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusForbidden))
+		})
+	})
+
+	Context("when the signature is replayed on another path of the host", func() {
+		It("denies it", func() {
+			signedRequest("https://app.example.com/other/path", now)
+
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusForbidden))
+			Expect(resp.Header().Get(routeservice.VerifiedHeader)).To(BeEmpty())
+		})
+	})
+
+	Context("when the signature is replayed with another query", func() {
+		It("denies it", func() {
+			signedRequest("https://app.example.com/some/path?some=other-query", now)
+
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusForbidden))
+		})
+	})
+
+	Context("when the route service sends the request back over another scheme", func() {
+		It("allows it as verified", func() {
+			signedRequest("http://app.example.com/some/path?some=query", now)
+
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(resp.Header().Get(routeservice.VerifiedHeader)).To(Equal("true"))
+		})
+	})
+
+	Context("when the request is for the root path", func() {
+		It("signs it and allows it as verified when it comes back", func() {
+			request = httptest.NewRequest("GET", "/routeservice/check", nil)
+			request.Host = "app.example.com"
+			handler.ServeHTTP(resp, request)
+			Expect(resp.Header().Get(routeservice.ForwardedUrlHeader)).To(Equal("http://app.example.com/"))
+
+			signedRequest("http://app.example.com/", now)
+			resp = httptest.NewRecorder()
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(resp.Header().Get(routeservice.VerifiedHeader)).To(Equal("true"))
+		})
+	})
+
+	Context("when the forwarded url header does not match the signature", func() {
+		It("denies it", func() {
+			signedRequest("https://app.example.com/some/path?some=query", now)
+			request.Header.Set(routeservice.ForwardedUrlHeader, "https://app.example.com/other/path")
+
+			handler.ServeHTTP(resp, request)
+
+			Expect(resp.Code).To(Equal(http.StatusForbidden))
+		})
+	})
+})
@@ -0,0 +1,105 @@
+package routeservice
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Headers gorouter sends to route services, and expects back on the forwarded request
+const (
+	ForwardedUrlHeader = "X-CF-Forwarded-Url"
+	SignatureHeader    = "X-CF-Proxy-Signature"
+	MetadataHeader     = "X-CF-Proxy-Metadata"
+)
+
+// Key derives the AES key from the route service secret the same way gorouter does
+func Key(secret string) []byte {
+	return pbkdf2.Key([]byte(secret), nil, 100000, 16, sha256.New)
+}
+
+// Signature is the content of the X-CF-Proxy-Signature header before it is encrypted
+type Signature struct {
+	ForwardedUrl  string    `json:"forwarded_url"`
+	RequestedTime time.Time `json:"requested_time"`
+}
+
+// Metadata is the content of the X-CF-Proxy-Metadata header
+type Metadata struct {
+	Nonce []byte `json:"nonce"`
+}
+
+// Signer builds and opens the X-CF-Proxy-Signature and X-CF-Proxy-Metadata headers
+// the same way gorouter does, with a random nonce and the time of the request
+type Signer struct {
+	// AES key, see Key
+	Key []byte
+}
+
+// Sign returns the signature and metadata headers for the forwarded url requested at requestedTime
+func (s *Signer) Sign(forwardedUrl string, requestedTime time.Time) (signature string, metadata string, err error) {
+	gcm, err := s.gcm()
+	if err != nil {
+		return "", "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", "", fmt.Errorf("generating nonce: %w", err)
+	}
+
+	// marshaling plain structs cannot fail
+	plainText, _ := json.Marshal(Signature{ForwardedUrl: forwardedUrl, RequestedTime: requestedTime})
+
+	cipherText := gcm.Seal(nil, nonce, plainText, []byte{})
+	metadataJSON, _ := json.Marshal(Metadata{Nonce: nonce})
+
+	return base64.URLEncoding.EncodeToString(cipherText), base64.URLEncoding.EncodeToString(metadataJSON), nil
+}
+
+// Open decrypts the signature with the nonce of the metadata
+func (s *Signer) Open(signature string, metadata string) (Signature, error) {
+	gcm, err := s.gcm()
+	if err != nil {
+		return Signature{}, err
+	}
+
+	metadataJSON, err := base64.URLEncoding.DecodeString(metadata)
+	if err != nil {
+		return Signature{}, fmt.Errorf("decoding metadata: %w", err)
+	}
+	var m Metadata
+	if err := json.Unmarshal(metadataJSON, &m); err != nil {
+		return Signature{}, fmt.Errorf("unmarshal metadata: %w", err)
+	}
+
+	cipherText, err := base64.URLEncoding.DecodeString(signature)
+	if err != nil {
+		return Signature{}, fmt.Errorf("decoding signature: %w", err)
+	}
+	plainText, err := gcm.Open(nil, m.Nonce, cipherText, []byte{})
+	if err != nil {
+		return Signature{}, fmt.Errorf("decrypting signature: %w", err)
+	}
+
+	var sig Signature
+	if err := json.Unmarshal(plainText, &sig); err != nil {
+		return Signature{}, fmt.Errorf("unmarshal signature: %w", err)
+	}
+	return sig, nil
+}
+
+func (s *Signer) gcm() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(s.Key)
+	if err != nil {
+		return nil, fmt.Errorf("route service key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,60 @@
+package routeservice_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/routeservice"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Signer", func() {
+	var signer *routeservice.Signer
+
+	BeforeEach(func() {
+		signer = &routeservice.Signer{Key: routeservice.Key("some-secret")}
+	})
+
+	It("encrypts the forwarded url and the requested time so that they can be opened with the same key", func() {
+		requestedTime := time.Date(2020, 3, 4, 5, 6, 7, 0, time.UTC)
+		signature, metadata, err := signer.Sign("https://app.example.com/path", requestedTime)
+		Expect(err).NotTo(HaveOccurred())
+
+		opened, err := signer.Open(signature, metadata)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opened.ForwardedUrl).To(Equal("https://app.example.com/path"))
+		Expect(opened.RequestedTime).To(BeTemporally("==", requestedTime))
+	})
+
+	It("uses a random nonce for every signature", func() {
+		requestedTime := time.Now()
+		signature0, metadata0, err := signer.Sign("https://app.example.com/path", requestedTime)
+		Expect(err).NotTo(HaveOccurred())
+		signature1, metadata1, err := signer.Sign("https://app.example.com/path", requestedTime)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(metadata1).NotTo(Equal(metadata0))
+		Expect(signature1).NotTo(Equal(signature0))
+	})
+
+	Context("when the signature was made with another key", func() {
+		It("fails to open it", func() {
+			signature, metadata, err := signer.Sign("https://app.example.com/path", time.Now())
+			Expect(err).NotTo(HaveOccurred())
+
+			other := &routeservice.Signer{Key: routeservice.Key("other-secret")}
+			_, err = other.Open(signature, metadata)
+			Expect(err).To(MatchError(ContainSubstring("decrypting signature")))
+		})
+	})
+
+	Context("when the key has an invalid length", func() {
+		It("returns an error", func() {
+			signer.Key = []byte("potato")
+
+			_, _, err := signer.Sign("https://app.example.com/path", time.Now())
+			Expect(err).To(MatchError(ContainSubstring("route service key")))
+		})
+	})
+})
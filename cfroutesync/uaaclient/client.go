@@ -1,10 +1,12 @@
 package uaaclient
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 )
 
 type Client struct {
@@ -19,13 +21,30 @@ type jsonClient interface {
 	MakeRequest(*http.Request, interface{}) error
 }
 
-func (c *Client) GetToken() (string, error) {
+// Token is an access token as issued by UAA
+type Token struct {
+	AccessToken string
+
+	// Lifetime of the token as reported by UAA at the time it was issued, zero if unknown
+	ExpiresIn time.Duration
+}
+
+func (c *Client) GetToken(ctx context.Context) (string, error) {
+	token, err := c.FetchToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+// FetchToken requests a new token from UAA using the client_credentials grant
+func (c *Client) FetchToken(ctx context.Context) (Token, error) {
 	reqURL := fmt.Sprintf("%s/oauth/token", c.BaseURL)
 	bodyString := fmt.Sprintf("grant_type=client_credentials")
 
-	request, err := http.NewRequest("POST", reqURL, strings.NewReader(bodyString))
+	request, err := http.NewRequestWithContext(ctx, "POST", reqURL, strings.NewReader(bodyString))
 	if err != nil {
-		return "", err
+		return Token{}, err
 	}
 
 	request.SetBasicAuth(url.QueryEscape(c.Name), url.QueryEscape(c.Secret))
@@ -33,11 +52,15 @@ func (c *Client) GetToken() (string, error) {
 
 	type getTokenResponse struct {
 		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
 	}
 	response := &getTokenResponse{}
 	err = c.JSONClient.MakeRequest(request, response)
 	if err != nil {
-		return "", err
+		return Token{}, err
 	}
-	return response.AccessToken, nil
+	return Token{
+		AccessToken: response.AccessToken,
+		ExpiresIn:   time.Duration(response.ExpiresIn) * time.Second,
+	}, nil
 }
@@ -1,10 +1,12 @@
 package uaaclient_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"io/ioutil"
 	"net/http"
+	"time"
 
 	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/uaaclient"
 
@@ -29,7 +31,7 @@ var _ = Describe("Client", func() {
 				JSONClient: jsonClient,
 			}
 
-			body := `{ "access_token" : "valid-token" }`
+			body := `{ "access_token" : "valid-token", "expires_in": 43199 }`
 
 			jsonClient.MakeRequestStub = func(req *http.Request, responseStruct interface{}) error {
 				return json.Unmarshal([]byte(body), responseStruct)
@@ -37,13 +39,31 @@ var _ = Describe("Client", func() {
 		})
 
 		It("Returns the token", func() {
-			token, err := client.GetToken()
+			token, err := client.GetToken(context.Background())
 			Expect(err).NotTo(HaveOccurred())
 			Expect(token).To(Equal("valid-token"))
 		})
 
+		It("returns the lifetime of the token when fetching the full token", func() {
+			token, err := client.FetchToken(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal(uaaclient.Token{
+				AccessToken: "valid-token",
+				ExpiresIn:   43199 * time.Second,
+			}))
+		})
+
+		It("makes the request with the provided context", func() {
+			type key struct{}
+			ctx := context.WithValue(context.Background(), key{}, "some-value")
+			_, err := client.GetToken(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			receivedRequest, _ := jsonClient.MakeRequestArgsForCall(0)
+			Expect(receivedRequest.Context().Value(key{})).To(Equal("some-value"))
+		})
+
 		It("forms the required request", func() {
-			_, err := client.GetToken()
+			_, err := client.GetToken(context.Background())
 			Expect(err).NotTo(HaveOccurred())
 			Expect(jsonClient.MakeRequestCallCount()).To(Equal(1))
 			receivedRequest, _ := jsonClient.MakeRequestArgsForCall(0)
@@ -69,7 +89,7 @@ var _ = Describe("Client", func() {
 					JSONClient: jsonClient,
 				}
 
-				_, err := client.GetToken()
+				_, err := client.GetToken(context.Background())
 				Expect(err).NotTo(HaveOccurred())
 				Expect(jsonClient.MakeRequestCallCount()).To(Equal(1))
 				receivedRequest, _ := jsonClient.MakeRequestArgsForCall(0)
@@ -90,7 +110,7 @@ var _ = Describe("Client", func() {
 			})
 
 			It("returns a helpful error", func() {
-				_, err := client.GetToken()
+				_, err := client.GetToken(context.Background())
 				Expect(err).To(MatchError(ContainSubstring("potato")))
 			})
 		})
@@ -101,7 +121,7 @@ var _ = Describe("Client", func() {
 			})
 
 			It("returns a helpful error", func() {
-				_, err := client.GetToken()
+				_, err := client.GetToken(context.Background())
 				Expect(err).To(MatchError(ContainSubstring("invalid URL escape")))
 			})
 		})
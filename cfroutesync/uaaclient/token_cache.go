@@ -0,0 +1,115 @@
+package uaaclient
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:generate counterfeiter -o fakes/token_fetcher.go --fake-name TokenFetcher . tokenFetcher
+type tokenFetcher interface {
+	FetchToken(ctx context.Context) (Token, error)
+}
+
+// TokenCache hands out the same UAA token until shortly before it expires,
+// so that callers polling Cloud Controller do not request a new token every time
+type TokenCache struct {
+	Client tokenFetcher
+
+	// How long before its expiry a cached token is proactively replaced, at most half of the
+	// lifetime of the token so that short-lived tokens are still reused
+	RefreshMargin time.Duration
+
+	Now func() time.Time
+
+	mutex       sync.Mutex
+	accessToken string
+	refreshAt   time.Time
+}
+
+// GetToken returns the cached token, fetching a new one from UAA if there is none
+// or if the cached one is about to expire
+func (c *TokenCache) GetToken(ctx context.Context) (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := c.Now()
+	if c.accessToken != "" && now.Before(c.refreshAt) {
+		return c.accessToken, nil
+	}
+
+	token, err := c.Client.FetchToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	c.accessToken = token.AccessToken
+	c.refreshAt = c.refreshTime(expiryOf(token, now), now)
+	return c.accessToken, nil
+}
+
+// InvalidateToken discards the cached token if it is the given one, e.g. because
+// Cloud Controller rejected it. The next call to GetToken fetches a new token.
+func (c *TokenCache) InvalidateToken(token string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.accessToken == token {
+		c.accessToken = ""
+		c.refreshAt = time.Time{}
+	}
+}
+
+// refreshTime returns when a token fetched at issuedAt is replaced, RefreshMargin before it expires.
+// The margin is clamped to half of the lifetime, a margin as long as the lifetime would fetch a token on every call.
+func (c *TokenCache) refreshTime(expiresAt time.Time, issuedAt time.Time) time.Time {
+	margin := c.RefreshMargin
+	if lifetime := expiresAt.Sub(issuedAt); margin > lifetime/2 {
+		margin = lifetime / 2
+	}
+	return expiresAt.Add(-margin)
+}
+
+// expiryOf returns the earlier of the expiry reported by UAA and the exp claim of the JWT.
+// Tokens without either are treated as already expired so that they are never reused.
+func expiryOf(token Token, issuedAt time.Time) time.Time {
+	var expiresAt time.Time
+	if token.ExpiresIn > 0 {
+		expiresAt = issuedAt.Add(token.ExpiresIn)
+	}
+
+	if exp, ok := jwtExpiry(token.AccessToken); ok {
+		if expiresAt.IsZero() || exp.Before(expiresAt) {
+			expiresAt = exp
+		}
+	}
+
+	if expiresAt.IsZero() {
+		return issuedAt
+	}
+	return expiresAt
+}
+
+// jwtExpiry reads the exp claim from the payload of a JWT without verifying its signature
+func jwtExpiry(accessToken string) (time.Time, bool) {
+	parts := strings.Split(accessToken, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Exp, 0), true
+}
@@ -0,0 +1,165 @@
+package uaaclient_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/uaaclient"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/uaaclient/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TokenCache", func() {
+	var (
+		cache        *uaaclient.TokenCache
+		tokenFetcher *fakes.TokenFetcher
+		now          time.Time
+		ctx          context.Context
+	)
+
+	BeforeEach(func() {
+		now = time.Unix(1500000000, 0)
+		ctx = context.Background()
+		tokenFetcher = &fakes.TokenFetcher{}
+		tokenFetcher.FetchTokenReturnsOnCall(0, uaaclient.Token{AccessToken: "token-1", ExpiresIn: 10 * time.Minute}, nil)
+		tokenFetcher.FetchTokenReturnsOnCall(1, uaaclient.Token{AccessToken: "token-2", ExpiresIn: 10 * time.Minute}, nil)
+
+		cache = &uaaclient.TokenCache{
+			Client:        tokenFetcher,
+			RefreshMargin: time.Minute,
+			Now:           func() time.Time { return now },
+		}
+	})
+
+	It("fetches a token the first time", func() {
+		token, err := cache.GetToken(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(token).To(Equal("token-1"))
+		Expect(tokenFetcher.FetchTokenCallCount()).To(Equal(1))
+	})
+
+	It("fetches the token with the provided context", func() {
+		type key struct{}
+		ctx = context.WithValue(ctx, key{}, "some-value")
+		cache.GetToken(ctx)
+		Expect(tokenFetcher.FetchTokenArgsForCall(0).Value(key{})).To(Equal("some-value"))
+	})
+
+	It("reuses the token while it is valid", func() {
+		cache.GetToken(ctx)
+		now = now.Add(8 * time.Minute)
+
+		token, err := cache.GetToken(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(token).To(Equal("token-1"))
+		Expect(tokenFetcher.FetchTokenCallCount()).To(Equal(1))
+	})
+
+	It("fetches a new token once the old one is within the refresh margin of expiring", func() {
+		cache.GetToken(ctx)
+		now = now.Add(9 * time.Minute)
+
+		token, err := cache.GetToken(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(token).To(Equal("token-2"))
+		Expect(tokenFetcher.FetchTokenCallCount()).To(Equal(2))
+	})
+
+	Context("when the refresh margin is at least the lifetime of the token", func() {
+		BeforeEach(func() {
+			cache.RefreshMargin = 10 * time.Minute
+		})
+
+		It("reuses the token for the first half of its lifetime", func() {
+			cache.GetToken(ctx)
+			now = now.Add(5*time.Minute - time.Second)
+
+			token, err := cache.GetToken(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal("token-1"))
+			Expect(tokenFetcher.FetchTokenCallCount()).To(Equal(1))
+
+			now = now.Add(time.Second)
+			token, err = cache.GetToken(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal("token-2"))
+		})
+	})
+
+	Context("when the token is a JWT that expires before expires_in says", func() {
+		BeforeEach(func() {
+			jwt := fakeJWT(fmt.Sprintf(`{"exp": %d}`, now.Add(3*time.Minute).Unix()))
+			tokenFetcher.FetchTokenReturnsOnCall(0, uaaclient.Token{AccessToken: jwt, ExpiresIn: 10 * time.Minute}, nil)
+		})
+
+		It("uses the exp claim", func() {
+			cache.GetToken(ctx)
+			now = now.Add(time.Minute)
+			cache.GetToken(ctx)
+			Expect(tokenFetcher.FetchTokenCallCount()).To(Equal(1))
+
+			now = now.Add(time.Minute)
+			token, err := cache.GetToken(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal("token-2"))
+		})
+	})
+
+	Context("when UAA does not say when the token expires", func() {
+		BeforeEach(func() {
+			tokenFetcher.FetchTokenReturnsOnCall(0, uaaclient.Token{AccessToken: "token-1"}, nil)
+		})
+
+		It("does not reuse the token", func() {
+			cache.GetToken(ctx)
+			token, err := cache.GetToken(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal("token-2"))
+		})
+	})
+
+	Describe("InvalidateToken", func() {
+		It("forces a new token to be fetched", func() {
+			cache.GetToken(ctx)
+			cache.InvalidateToken("token-1")
+
+			token, err := cache.GetToken(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal("token-2"))
+		})
+
+		It("ignores tokens that are no longer cached", func() {
+			cache.GetToken(ctx)
+			cache.InvalidateToken("some-older-token")
+
+			token, err := cache.GetToken(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal("token-1"))
+		})
+	})
+
+	Context("when fetching the token fails", func() {
+		BeforeEach(func() {
+			tokenFetcher.FetchTokenReturnsOnCall(0, uaaclient.Token{}, errors.New("potato"))
+		})
+
+		It("returns the error and does not cache anything", func() {
+			_, err := cache.GetToken(ctx)
+			Expect(err).To(MatchError("potato"))
+
+			token, err := cache.GetToken(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal("token-2"))
+		})
+	})
+})
+
+func fakeJWT(claims string) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	return fmt.Sprintf("%s.%s.%s", encode([]byte(`{"alg":"RS256"}`)), encode([]byte(claims)), encode([]byte("signature")))
+}
@@ -0,0 +1,81 @@
+package webhook
+
+import (
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Cookie that gorouter uses for sticky sessions
+const DefaultSessionCookieName = "JSESSIONID"
+
+// DestinationRuleBuilder builds a DestinationRule for the Service of every route destination.
+// Routes with a load balancing option use that algorithm. Other HTTP routes get sticky sessions
+// like with gorouter, by hashing on the session cookie, which Istio cannot combine with a simple algorithm.
+// Istio requires a ttl for cookie hashing, so Envoy sets a session cookie itself on requests that come without one.
+// Stickiness applies to the instances of a destination, picking between weighted destinations stays random.
+// The DestinationRules keep the mutual TLS of the mesh, which a DestinationRule without tls settings would turn off.
+type DestinationRuleBuilder struct {
+	// Cookie to hash on for sticky sessions, defaults to DefaultSessionCookieName
+	SessionCookieName string
+}
+
+func (b *DestinationRuleBuilder) Build(routes []models.Route, template Template) []K8sResource {
+	resources := []K8sResource{}
+	for _, route := range routes {
+		loadBalancer, ok := b.loadBalancerSettings(route)
+		if !ok {
+			continue
+		}
+
+		for _, dest := range route.Destinations {
+			resources = append(resources, DestinationRule{
+				ApiVersion: "networking.istio.io/v1alpha3",
+				Kind:       "DestinationRule",
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   template.serviceName(dest),
+					Labels: cloneLabels(template.ObjectMeta.Labels),
+					Annotations: map[string]string{
+						"cloudfoundry.org/route": route.Guid,
+					},
+				},
+				Spec: DestinationRuleSpec{
+					Host: template.serviceName(dest),
+					TrafficPolicy: TrafficPolicy{
+						LoadBalancer: loadBalancer,
+						Tls:          &ClientTLSSettings{Mode: "ISTIO_MUTUAL"},
+					},
+				},
+			})
+		}
+	}
+	return resources
+}
+
+func (b *DestinationRuleBuilder) loadBalancerSettings(route models.Route) (*LoadBalancerSettings, bool) {
+	switch route.Options.LoadBalancing {
+	case models.LoadBalancingRoundRobin:
+		return &LoadBalancerSettings{Simple: "ROUND_ROBIN"}, true
+	case models.LoadBalancingLeastConnection:
+		return &LoadBalancerSettings{Simple: "LEAST_CONN"}, true
+	case "":
+	default:
+		log.Errorf("ignoring unknown load balancing algorithm '%s' of route %s", route.Options.LoadBalancing, route.Guid)
+	}
+
+	if route.IsTCP() {
+		return nil, false
+	}
+
+	cookieName := b.SessionCookieName
+	if cookieName == "" {
+		cookieName = DefaultSessionCookieName
+	}
+	return &LoadBalancerSettings{
+		ConsistentHash: &ConsistentHashLB{
+			// a ttl of 0s only lasts for the browser session, like the cookies of gorouter
+			HttpCookie: &HTTPCookie{Name: cookieName, Path: "/", Ttl: "0s"},
+		},
+	}, true
+}
@@ -0,0 +1,160 @@
+package webhook_test
+
+import (
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/webhook"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("DestinationRuleBuilder", func() {
+	var (
+		template webhook.Template
+		builder  webhook.DestinationRuleBuilder
+	)
+
+	route := func(guid string, loadBalancing string, destinationGuids ...string) models.Route {
+		r := models.Route{
+			Guid:     guid,
+			Host:     "test0",
+			Protocol: models.ProtocolHTTP,
+			Domain:   models.Domain{Guid: "domain-0-guid", Name: "domain0.example.com"},
+			Options:  models.RouteOptions{LoadBalancing: loadBalancing},
+		}
+		for _, destinationGuid := range destinationGuids {
+			r.Destinations = append(r.Destinations, models.Destination{
+				Guid: destinationGuid,
+				App:  models.App{Guid: "app-" + destinationGuid, Process: models.Process{Type: "web"}},
+				Port: 8080,
+			})
+		}
+		return r
+	}
+
+	loadBalancerOf := func(resource webhook.K8sResource) webhook.LoadBalancerSettings {
+		return *resource.(webhook.DestinationRule).Spec.TrafficPolicy.LoadBalancer
+	}
+
+	BeforeEach(func() {
+		template = webhook.Template{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{"cloudfoundry.org/bulk-sync-route": "true"},
+			},
+		}
+		builder = webhook.DestinationRuleBuilder{}
+	})
+
+	It("returns a DestinationRule with JSESSIONID cookie affinity for each destination of an http route", func() {
+		routes := []models.Route{route("route-guid-0", "", "destination-guid-0", "destination-guid-1")}
+
+		Expect(builder.Build(routes, template)).To(Equal([]webhook.K8sResource{
+			webhook.DestinationRule{
+				ApiVersion: "networking.istio.io/v1alpha3",
+				Kind:       "DestinationRule",
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "s-destination-guid-0",
+					Labels:      map[string]string{"cloudfoundry.org/bulk-sync-route": "true"},
+					Annotations: map[string]string{"cloudfoundry.org/route": "route-guid-0"},
+				},
+				Spec: webhook.DestinationRuleSpec{
+					Host: "s-destination-guid-0",
+					TrafficPolicy: webhook.TrafficPolicy{
+						LoadBalancer: &webhook.LoadBalancerSettings{
+							ConsistentHash: &webhook.ConsistentHashLB{
+								HttpCookie: &webhook.HTTPCookie{Name: "JSESSIONID", Path: "/", Ttl: "0s"},
+							},
+						},
+						Tls: &webhook.ClientTLSSettings{Mode: "ISTIO_MUTUAL"},
+					},
+				},
+			},
+			webhook.DestinationRule{
+				ApiVersion: "networking.istio.io/v1alpha3",
+				Kind:       "DestinationRule",
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "s-destination-guid-1",
+					Labels:      map[string]string{"cloudfoundry.org/bulk-sync-route": "true"},
+					Annotations: map[string]string{"cloudfoundry.org/route": "route-guid-0"},
+				},
+				Spec: webhook.DestinationRuleSpec{
+					Host: "s-destination-guid-1",
+					TrafficPolicy: webhook.TrafficPolicy{
+						LoadBalancer: &webhook.LoadBalancerSettings{
+							ConsistentHash: &webhook.ConsistentHashLB{
+								HttpCookie: &webhook.HTTPCookie{Name: "JSESSIONID", Path: "/", Ttl: "0s"},
+							},
+						},
+						Tls: &webhook.ClientTLSSettings{Mode: "ISTIO_MUTUAL"},
+					},
+				},
+			},
+		}))
+	})
+
+	Context("when the parent selects only some of the routes", func() {
+		It("suffixes the name and the host with the parent name, like the name of the Service", func() {
+			template.ParentName = "some-parent"
+			routes := []models.Route{route("route-guid-0", "", "destination-guid-0")}
+
+			rule := builder.Build(routes, template)[0].(webhook.DestinationRule)
+
+			Expect(rule.Name).To(Equal("s-destination-guid-0-some-parent"))
+			Expect(rule.Spec.Host).To(Equal("s-destination-guid-0-some-parent"))
+		})
+	})
+
+	It("uses the configured session cookie name", func() {
+		builder.SessionCookieName = "SESSION"
+
+		resources := builder.Build([]models.Route{route("route-guid-0", "", "destination-guid-0")}, template)
+
+		Expect(loadBalancerOf(resources[0]).ConsistentHash.HttpCookie.Name).To(Equal("SESSION"))
+	})
+
+	Context("when the route has a load balancing option", func() {
+		It("uses the matching simple algorithm", func() {
+			routes := []models.Route{
+				route("route-guid-0", models.LoadBalancingRoundRobin, "destination-guid-0"),
+				route("route-guid-1", models.LoadBalancingLeastConnection, "destination-guid-1"),
+			}
+
+			resources := builder.Build(routes, template)
+
+			Expect(resources).To(HaveLen(2))
+			Expect(loadBalancerOf(resources[0])).To(Equal(webhook.LoadBalancerSettings{Simple: "ROUND_ROBIN"}))
+			Expect(loadBalancerOf(resources[1])).To(Equal(webhook.LoadBalancerSettings{Simple: "LEAST_CONN"}))
+			Expect(resources[1].(webhook.DestinationRule).Spec.TrafficPolicy.Tls).To(Equal(&webhook.ClientTLSSettings{Mode: "ISTIO_MUTUAL"}))
+		})
+	})
+
+	Context("when the load balancing option is unknown", func() {
+		It("falls back to cookie affinity", func() {
+			resources := builder.Build([]models.Route{route("route-guid-0", "potato", "destination-guid-0")}, template)
+
+			Expect(loadBalancerOf(resources[0]).ConsistentHash).NotTo(BeNil())
+		})
+	})
+
+	Context("when the route is a tcp route", func() {
+		It("only creates DestinationRules when a load balancing option is set", func() {
+			tcpRoute := route("route-guid-0", "", "destination-guid-0")
+			tcpRoute.Protocol = models.ProtocolTCP
+			tcpRouteWithOption := route("route-guid-1", models.LoadBalancingLeastConnection, "destination-guid-1")
+			tcpRouteWithOption.Protocol = models.ProtocolTCP
+
+			resources := builder.Build([]models.Route{tcpRoute, tcpRouteWithOption}, template)
+
+			Expect(resources).To(HaveLen(1))
+			Expect(resources[0].(webhook.DestinationRule).Spec.Host).To(Equal("s-destination-guid-1"))
+			Expect(loadBalancerOf(resources[0])).To(Equal(webhook.LoadBalancerSettings{Simple: "LEAST_CONN"}))
+		})
+	})
+
+	Context("when a route has no destinations", func() {
+		It("does not create a DestinationRule", func() {
+			Expect(builder.Build([]models.Route{route("route-guid-0", "")}, template)).To(Equal([]webhook.K8sResource{}))
+		})
+	})
+})
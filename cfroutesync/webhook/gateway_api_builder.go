@@ -0,0 +1,176 @@
+package webhook
+
+import (
+	"fmt"
+	"sort"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	GatewayAPIHTTPRouteVersion = "gateway.networking.k8s.io/v1"
+	GatewayAPITCPRouteVersion  = "gateway.networking.k8s.io/v1alpha2"
+)
+
+// GatewayAPIBuilder builds Kubernetes Gateway API routes as an alternative to the Istio
+// VirtualServices: an HTTPRoute for every fqdn and a TCPRoute for every TCP port.
+// Internal routes are not supported, since they need a service mesh.
+type GatewayAPIBuilder struct {
+	// Gateways the routes attach to. TCPRoutes attach to the listener on the port of the route.
+	ParentRefs []ParentReference
+}
+
+func (b *GatewayAPIBuilder) Build(routes []models.Route, template Template) []K8sResource {
+	resources := []K8sResource{}
+
+	routesForFQDN := groupByFQDN(WithoutRouteServices(httpRoutes(routes), "the Gateway API backend"))
+	for _, fqdn := range sortFQDNs(routesForFQDN) {
+		if len(destinationsForFQDN(fqdn, routesForFQDN)) == 0 {
+			continue
+		}
+		httpRoute, err := b.fqdnToHTTPRoute(fqdn, routesForFQDN[fqdn], template)
+		if err != nil {
+			log.WithError(err).Errorf("unable to create HTTPRoute for fqdn '%s'", fqdn)
+			continue
+		}
+		resources = append(resources, httpRoute)
+	}
+
+	routesForPort := GroupTCPRoutesByPort(routes)
+	ports := make([]int, 0, len(routesForPort))
+	for port := range routesForPort {
+		ports = append(ports, port)
+	}
+	// Sorting so that the results are stable
+	sort.Ints(ports)
+
+	for _, port := range ports {
+		tcpRoute, err := b.portToTCPRoute(port, routesForPort[port], template)
+		if err != nil {
+			log.WithError(err).Errorf("unable to create TCPRoute for tcp port %d", port)
+			continue
+		}
+		resources = append(resources, tcpRoute)
+	}
+
+	return resources
+}
+
+func (b *GatewayAPIBuilder) fqdnToHTTPRoute(fqdn string, routes []models.Route, template Template) (GatewayAPIHTTPRoute, error) {
+	err := validateRoutesForFQDN(routes)
+	if err != nil {
+		return GatewayAPIHTTPRoute{}, err
+	}
+	if routes[0].Domain.Internal {
+		return GatewayAPIHTTPRoute{}, fmt.Errorf("internal domains are not supported by the gateway api backend")
+	}
+
+	httpRoute := GatewayAPIHTTPRoute{
+		ApiVersion: GatewayAPIHTTPRouteVersion,
+		Kind:       "HTTPRoute",
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   template.childName(HTTPRouteName(fqdn)),
+			Labels: cloneLabels(template.ObjectMeta.Labels),
+			Annotations: map[string]string{
+				"cloudfoundry.org/fqdn": fqdn,
+			},
+		},
+		Spec: GatewayAPIHTTPRouteSpec{
+			ParentRefs: b.ParentRefs,
+			Hostnames:  []string{fqdn},
+		},
+	}
+
+	sortRoutes(routes)
+
+	for _, route := range routes {
+		if len(route.Destinations) == 0 {
+			continue
+		}
+
+		backendRefs, err := destinationsToBackendRefs(route, template)
+		if err != nil {
+			return GatewayAPIHTTPRoute{}, err
+		}
+		for i, destination := range route.Destinations {
+			backendRefs[i].Filters = []HTTPRouteFilter{{
+				Type: "RequestHeaderModifier",
+				RequestHeaderModifier: &HTTPHeaderFilter{
+					Set: DestinationHeaders(route, destination),
+				},
+			}}
+		}
+
+		rule := HTTPRouteRule{BackendRefs: backendRefs}
+		if route.Path != "" {
+			// PathPrefix matches by path segment, the same way as pathMatches
+			rule.Matches = []HTTPRouteMatch{{Path: &HTTPPathMatch{Type: "PathPrefix", Value: route.Path}}}
+		}
+		httpRoute.Spec.Rules = append(httpRoute.Spec.Rules, rule)
+	}
+
+	return httpRoute, nil
+}
+
+func (b *GatewayAPIBuilder) portToTCPRoute(port int, route models.Route, template Template) (GatewayAPITCPRoute, error) {
+	backendRefs, err := destinationsToBackendRefs(route, template)
+	if err != nil {
+		return GatewayAPITCPRoute{}, err
+	}
+
+	parentRefs := make([]ParentReference, len(b.ParentRefs))
+	for i, parentRef := range b.ParentRefs {
+		parentRefs[i] = parentRef
+		parentRefs[i].Port = models.IntPtr(port)
+	}
+
+	return GatewayAPITCPRoute{
+		ApiVersion: GatewayAPITCPRouteVersion,
+		Kind:       "TCPRoute",
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   template.childName(TCPRouteName(port)),
+			Labels: cloneLabels(template.ObjectMeta.Labels),
+			Annotations: map[string]string{
+				"cloudfoundry.org/route": route.Guid,
+				"cloudfoundry.org/url":   route.Url,
+			},
+		},
+		Spec: GatewayAPITCPRouteSpec{
+			ParentRefs: parentRefs,
+			Rules:      []TCPRouteRule{{BackendRefs: backendRefs}},
+		},
+	}, nil
+}
+
+func destinationsToBackendRefs(route models.Route, template Template) ([]BackendRef, error) {
+	err := validateWeights(route, route.Destinations)
+	if err != nil {
+		return nil, err
+	}
+
+	backendRefs := make([]BackendRef, 0, len(route.Destinations))
+	for _, destination := range route.Destinations {
+		backendRefs = append(backendRefs, BackendRef{
+			Name:   template.serviceName(destination),
+			Port:   destination.Port,
+			Weight: destination.Weight,
+		})
+	}
+	if len(route.Destinations) > 1 && route.Destinations[0].Weight == nil {
+		for i, weight := range evenWeights(len(route.Destinations)) {
+			backendRefs[i].Weight = models.IntPtr(weight)
+		}
+	}
+	return backendRefs, nil
+}
+
+func HTTPRouteName(fqdn string) string {
+	return hashedName("hr", fqdn)
+}
+
+func TCPRouteName(port int) string {
+	return fmt.Sprintf("tr-%d", port)
+}
@@ -0,0 +1,155 @@
+package webhook_test
+
+import (
+	"encoding/json"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/webhook"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("GatewayAPIBuilder", func() {
+	var (
+		template webhook.Template
+		builder  webhook.GatewayAPIBuilder
+		routes   []models.Route
+	)
+
+	BeforeEach(func() {
+		template = testTemplate()
+		builder = webhook.GatewayAPIBuilder{
+			ParentRefs: []webhook.ParentReference{{Name: "cf-gateway", Namespace: "cf-system"}},
+		}
+		routes = testRoutes()
+	})
+
+	It("returns an HTTPRoute for each fqdn and a TCPRoute for each tcp port", func() {
+		headers := func(appGuid string) []webhook.HTTPRouteFilter {
+			return []webhook.HTTPRouteFilter{{
+				Type: "RequestHeaderModifier",
+				RequestHeaderModifier: &webhook.HTTPHeaderFilter{
+					Set: []webhook.HTTPHeader{
+						{Name: "CF-App-Id", Value: appGuid},
+						{Name: "CF-App-Process-Type", Value: "web"},
+						{Name: "CF-Space-Id", Value: "space-guid-0"},
+						{Name: "CF-Organization-Id", Value: "org-guid-0"},
+					},
+				},
+			}}
+		}
+
+		Expect(builder.Build(routes, template)).To(Equal([]webhook.K8sResource{
+			webhook.GatewayAPIHTTPRoute{
+				ApiVersion: "gateway.networking.k8s.io/v1",
+				Kind:       "HTTPRoute",
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        webhook.HTTPRouteName("test0.domain0.example.com"),
+					Labels:      map[string]string{"cloudfoundry.org/bulk-sync-route": "true"},
+					Annotations: map[string]string{"cloudfoundry.org/fqdn": "test0.domain0.example.com"},
+				},
+				Spec: webhook.GatewayAPIHTTPRouteSpec{
+					ParentRefs: []webhook.ParentReference{{Name: "cf-gateway", Namespace: "cf-system"}},
+					Hostnames:  []string{"test0.domain0.example.com"},
+					Rules: []webhook.HTTPRouteRule{
+						{
+							Matches: []webhook.HTTPRouteMatch{{Path: &webhook.HTTPPathMatch{Type: "PathPrefix", Value: "/path0"}}},
+							BackendRefs: []webhook.BackendRef{
+								{Name: "s-destination-guid-1", Port: 8080, Weight: models.IntPtr(70), Filters: headers("app-destination-guid-1")},
+								{Name: "s-destination-guid-2", Port: 9000, Weight: models.IntPtr(30), Filters: headers("app-destination-guid-2")},
+							},
+						},
+						{
+							BackendRefs: []webhook.BackendRef{
+								{Name: "s-destination-guid-0", Port: 8080, Filters: headers("app-destination-guid-0")},
+							},
+						},
+					},
+				},
+			},
+			webhook.GatewayAPITCPRoute{
+				ApiVersion: "gateway.networking.k8s.io/v1alpha2",
+				Kind:       "TCPRoute",
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "tr-2001",
+					Labels: map[string]string{"cloudfoundry.org/bulk-sync-route": "true"},
+					Annotations: map[string]string{
+						"cloudfoundry.org/route": "route-guid-2",
+						"cloudfoundry.org/url":   "tcp.example.com:2001",
+					},
+				},
+				Spec: webhook.GatewayAPITCPRouteSpec{
+					ParentRefs: []webhook.ParentReference{{Name: "cf-gateway", Namespace: "cf-system", Port: models.IntPtr(2001)}},
+					Rules: []webhook.TCPRouteRule{{
+						BackendRefs: []webhook.BackendRef{
+							{Name: "s-destination-guid-3", Port: 5432},
+						},
+					}},
+				},
+			},
+		}))
+	})
+
+	It("does not modify the configured parent refs when adding ports", func() {
+		builder.Build(routes, template)
+
+		Expect(builder.ParentRefs).To(Equal([]webhook.ParentReference{{Name: "cf-gateway", Namespace: "cf-system"}}))
+	})
+
+	It("serializes to the gateway api schema", func() {
+		resources := builder.Build(routes[:1], template)
+
+		bytes, err := json.Marshal(resources[0])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bytes).To(MatchJSON(`{
+			"apiVersion": "gateway.networking.k8s.io/v1",
+			"kind": "HTTPRoute",
+			"metadata": {
+				"name": "` + webhook.HTTPRouteName("test0.domain0.example.com") + `",
+				"labels": {"cloudfoundry.org/bulk-sync-route": "true"},
+				"annotations": {"cloudfoundry.org/fqdn": "test0.domain0.example.com"},
+				"creationTimestamp": null
+			},
+			"spec": {
+				"parentRefs": [{"name": "cf-gateway", "namespace": "cf-system"}],
+				"hostnames": ["test0.domain0.example.com"],
+				"rules": [{
+					"backendRefs": [{
+						"name": "s-destination-guid-0",
+						"port": 8080,
+						"filters": [{
+							"type": "RequestHeaderModifier",
+							"requestHeaderModifier": {
+								"set": [
+									{"name": "CF-App-Id", "value": "app-destination-guid-0"},
+									{"name": "CF-App-Process-Type", "value": "web"},
+									{"name": "CF-Space-Id", "value": "space-guid-0"},
+									{"name": "CF-Organization-Id", "value": "org-guid-0"}
+								]
+							}
+						}]
+					}]
+				}]
+			}
+		}`))
+	})
+
+	Context("when a route has several destinations without weights", func() {
+		It("splits the weight evenly", func() {
+			routes[1].Destinations[0].Weight = nil
+			routes[1].Destinations[1].Weight = nil
+
+			resources := builder.Build(routes[1:2], template)
+
+			backendRefs := resources[0].(webhook.GatewayAPIHTTPRoute).Spec.Rules[0].BackendRefs
+			Expect(backendRefs[0].Weight).To(Equal(models.IntPtr(50)))
+			Expect(backendRefs[1].Weight).To(Equal(models.IntPtr(50)))
+		})
+	})
+
+	itSkipsTheRoutesItCannotServe(func(routes []models.Route) []webhook.K8sResource {
+		return builder.Build(routes, template)
+	})
+})
@@ -128,6 +128,7 @@ var _ = Describe("ServeHTTP", func() {
 
 			expectedResponseBody := `
 {
+	"status": {},
 	"children": [{
 			"apiVersion": "networking.istio.io/v1alpha3",
 			"kind": "VirtualService",
@@ -136,8 +137,7 @@ var _ = Describe("ServeHTTP", func() {
 			},
 			"spec": {
 				"hosts": null,
-				"gateways": null,
-				"http": null
+				"gateways": null
 			}
 		},
 		{
@@ -171,7 +171,7 @@ var _ = Describe("ServeHTTP", func() {
 				handler.ServeHTTP(resp, request)
 
 				Expect(resp.Code).To(Equal(http.StatusOK))
-				Expect(resp.Body).To(MatchJSON(`{"children":  []}`))
+				Expect(resp.Body).To(MatchJSON(`{"status": {}, "children":  []}`))
 			})
 		})
 
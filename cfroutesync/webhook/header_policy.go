@@ -0,0 +1,148 @@
+package webhook
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"text/template"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// headerTemplateData is what the values of the headers of a BulkSyncSpec are rendered with,
+// e.g. {{.Route.Host}}, {{.App.Guid}}, {{.Space.Guid}} or {{.Organization.Guid}}
+type headerTemplateData struct {
+	Route        models.Route
+	App          models.App
+	Space        models.Space
+	Organization models.Organization
+}
+
+type headerOperationTemplates struct {
+	set    map[string]*template.Template
+	add    map[string]*template.Template
+	remove []string
+}
+
+// HeaderPolicy holds the parsed header templates of a BulkSyncSpec, see ParseHeaderPolicy
+type HeaderPolicy struct {
+	request  headerOperationTemplates
+	response headerOperationTemplates
+}
+
+// ParseHeaderPolicy parses and validates the header values as templates. It fails on headers with invalid
+// templates, and on request headers that would change the CF headers of the destination, whatever their case.
+func ParseHeaderPolicy(headers VirtualServiceHeaders) (HeaderPolicy, error) {
+	request, err := parseHeaderOperations("request", headers.Request, isDestinationHeader)
+	if err != nil {
+		return HeaderPolicy{}, err
+	}
+	response, err := parseHeaderOperations("response", headers.Response, func(string) bool { return false })
+	if err != nil {
+		return HeaderPolicy{}, err
+	}
+	return HeaderPolicy{request: request, response: response}, nil
+}
+
+func parseHeaderOperations(direction string, operations VirtualServiceHeaderOperations, reserved func(name string) bool) (headerOperationTemplates, error) {
+	for _, name := range operations.Remove {
+		if reserved(name) {
+			return headerOperationTemplates{}, fmt.Errorf("%s header remove '%s' would change a CF header", direction, name)
+		}
+	}
+	set, err := parseHeaderTemplates(direction, "set", operations.Set, reserved)
+	if err != nil {
+		return headerOperationTemplates{}, err
+	}
+	add, err := parseHeaderTemplates(direction, "add", operations.Add, reserved)
+	if err != nil {
+		return headerOperationTemplates{}, err
+	}
+	return headerOperationTemplates{set: set, add: add, remove: operations.Remove}, nil
+}
+
+func parseHeaderTemplates(direction string, operation string, headers map[string]string, reserved func(name string) bool) (map[string]*template.Template, error) {
+	templates := map[string]*template.Template{}
+	for name, value := range headers {
+		if reserved(name) {
+			return nil, fmt.Errorf("%s header %s '%s' would change a CF header", direction, operation, name)
+		}
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s header %s '%s': %w", direction, operation, name, err)
+		}
+		if err := validateHeaderTemplate(tmpl); err != nil {
+			return nil, fmt.Errorf("%s header %s '%s': %w", direction, operation, name, err)
+		}
+		templates[name] = tmpl
+	}
+	return templates, nil
+}
+
+// validateHeaderTemplate renders the template without any data to catch fields that do not exist.
+// Labels or annotations that only some routes have are not missing here, those render empty.
+func validateHeaderTemplate(tmpl *template.Template) error {
+	validation, err := tmpl.Clone()
+	if err != nil {
+		return err
+	}
+	return validation.Option("missingkey=zero").Execute(ioutil.Discard, headerTemplateData{})
+}
+
+// isDestinationHeader returns true when the header is one of the DestinationHeaders, whose names are case-insensitive
+func isDestinationHeader(name string) bool {
+	for _, header := range DestinationHeaders(models.Route{}, models.Destination{}) {
+		if strings.EqualFold(header.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// render returns the headers for a destination of a route. Headers whose templates fail to render for the route,
+// e.g. for a label the route does not have, are logged and left out.
+func (p HeaderPolicy) render(route models.Route, destination models.Destination) VirtualServiceHeaders {
+	data := headerTemplateData{
+		Route:        route,
+		App:          destination.App,
+		Space:        route.Space,
+		Organization: route.Space.Organization,
+	}
+	return VirtualServiceHeaders{
+		Request:  p.request.render(route, data),
+		Response: p.response.render(route, data),
+	}
+}
+
+func (t headerOperationTemplates) render(route models.Route, data headerTemplateData) VirtualServiceHeaderOperations {
+	operations := VirtualServiceHeaderOperations{
+		Set: renderHeaderTemplates(route, t.set, data),
+		Add: renderHeaderTemplates(route, t.add, data),
+	}
+	if len(t.remove) != 0 {
+		operations.Remove = append([]string{}, t.remove...)
+		// Sorting so that the results are stable
+		sort.Strings(operations.Remove)
+	}
+	return operations
+}
+
+func renderHeaderTemplates(route models.Route, templates map[string]*template.Template, data headerTemplateData) map[string]string {
+	if len(templates) == 0 {
+		return nil
+	}
+	headers := map[string]string{}
+	for name, tmpl := range templates {
+		var value bytes.Buffer
+		if err := tmpl.Execute(&value, data); err != nil {
+			log.WithError(err).Errorf("ignoring header %s for route %s", name, route.Guid)
+			continue
+		}
+		headers[name] = value.String()
+	}
+	return headers
+}
@@ -0,0 +1,135 @@
+package webhook_test
+
+import (
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/webhook"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Header policies", func() {
+	var (
+		builder webhook.VirtualServiceBuilder
+		routes  []models.Route
+		headers webhook.VirtualServiceHeaders
+	)
+
+	destinationHeaders := func() webhook.VirtualServiceHeaders {
+		policy, err := webhook.ParseHeaderPolicy(headers)
+		Expect(err).NotTo(HaveOccurred())
+		resources := builder.BuildWithHeaders(routes, webhook.Template{}, policy)
+		Expect(resources).To(HaveLen(1))
+		return resources[0].(webhook.VirtualService).Spec.Http[0].Route[0].Headers
+	}
+
+	BeforeEach(func() {
+		builder = webhook.VirtualServiceBuilder{IstioGateways: []string{"some-gateway"}}
+		routes = []models.Route{{
+			Guid:   "route-guid",
+			Host:   "app",
+			Url:    "app.example.com",
+			Domain: models.Domain{Name: "example.com"},
+			Space:  models.Space{Guid: "space-guid", Organization: models.Organization{Guid: "org-guid"}},
+			Destinations: []models.Destination{{
+				Guid: "dest-guid",
+				App:  models.App{Guid: "app-guid", Process: models.Process{Type: "web"}},
+				Port: 8080,
+			}},
+		}}
+		headers = webhook.VirtualServiceHeaders{
+			Request: webhook.VirtualServiceHeaderOperations{
+				Set: map[string]string{
+					"X-Forwarded-Proto": "https",
+					"X-CF-Route":        "{{.Route.Host}}.{{.Route.Domain.Name}} in {{.Organization.Guid}}/{{.Space.Guid}}",
+				},
+				Add: map[string]string{"X-CF-Process": "{{.App.Process.Type}}"},
+			},
+			Response: webhook.VirtualServiceHeaderOperations{
+				Set:    map[string]string{"Strict-Transport-Security": "max-age=31536000"},
+				Remove: []string{"X-Powered-By", "Server"},
+			},
+		}
+	})
+
+	It("merges the rendered headers into the headers of every destination", func() {
+		Expect(destinationHeaders()).To(Equal(webhook.VirtualServiceHeaders{
+			Request: webhook.VirtualServiceHeaderOperations{
+				Set: map[string]string{
+					"X-Forwarded-Proto":   "https",
+					"X-CF-Route":          "app.example.com in org-guid/space-guid",
+					"CF-App-Id":           "app-guid",
+					"CF-App-Process-Type": "web",
+					"CF-Space-Id":         "space-guid",
+					"CF-Organization-Id":  "org-guid",
+				},
+				Add: map[string]string{"X-CF-Process": "web"},
+			},
+			Response: webhook.VirtualServiceHeaderOperations{
+				Set:    map[string]string{"Strict-Transport-Security": "max-age=31536000"},
+				Remove: []string{"Server", "X-Powered-By"},
+			},
+		}))
+	})
+
+	Context("when the request headers would change the CF headers", func() {
+		It("fails to parse a header set, whatever its case", func() {
+			headers.Request.Set["cf-app-id"] = "spoofed"
+			_, err := webhook.ParseHeaderPolicy(headers)
+			Expect(err).To(MatchError("request header set 'cf-app-id' would change a CF header"))
+		})
+
+		It("fails to parse a header add", func() {
+			headers.Request.Add = map[string]string{"CF-SPACE-ID": "spoofed"}
+			_, err := webhook.ParseHeaderPolicy(headers)
+			Expect(err).To(MatchError("request header add 'CF-SPACE-ID' would change a CF header"))
+		})
+
+		It("fails to parse a header remove", func() {
+			headers.Request.Remove = []string{"X-Forwarded-For", "CF-Organization-Id"}
+			_, err := webhook.ParseHeaderPolicy(headers)
+			Expect(err).To(MatchError("request header remove 'CF-Organization-Id' would change a CF header"))
+		})
+
+		It("still applies them to the response", func() {
+			headers.Response.Set["cf-app-id"] = "allowed"
+			Expect(destinationHeaders().Response.Set).To(HaveKeyWithValue("cf-app-id", "allowed"))
+		})
+	})
+
+	Context("when a header template is invalid", func() {
+		It("fails to parse it", func() {
+			headers.Request.Set["X-Unparseable"] = "{{.Route.Host"
+			_, err := webhook.ParseHeaderPolicy(headers)
+			Expect(err).To(MatchError(ContainSubstring("request header set 'X-Unparseable'")))
+		})
+	})
+
+	Context("when a header template refers to a field that does not exist", func() {
+		It("fails to parse it", func() {
+			headers.Response.Add = map[string]string{"X-Unknown-Field": "{{.Route.Potato}}"}
+			_, err := webhook.ParseHeaderPolicy(headers)
+			Expect(err).To(MatchError(ContainSubstring("response header add 'X-Unknown-Field'")))
+			Expect(err).To(MatchError(ContainSubstring("can't evaluate field Potato")))
+		})
+	})
+
+	Context("when a header template fails to render for a route", func() {
+		BeforeEach(func() {
+			headers.Request.Set["X-Team"] = "{{.Route.Labels.team}}"
+		})
+
+		It("leaves out only that header", func() {
+			set := destinationHeaders().Request.Set
+			Expect(set).NotTo(HaveKey("X-Team"))
+			Expect(set).To(HaveKeyWithValue("X-Forwarded-Proto", "https"))
+		})
+
+		Context("when the route has the label", func() {
+			It("renders it", func() {
+				routes[0].Labels = map[string]string{"team": "potato"}
+				Expect(destinationHeaders().Request.Set).To(HaveKeyWithValue("X-Team", "potato"))
+			})
+		})
+	})
+})
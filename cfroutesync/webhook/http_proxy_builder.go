@@ -0,0 +1,112 @@
+package webhook
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HTTPProxyBuilder builds a Contour HTTPProxy for every fqdn, equivalent to the VirtualServices
+// of the VirtualServiceBuilder. Contour is only an ingress, so internal and TCP routes are not supported.
+type HTTPProxyBuilder struct{}
+
+func (b *HTTPProxyBuilder) Build(routes []models.Route, template Template) []K8sResource {
+	resources := []K8sResource{}
+
+	routesForFQDN := groupByFQDN(WithoutRouteServices(httpRoutes(routes), "the Contour backend"))
+	for _, fqdn := range sortFQDNs(routesForFQDN) {
+		if len(destinationsForFQDN(fqdn, routesForFQDN)) == 0 {
+			continue
+		}
+		httpProxy, err := b.fqdnToHTTPProxy(fqdn, routesForFQDN[fqdn], template)
+		if err != nil {
+			log.WithError(err).Errorf("unable to create HTTPProxy for fqdn '%s'", fqdn)
+			continue
+		}
+		resources = append(resources, httpProxy)
+	}
+
+	return resources
+}
+
+func (b *HTTPProxyBuilder) fqdnToHTTPProxy(fqdn string, routes []models.Route, template Template) (HTTPProxy, error) {
+	err := validateRoutesForFQDN(routes)
+	if err != nil {
+		return HTTPProxy{}, err
+	}
+	if routes[0].Domain.Internal {
+		return HTTPProxy{}, fmt.Errorf("internal domains are not supported by the contour backend")
+	}
+
+	httpProxy := HTTPProxy{
+		ApiVersion: "projectcontour.io/v1",
+		Kind:       "HTTPProxy",
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   template.childName(HTTPProxyName(fqdn)),
+			Labels: cloneLabels(template.ObjectMeta.Labels),
+			Annotations: map[string]string{
+				"cloudfoundry.org/fqdn": fqdn,
+			},
+		},
+		Spec: HTTPProxySpec{VirtualHost: VirtualHost{Fqdn: fqdn}},
+	}
+
+	sortRoutes(routes)
+
+	for _, route := range routes {
+		if len(route.Destinations) == 0 {
+			continue
+		}
+
+		services, err := destinationsToContourServices(route, template)
+		if err != nil {
+			return HTTPProxy{}, err
+		}
+
+		if route.Path == "" {
+			httpProxy.Spec.Routes = append(httpProxy.Spec.Routes, ContourRoute{Services: services})
+			continue
+		}
+		// the same conditions as pathMatches
+		httpProxy.Spec.Routes = append(httpProxy.Spec.Routes,
+			ContourRoute{Conditions: []MatchCondition{{Exact: route.Path}}, Services: services},
+			ContourRoute{Conditions: []MatchCondition{{Prefix: route.Path + "/"}}, Services: services},
+		)
+	}
+
+	return httpProxy, nil
+}
+
+func destinationsToContourServices(route models.Route, template Template) ([]ContourService, error) {
+	err := validateWeights(route, route.Destinations)
+	if err != nil {
+		return nil, err
+	}
+
+	services := make([]ContourService, 0, len(route.Destinations))
+	for _, destination := range route.Destinations {
+		headers := []HeaderValue{}
+		for _, header := range DestinationHeaders(route, destination) {
+			headers = append(headers, HeaderValue(header))
+		}
+		services = append(services, ContourService{
+			Name:                 template.serviceName(destination),
+			Port:                 destination.Port,
+			Weight:               destination.Weight,
+			RequestHeadersPolicy: &HeadersPolicy{Set: headers},
+		})
+	}
+	if len(route.Destinations) > 1 && route.Destinations[0].Weight == nil {
+		for i, weight := range evenWeights(len(route.Destinations)) {
+			services[i].Weight = models.IntPtr(weight)
+		}
+	}
+	return services, nil
+}
+
+func HTTPProxyName(fqdn string) string {
+	return hashedName("hp", fqdn)
+}
@@ -0,0 +1,89 @@
+package webhook_test
+
+import (
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/webhook"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("HTTPProxyBuilder", func() {
+	var (
+		template webhook.Template
+		builder  webhook.HTTPProxyBuilder
+		routes   []models.Route
+	)
+
+	headers := func(appGuid string) *webhook.HeadersPolicy {
+		return &webhook.HeadersPolicy{
+			Set: []webhook.HeaderValue{
+				{Name: "CF-App-Id", Value: appGuid},
+				{Name: "CF-App-Process-Type", Value: "web"},
+				{Name: "CF-Space-Id", Value: "space-guid-0"},
+				{Name: "CF-Organization-Id", Value: "org-guid-0"},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		template = testTemplate()
+		builder = webhook.HTTPProxyBuilder{}
+		routes = testRoutes()
+	})
+
+	It("returns an HTTPProxy for each fqdn with the same matching as the VirtualServices", func() {
+		pathServices := []webhook.ContourService{
+			{Name: "s-destination-guid-1", Port: 8080, Weight: models.IntPtr(70), RequestHeadersPolicy: headers("app-destination-guid-1")},
+			{Name: "s-destination-guid-2", Port: 9000, Weight: models.IntPtr(30), RequestHeadersPolicy: headers("app-destination-guid-2")},
+		}
+
+		Expect(builder.Build(routes, template)).To(Equal([]webhook.K8sResource{
+			webhook.HTTPProxy{
+				ApiVersion: "projectcontour.io/v1",
+				Kind:       "HTTPProxy",
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        webhook.HTTPProxyName("test0.domain0.example.com"),
+					Labels:      map[string]string{"cloudfoundry.org/bulk-sync-route": "true"},
+					Annotations: map[string]string{"cloudfoundry.org/fqdn": "test0.domain0.example.com"},
+				},
+				Spec: webhook.HTTPProxySpec{
+					VirtualHost: webhook.VirtualHost{Fqdn: "test0.domain0.example.com"},
+					Routes: []webhook.ContourRoute{
+						{
+							Conditions: []webhook.MatchCondition{{Exact: "/path0"}},
+							Services:   pathServices,
+						},
+						{
+							Conditions: []webhook.MatchCondition{{Prefix: "/path0/"}},
+							Services:   pathServices,
+						},
+						{
+							Services: []webhook.ContourService{
+								{Name: "s-destination-guid-0", Port: 8080, RequestHeadersPolicy: headers("app-destination-guid-0")},
+							},
+						},
+					},
+				},
+			},
+		}))
+	})
+
+	Context("when a route has several destinations without weights", func() {
+		It("splits the weight evenly", func() {
+			routes[1].Destinations[0].Weight = nil
+			routes[1].Destinations[1].Weight = nil
+
+			resources := builder.Build(routes[1:2], template)
+
+			services := resources[0].(webhook.HTTPProxy).Spec.Routes[0].Services
+			Expect(services[0].Weight).To(Equal(models.IntPtr(50)))
+			Expect(services[1].Weight).To(Equal(models.IntPtr(50)))
+		})
+	})
+
+	itSkipsTheRoutesItCannotServe(func(routes []models.Route) []webhook.K8sResource {
+		return builder.Build(routes, template)
+	})
+})
@@ -0,0 +1,88 @@
+package webhook
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Name of the Gateway that terminates TLS for the domains of the routes
+const HTTPSGatewayName = "cf-https-domains"
+
+// HTTPSGatewayBuilder builds a Gateway with an HTTPS server for every domain of the external HTTP routes,
+// so that certificates do not have to be added to the Gateway by hand. The VirtualServiceBuilder
+// attaches to it with HTTPSGateway. The ingress gateway reads the TLS secrets from its own namespace.
+type HTTPSGatewayBuilder struct {
+	// Labels of the ingress gateway pods that serve the domains
+	GatewaySelector map[string]string
+
+	// Names of the TLS secrets by domain, domains without one use TLSSecretName
+	TLSSecrets map[string]string
+
+	// Adds an HTTP server for every domain that redirects to HTTPS
+	HTTPSRedirect bool
+}
+
+func (b *HTTPSGatewayBuilder) Build(routes []models.Route, template Template) []K8sResource {
+	domainSet := map[string]bool{}
+	for _, route := range httpRoutes(routes) {
+		if !route.Domain.Internal {
+			domainSet[route.Domain.Name] = true
+		}
+	}
+	if len(domainSet) == 0 {
+		return []K8sResource{}
+	}
+
+	domains := make([]string, 0, len(domainSet))
+	for domain := range domainSet {
+		domains = append(domains, domain)
+	}
+	// Sorting so that the results are stable
+	sort.Strings(domains)
+
+	gateway := Gateway{
+		ApiVersion: "networking.istio.io/v1alpha3",
+		Kind:       "Gateway",
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   template.childName(HTTPSGatewayName),
+			Labels: cloneLabels(template.ObjectMeta.Labels),
+		},
+		Spec: GatewaySpec{Selector: b.GatewaySelector},
+	}
+	for _, domain := range domains {
+		hosts := []string{domain, "*." + domain}
+		portName := strings.ReplaceAll(domain, ".", "-")
+
+		gateway.Spec.Servers = append(gateway.Spec.Servers, GatewayServer{
+			Port:  GatewayPort{Number: 443, Name: "https-" + portName, Protocol: "HTTPS"},
+			Hosts: hosts,
+			Tls:   &ServerTLSSettings{Mode: "SIMPLE", CredentialName: b.tlsSecret(domain)},
+		})
+		if b.HTTPSRedirect {
+			gateway.Spec.Servers = append(gateway.Spec.Servers, GatewayServer{
+				Port:  GatewayPort{Number: 80, Name: "http-" + portName, Protocol: "HTTP"},
+				Hosts: hosts,
+				Tls:   &ServerTLSSettings{HttpsRedirect: true},
+			})
+		}
+	}
+	return []K8sResource{gateway}
+}
+
+func (b *HTTPSGatewayBuilder) tlsSecret(domain string) string {
+	if secret, ok := b.TLSSecrets[domain]; ok {
+		return secret
+	}
+	return TLSSecretName(domain)
+}
+
+// TLSSecretName is the name of the TLS secret of a domain without a configured secret,
+// e.g. apps-example-com-cert for apps.example.com
+func TLSSecretName(domain string) string {
+	return fmt.Sprintf("%s-cert", strings.ReplaceAll(domain, ".", "-"))
+}
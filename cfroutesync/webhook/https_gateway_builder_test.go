@@ -0,0 +1,99 @@
+package webhook_test
+
+import (
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/webhook"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("HTTPSGatewayBuilder", func() {
+	var (
+		template webhook.Template
+		builder  webhook.HTTPSGatewayBuilder
+		routes   []models.Route
+	)
+
+	BeforeEach(func() {
+		template = webhook.Template{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{"cloudfoundry.org/bulk-sync-route": "true"},
+			},
+		}
+		builder = webhook.HTTPSGatewayBuilder{
+			GatewaySelector: map[string]string{"istio": "ingressgateway"},
+			TLSSecrets:      map[string]string{"custom.example.org": "custom-secret"},
+		}
+		routes = []models.Route{
+			{Guid: "route-0", Host: "app", Url: "app.apps.example.com", Domain: models.Domain{Name: "apps.example.com"}},
+			{Guid: "route-1", Host: "other", Url: "other.apps.example.com", Domain: models.Domain{Name: "apps.example.com"}},
+			{Guid: "route-2", Url: "custom.example.org", Domain: models.Domain{Name: "custom.example.org"}},
+			{Guid: "route-3", Host: "backend", Url: "backend.apps.internal", Domain: models.Domain{Name: "apps.internal", Internal: true}},
+			{Guid: "route-4", Url: "tcp.example.com:1234", Protocol: models.ProtocolTCP, Port: 1234, Domain: models.Domain{Name: "tcp.example.com"}},
+		}
+	})
+
+	It("returns a Gateway with an HTTPS server per external domain", func() {
+		Expect(builder.Build(routes, template)).To(Equal([]webhook.K8sResource{
+			webhook.Gateway{
+				ApiVersion: "networking.istio.io/v1alpha3",
+				Kind:       "Gateway",
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "cf-https-domains",
+					Labels: map[string]string{"cloudfoundry.org/bulk-sync-route": "true"},
+				},
+				Spec: webhook.GatewaySpec{
+					Selector: map[string]string{"istio": "ingressgateway"},
+					Servers: []webhook.GatewayServer{
+						{
+							Port:  webhook.GatewayPort{Number: 443, Name: "https-apps-example-com", Protocol: "HTTPS"},
+							Hosts: []string{"apps.example.com", "*.apps.example.com"},
+							Tls:   &webhook.ServerTLSSettings{Mode: "SIMPLE", CredentialName: "apps-example-com-cert"},
+						},
+						{
+							Port:  webhook.GatewayPort{Number: 443, Name: "https-custom-example-org", Protocol: "HTTPS"},
+							Hosts: []string{"custom.example.org", "*.custom.example.org"},
+							Tls:   &webhook.ServerTLSSettings{Mode: "SIMPLE", CredentialName: "custom-secret"},
+						},
+					},
+				},
+			},
+		}))
+	})
+
+	Context("when https redirect is enabled", func() {
+		BeforeEach(func() {
+			builder.HTTPSRedirect = true
+			routes = routes[:1]
+		})
+
+		It("adds an HTTP server per domain that redirects to HTTPS", func() {
+			resources := builder.Build(routes, template)
+			Expect(resources).To(HaveLen(1))
+			Expect(resources[0].(webhook.Gateway).Spec.Servers).To(Equal([]webhook.GatewayServer{
+				{
+					Port:  webhook.GatewayPort{Number: 443, Name: "https-apps-example-com", Protocol: "HTTPS"},
+					Hosts: []string{"apps.example.com", "*.apps.example.com"},
+					Tls:   &webhook.ServerTLSSettings{Mode: "SIMPLE", CredentialName: "apps-example-com-cert"},
+				},
+				{
+					Port:  webhook.GatewayPort{Number: 80, Name: "http-apps-example-com", Protocol: "HTTP"},
+					Hosts: []string{"apps.example.com", "*.apps.example.com"},
+					Tls:   &webhook.ServerTLSSettings{HttpsRedirect: true},
+				},
+			}))
+		})
+	})
+
+	Context("when there are no external http routes", func() {
+		BeforeEach(func() {
+			routes = routes[3:]
+		})
+
+		It("returns no Gateway", func() {
+			Expect(builder.Build(routes, template)).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,122 @@
+package webhook
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IngressBuilder builds a plain Kubernetes Ingress for every fqdn, for clusters without a mesh.
+// An Ingress can neither split traffic nor add the CF-* request headers, so a route with several
+// destinations only sends traffic to the one with the highest weight. Internal and TCP routes are not supported.
+type IngressBuilder struct {
+	// Class of the ingress controller that serves the Ingresses, empty uses the cluster default
+	IngressClassName string
+}
+
+func (b *IngressBuilder) Build(routes []models.Route, template Template) []K8sResource {
+	resources := []K8sResource{}
+
+	routesForFQDN := groupByFQDN(WithoutRouteServices(httpRoutes(routes), "the Ingress backend"))
+	for _, fqdn := range sortFQDNs(routesForFQDN) {
+		if len(destinationsForFQDN(fqdn, routesForFQDN)) == 0 {
+			continue
+		}
+		ingress, err := b.fqdnToIngress(fqdn, routesForFQDN[fqdn], template)
+		if err != nil {
+			log.WithError(err).Errorf("unable to create Ingress for fqdn '%s'", fqdn)
+			continue
+		}
+		resources = append(resources, ingress)
+	}
+
+	return resources
+}
+
+func (b *IngressBuilder) fqdnToIngress(fqdn string, routes []models.Route, template Template) (Ingress, error) {
+	err := validateRoutesForFQDN(routes)
+	if err != nil {
+		return Ingress{}, err
+	}
+	if routes[0].Domain.Internal {
+		return Ingress{}, fmt.Errorf("internal domains are not supported by the ingress backend")
+	}
+
+	rule := IngressRule{Host: fqdn}
+
+	sortRoutes(routes)
+
+	for _, route := range routes {
+		if len(route.Destinations) == 0 {
+			continue
+		}
+
+		destination, err := heaviestDestination(route)
+		if err != nil {
+			return Ingress{}, err
+		}
+
+		path := route.Path
+		if path == "" {
+			path = "/"
+		}
+		// a Prefix path matches by path segment, like pathMatches
+		rule.HTTP.Paths = append(rule.HTTP.Paths, HTTPIngressPath{
+			Path:     path,
+			PathType: "Prefix",
+			Backend: IngressBackend{
+				Service: IngressServiceBackend{
+					Name: template.serviceName(destination),
+					Port: ServiceBackendPort{Number: destination.Port},
+				},
+			},
+		})
+	}
+
+	return Ingress{
+		ApiVersion: "networking.k8s.io/v1",
+		Kind:       "Ingress",
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   template.childName(IngressName(fqdn)),
+			Labels: cloneLabels(template.ObjectMeta.Labels),
+			Annotations: map[string]string{
+				"cloudfoundry.org/fqdn": fqdn,
+			},
+		},
+		Spec: IngressSpec{
+			IngressClassName: b.IngressClassName,
+			Rules:            []IngressRule{rule},
+		},
+	}, nil
+}
+
+// heaviestDestination approximates a weighted route by the destination with the highest weight,
+// the first one when they are evenly weighted
+func heaviestDestination(route models.Route) (models.Destination, error) {
+	err := validateWeights(route, route.Destinations)
+	if err != nil {
+		return models.Destination{}, err
+	}
+
+	heaviest := route.Destinations[0]
+	if len(route.Destinations) == 1 {
+		return heaviest, nil
+	}
+	for _, destination := range route.Destinations[1:] {
+		if destination.Weight != nil && *destination.Weight > *heaviest.Weight {
+			heaviest = destination
+		}
+	}
+	log.WithFields(log.Fields{
+		"route":       route.Guid,
+		"destination": heaviest.Guid,
+	}).Warn("weighted routes are not supported by the ingress backend, only sending traffic to one destination")
+	return heaviest, nil
+}
+
+func IngressName(fqdn string) string {
+	return hashedName("in", fqdn)
+}
@@ -0,0 +1,86 @@
+package webhook_test
+
+import (
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/webhook"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("IngressBuilder", func() {
+	var (
+		template webhook.Template
+		builder  webhook.IngressBuilder
+		routes   []models.Route
+	)
+
+	backend := func(name string, port int) webhook.IngressBackend {
+		return webhook.IngressBackend{
+			Service: webhook.IngressServiceBackend{Name: name, Port: webhook.ServiceBackendPort{Number: port}},
+		}
+	}
+
+	BeforeEach(func() {
+		template = testTemplate()
+		builder = webhook.IngressBuilder{IngressClassName: "nginx"}
+		routes = testRoutes()
+	})
+
+	It("returns an Ingress for each fqdn with a prefix path per route", func() {
+		Expect(builder.Build(routes, template)).To(Equal([]webhook.K8sResource{
+			webhook.Ingress{
+				ApiVersion: "networking.k8s.io/v1",
+				Kind:       "Ingress",
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        webhook.IngressName("test0.domain0.example.com"),
+					Labels:      map[string]string{"cloudfoundry.org/bulk-sync-route": "true"},
+					Annotations: map[string]string{"cloudfoundry.org/fqdn": "test0.domain0.example.com"},
+				},
+				Spec: webhook.IngressSpec{
+					IngressClassName: "nginx",
+					Rules: []webhook.IngressRule{
+						{
+							Host: "test0.domain0.example.com",
+							HTTP: webhook.HTTPIngressRuleValue{
+								Paths: []webhook.HTTPIngressPath{
+									{Path: "/path0", PathType: "Prefix", Backend: backend("s-destination-guid-1", 8080)},
+									{Path: "/", PathType: "Prefix", Backend: backend("s-destination-guid-0", 8080)},
+								},
+							},
+						},
+					},
+				},
+			},
+		}))
+	})
+
+	Context("when a later destination of a route has the most weight", func() {
+		It("sends the traffic to that destination", func() {
+			routes[1].Destinations[0].Weight = models.IntPtr(30)
+			routes[1].Destinations[1].Weight = models.IntPtr(70)
+
+			resources := builder.Build(routes[1:2], template)
+
+			paths := resources[0].(webhook.Ingress).Spec.Rules[0].HTTP.Paths
+			Expect(paths[0].Backend).To(Equal(backend("s-destination-guid-2", 9000)))
+		})
+	})
+
+	Context("when a route has several destinations without weights", func() {
+		It("sends the traffic to the first destination", func() {
+			routes[1].Destinations[0].Weight = nil
+			routes[1].Destinations[1].Weight = nil
+
+			resources := builder.Build(routes[1:2], template)
+
+			paths := resources[0].(webhook.Ingress).Spec.Rules[0].HTTP.Paths
+			Expect(paths[0].Backend).To(Equal(backend("s-destination-guid-1", 8080)))
+		})
+	})
+
+	itSkipsTheRoutesItCannotServe(func(routes []models.Route) []webhook.K8sResource {
+		return builder.Build(routes, template)
+	})
+})
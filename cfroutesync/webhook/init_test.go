@@ -3,9 +3,13 @@ package webhook_test
 import (
 	"testing"
 
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/webhook"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestWebhook(t *testing.T) {
@@ -14,3 +18,97 @@ func TestWebhook(t *testing.T) {
 	log.SetFormatter(&log.JSONFormatter{})
 	RunSpecs(t, "WebHook Suite")
 }
+
+func testTemplate() webhook.Template {
+	return webhook.Template{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{"cloudfoundry.org/bulk-sync-route": "true"},
+		},
+	}
+}
+
+func testDestination(guid string, port int, weight *int) models.Destination {
+	return models.Destination{
+		Guid: guid,
+		App: models.App{
+			Guid:    "app-" + guid,
+			Process: models.Process{Type: "web"},
+		},
+		Port:   port,
+		Weight: weight,
+	}
+}
+
+// testRoutes are the routes the routing backends are tested with: a route without a path
+// and a route with a path and two weighted destinations on the same fqdn, and a tcp route
+func testRoutes() []models.Route {
+	space := models.Space{Guid: "space-guid-0", Organization: models.Organization{Guid: "org-guid-0"}}
+	domain := models.Domain{Guid: "domain-0-guid", Name: "domain0.example.com"}
+	return []models.Route{
+		{
+			Guid:         "route-guid-0",
+			Host:         "test0",
+			Url:          "test0.domain0.example.com",
+			Protocol:     models.ProtocolHTTP,
+			Domain:       domain,
+			Space:        space,
+			Destinations: []models.Destination{testDestination("destination-guid-0", 8080, nil)},
+		},
+		{
+			Guid:     "route-guid-1",
+			Host:     "test0",
+			Path:     "/path0",
+			Url:      "test0.domain0.example.com/path0",
+			Protocol: models.ProtocolHTTP,
+			Domain:   domain,
+			Space:    space,
+			Destinations: []models.Destination{
+				testDestination("destination-guid-1", 8080, models.IntPtr(70)),
+				testDestination("destination-guid-2", 9000, models.IntPtr(30)),
+			},
+		},
+		{
+			Guid:         "route-guid-2",
+			Url:          "tcp.example.com:2001",
+			Protocol:     models.ProtocolTCP,
+			Port:         2001,
+			Domain:       models.Domain{Guid: "tcp-domain-guid", Name: "tcp.example.com"},
+			Space:        space,
+			Destinations: []models.Destination{testDestination("destination-guid-3", 5432, nil)},
+		},
+	}
+}
+
+// itSkipsTheRoutesItCannotServe tests that a routing backend leaves out the http routes of testRoutes
+// that it cannot route to, rather than routing them some other way
+func itSkipsTheRoutesItCannotServe(build func(routes []models.Route) []webhook.K8sResource) {
+	var routes []models.Route
+
+	BeforeEach(func() {
+		routes = testRoutes()
+	})
+
+	Context("when a route is for an internal domain", func() {
+		It("skips the fqdn", func() {
+			routes[0].Domain.Internal = true
+
+			Expect(build(routes[:1])).To(Equal([]webhook.K8sResource{}))
+		})
+	})
+
+	Context("when the weights of a route are invalid", func() {
+		It("skips the fqdn", func() {
+			routes[1].Destinations[0].Weight = models.IntPtr(10)
+
+			Expect(build(routes[:2])).To(Equal([]webhook.K8sResource{}))
+		})
+	})
+
+	Context("when a route is bound to a route service", func() {
+		It("skips the route rather than routing around the route service", func() {
+			routes[1].RouteServiceUrl = "https://route-service.example.com"
+
+			Expect(build(routes[:2])).To(Equal(build(routes[:1])))
+		})
+	})
+}
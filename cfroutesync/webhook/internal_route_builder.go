@@ -0,0 +1,118 @@
+package webhook
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// InternalRouteBuilder adds the fqdns of internal routes to the service registry of the mesh,
+// so that the sidecars of other app pods have listeners and clusters for them. It builds a
+// ServiceEntry for every internal fqdn, whose endpoints are the Services of its destinations.
+// The VirtualService of the fqdn on the mesh gateway still decides which destination a request goes to.
+//
+// Apps can only connect to an internal fqdn once they resolve it to some address: with Istio's
+// DNS proxy (ISTIO_META_DNS_CAPTURE) the sidecar answers for the hosts of ServiceEntries,
+// without it the cluster DNS has to answer for the internal domains.
+type InternalRouteBuilder struct {
+	// Namespace of the Services that the ServiceBuilder builds for the destinations
+	ServiceNamespace string
+}
+
+func (b *InternalRouteBuilder) Build(routes []models.Route, template Template) []K8sResource {
+	var internalRoutes []models.Route
+	for _, route := range httpRoutes(routes) {
+		if route.Domain.Internal {
+			internalRoutes = append(internalRoutes, route)
+		}
+	}
+
+	resources := []K8sResource{}
+	routesForFQDN := groupByFQDN(internalRoutes)
+	for _, fqdn := range sortFQDNs(routesForFQDN) {
+		if len(destinationsForFQDN(fqdn, routesForFQDN)) == 0 {
+			continue
+		}
+		resources = append(resources, b.fqdnToServiceEntry(fqdn, routesForFQDN[fqdn], template))
+	}
+	return resources
+}
+
+func (b *InternalRouteBuilder) fqdnToServiceEntry(fqdn string, routes []models.Route, template Template) ServiceEntry {
+	// the protocol of a port is the appProtocol of the Services behind it,
+	// when destinations disagree the first one wins as a port can only have one
+	ports := map[int]string{}
+	for _, route := range routes {
+		for _, destination := range route.Destinations {
+			if _, ok := ports[destination.Port]; !ok {
+				ports[destination.Port] = servicePortProtocol(route, destination)
+			}
+		}
+	}
+
+	services := map[string]map[string]int{}
+	for _, route := range routes {
+		for _, destination := range route.Destinations {
+			address := fmt.Sprintf("%s.%s.svc.cluster.local", template.serviceName(destination), b.ServiceNamespace)
+			if services[address] == nil {
+				services[address] = map[string]int{}
+			}
+			services[address][internalPortName(ports[destination.Port], destination.Port)] = destination.Port
+		}
+	}
+
+	// Sorting so that the results are stable
+	sortedPorts := make([]int, 0, len(ports))
+	for port := range ports {
+		sortedPorts = append(sortedPorts, port)
+	}
+	sort.Ints(sortedPorts)
+	addresses := make([]string, 0, len(services))
+	for address := range services {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	serviceEntry := ServiceEntry{
+		ApiVersion: "networking.istio.io/v1alpha3",
+		Kind:       "ServiceEntry",
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   template.childName(InternalServiceEntryName(fqdn)),
+			Labels: cloneLabels(template.ObjectMeta.Labels),
+			Annotations: map[string]string{
+				"cloudfoundry.org/fqdn": fqdn,
+			},
+		},
+		Spec: ServiceEntrySpec{
+			Hosts:      []string{fqdn},
+			Location:   "MESH_INTERNAL",
+			Resolution: "DNS",
+		},
+	}
+	for _, port := range sortedPorts {
+		serviceEntry.Spec.Ports = append(serviceEntry.Spec.Ports, ServiceEntryPort{
+			Number:   port,
+			Protocol: strings.ToUpper(ports[port]),
+			Name:     internalPortName(ports[port], port),
+		})
+	}
+	for _, address := range addresses {
+		serviceEntry.Spec.Endpoints = append(serviceEntry.Spec.Endpoints, ServiceEntryEndpoint{
+			Address: address,
+			Ports:   services[address],
+		})
+	}
+	return serviceEntry
+}
+
+func internalPortName(protocol string, port int) string {
+	return fmt.Sprintf("%s-%d", protocol, port)
+}
+
+func InternalServiceEntryName(fqdn string) string {
+	return hashedName("ie", fqdn)
+}
@@ -0,0 +1,132 @@
+package webhook_test
+
+import (
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/webhook"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("InternalRouteBuilder", func() {
+	var (
+		template webhook.Template
+		builder  webhook.InternalRouteBuilder
+		routes   []models.Route
+	)
+
+	BeforeEach(func() {
+		template = webhook.Template{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{"cloudfoundry.org/bulk-sync-route": "true"},
+			},
+		}
+		builder = webhook.InternalRouteBuilder{ServiceNamespace: "workload-namespace"}
+		routes = []models.Route{
+			{
+				Guid:   "route-guid-0",
+				Host:   "backend",
+				Path:   "/api",
+				Url:    "backend.apps.internal/api",
+				Domain: models.Domain{Name: "apps.internal", Internal: true},
+				Destinations: []models.Destination{
+					{Guid: "dest-guid-0", Port: 8080},
+					{Guid: "dest-guid-1", Port: 9000},
+				},
+			},
+			{
+				Guid:         "route-guid-1",
+				Host:         "backend",
+				Url:          "backend.apps.internal",
+				Domain:       models.Domain{Name: "apps.internal", Internal: true},
+				Destinations: []models.Destination{{Guid: "dest-guid-0", Port: 8080}},
+			},
+			{
+				Guid:   "route-guid-2",
+				Host:   "unmapped",
+				Url:    "unmapped.apps.internal",
+				Domain: models.Domain{Name: "apps.internal", Internal: true},
+			},
+			{
+				Guid:         "route-guid-3",
+				Host:         "frontend",
+				Url:          "frontend.example.com",
+				Domain:       models.Domain{Name: "example.com"},
+				Destinations: []models.Destination{{Guid: "dest-guid-2", Port: 8080}},
+			},
+		}
+	})
+
+	It("returns a ServiceEntry per internal fqdn with destinations, whose endpoints are the destination Services", func() {
+		Expect(builder.Build(routes, template)).To(Equal([]webhook.K8sResource{
+			webhook.ServiceEntry{
+				ApiVersion: "networking.istio.io/v1alpha3",
+				Kind:       "ServiceEntry",
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        webhook.InternalServiceEntryName("backend.apps.internal"),
+					Labels:      map[string]string{"cloudfoundry.org/bulk-sync-route": "true"},
+					Annotations: map[string]string{"cloudfoundry.org/fqdn": "backend.apps.internal"},
+				},
+				Spec: webhook.ServiceEntrySpec{
+					Hosts: []string{"backend.apps.internal"},
+					Ports: []webhook.ServiceEntryPort{
+						{Number: 8080, Protocol: "HTTP", Name: "http-8080"},
+						{Number: 9000, Protocol: "HTTP", Name: "http-9000"},
+					},
+					Location:   "MESH_INTERNAL",
+					Resolution: "DNS",
+					Endpoints: []webhook.ServiceEntryEndpoint{
+						{
+							Address: "s-dest-guid-0.workload-namespace.svc.cluster.local",
+							Ports:   map[string]int{"http-8080": 8080},
+						},
+						{
+							Address: "s-dest-guid-1.workload-namespace.svc.cluster.local",
+							Ports:   map[string]int{"http-9000": 9000},
+						},
+					},
+				},
+			},
+		}))
+	})
+
+	Context("when a destination speaks http2", func() {
+		BeforeEach(func() {
+			routes[0].Destinations[0].Protocol = models.ProtocolHTTP2
+			routes[1].Destinations[0].Protocol = models.ProtocolHTTP2
+		})
+
+		It("uses the protocol of the destination Service for the port", func() {
+			resources := builder.Build(routes, template)
+			Expect(resources).To(HaveLen(1))
+			serviceEntry := resources[0].(webhook.ServiceEntry)
+			Expect(serviceEntry.Spec.Ports).To(Equal([]webhook.ServiceEntryPort{
+				{Number: 8080, Protocol: "HTTP2", Name: "http2-8080"},
+				{Number: 9000, Protocol: "HTTP", Name: "http-9000"},
+			}))
+			Expect(serviceEntry.Spec.Endpoints).To(Equal([]webhook.ServiceEntryEndpoint{
+				{
+					Address: "s-dest-guid-0.workload-namespace.svc.cluster.local",
+					Ports:   map[string]int{"http2-8080": 8080},
+				},
+				{
+					Address: "s-dest-guid-1.workload-namespace.svc.cluster.local",
+					Ports:   map[string]int{"http-9000": 9000},
+				},
+			}))
+		})
+	})
+
+	It("ignores TCP routes", func() {
+		routes = []models.Route{{
+			Guid:         "route-guid-0",
+			Url:          "tcp.apps.internal:1234",
+			Protocol:     models.ProtocolTCP,
+			Port:         1234,
+			Domain:       models.Domain{Name: "tcp.apps.internal", Internal: true},
+			Destinations: []models.Destination{{Guid: "dest-guid-0", Port: 8080}},
+		}}
+		Expect(builder.Build(routes, template)).To(BeEmpty())
+	})
+})
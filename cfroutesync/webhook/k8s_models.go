@@ -8,20 +8,60 @@ import (
 type BulkSync struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata"`
-	Spec              BulkSyncSpec `json:"spec"`
+	Spec              BulkSyncSpec   `json:"spec"`
+	Status            BulkSyncStatus `json:"status,omitempty"`
 }
 
 type BulkSyncSpec struct {
+	// Selects the children of the parent by their labels, metacontroller adopts the children it selects.
+	// It has to match the labels of the template and does not select routes, see RouteSelector.
+	// cfroutesync finds the children it applies itself by the OwnerLabel of the reconciler instead.
 	Selector Selector `json:"selector"`
 	Template Template `json:"template"`
+
+	// Selects the routes the children are built from, so that several parents can split the routes between them.
+	// The names of the children of a parent with a route selector end in the name of the parent.
+	RouteSelector RouteSelector `json:"routeSelector,omitempty"`
+
+	// Headers set, added or removed on the requests to and responses from every destination of the http routes.
+	// Values are Go templates rendered with the .Route, .App, .Space and .Organization of the destination.
+	// Syncs of the parent fail while the templates are invalid or request headers would change the CF-* headers.
+	Headers VirtualServiceHeaders `json:"headers,omitempty"`
+}
+
+// BulkSyncStatus is reported by cfroutesync when it reconciles the children itself instead of metacontroller,
+// which sets the SyncStatus instead
+type BulkSyncStatus struct {
+	ObservedGeneration int64       `json:"observedGeneration,omitempty"`
+	LastSyncTime       metav1.Time `json:"lastSyncTime,omitempty"`
+	Error              string      `json:"error,omitempty"`
+
+	// Annotations of the selected routes that are ignored because their values are invalid, see InvalidAnnotations
+	InvalidAnnotations []string `json:"invalidAnnotations,omitempty"`
 }
 
 type Selector struct {
 	MatchLabels map[string]string `json:"matchLabels"`
 }
 
+// RouteSelector selects the routes that match all of its fields, empty fields match every route.
+// Wildcard fallbacks are resolved among the selected routes, so a host only falls back to a wildcard
+// route that the same parent selects. Selectors that put a wildcard route and the specific hosts it
+// covers into different parents, e.g. by domain and by space, lose the fallback.
+type RouteSelector struct {
+	OrganizationGuids []string `json:"organizationGuids,omitempty"`
+	SpaceGuids        []string `json:"spaceGuids,omitempty"`
+	DomainGuids       []string `json:"domainGuids,omitempty"`
+
+	// Labels of the routes in Cloud Controller
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+}
+
 type Template struct {
 	metav1.ObjectMeta `json:"metadata"`
+
+	// Name of the parent when it selects only some of the routes, see Lineage.Sync
+	ParentName string `json:"-"`
 }
 
 type Service struct {
@@ -37,19 +77,30 @@ type ServiceSpec struct {
 }
 
 type ServicePort struct {
-	Port int    `json:"port"`
-	Name string `json:"name"`
+	Port        int    `json:"port"`
+	Name        string `json:"name"`
+	AppProtocol string `json:"appProtocol,omitempty"`
 }
 
-type HTTPPrefixMatch struct {
-	Prefix string `json:"prefix"`
+// StringMatch matches a string in one of three forms, only one of the fields should be set
+// https://istio.io/docs/reference/config/networking/v1alpha3/virtual-service/#StringMatch
+type StringMatch struct {
+	Exact  string `json:"exact,omitempty"`
+	Prefix string `json:"prefix,omitempty"`
+	Regex  string `json:"regex,omitempty"`
 }
 
 type HTTPMatchRequest struct {
-	Uri HTTPPrefixMatch `json:"uri"`
+	Uri     StringMatch            `json:"uri"`
+	Headers map[string]StringMatch `json:"headers,omitempty"`
 }
 type VirtualServiceDestination struct {
-	Host string `json:"host"`
+	Host string        `json:"host"`
+	Port *PortSelector `json:"port,omitempty"`
+}
+
+type PortSelector struct {
+	Number int `json:"number"`
 }
 
 type VirtualServiceHeaders struct {
@@ -69,15 +120,51 @@ type HTTPRouteDestination struct {
 	Weight      *int                      `json:"weight,omitempty"`
 }
 
+// HTTPRewrite rewrites the path and host of a request before it is forwarded
+type HTTPRewrite struct {
+	Uri       string `json:"uri,omitempty"`
+	Authority string `json:"authority,omitempty"`
+}
+
+type HTTPRetry struct {
+	Attempts int `json:"attempts"`
+}
+
+type CorsPolicy struct {
+	AllowOrigin []string `json:"allowOrigin"`
+}
+
 type HTTPRoute struct {
-	Match []HTTPMatchRequest     `json:"match,omitempty"`
-	Route []HTTPRouteDestination `json:"route,omitempty"`
+	Name       string                 `json:"name,omitempty"`
+	Match      []HTTPMatchRequest     `json:"match,omitempty"`
+	Rewrite    *HTTPRewrite           `json:"rewrite,omitempty"`
+	Route      []HTTPRouteDestination `json:"route,omitempty"`
+	Timeout    string                 `json:"timeout,omitempty"`
+	Retries    *HTTPRetry             `json:"retries,omitempty"`
+	CorsPolicy *CorsPolicy            `json:"corsPolicy,omitempty"`
+}
+
+// L4MatchAttributes matches TCP traffic by the port it arrived on
+type L4MatchAttributes struct {
+	Port     int      `json:"port"`
+	Gateways []string `json:"gateways,omitempty"`
+}
+
+type RouteDestination struct {
+	Destination VirtualServiceDestination `json:"destination"`
+	Weight      *int                      `json:"weight,omitempty"`
+}
+
+type TCPRoute struct {
+	Match []L4MatchAttributes `json:"match,omitempty"`
+	Route []RouteDestination  `json:"route"`
 }
 
 type VirtualServiceSpec struct {
 	Hosts    []string    `json:"hosts"`
 	Gateways []string    `json:"gateways"`
-	Http     []HTTPRoute `json:"http"`
+	Http     []HTTPRoute `json:"http,omitempty"`
+	Tcp      []TCPRoute  `json:"tcp,omitempty"`
 }
 
 type VirtualService struct {
@@ -86,3 +173,358 @@ type VirtualService struct {
 	metav1.ObjectMeta `json:"metadata"`
 	Spec              VirtualServiceSpec `json:"spec"`
 }
+
+type GatewayPort struct {
+	Number   int    `json:"number"`
+	Name     string `json:"name"`
+	Protocol string `json:"protocol"`
+}
+
+type ServerTLSSettings struct {
+	HttpsRedirect  bool   `json:"httpsRedirect,omitempty"`
+	Mode           string `json:"mode,omitempty"`
+	CredentialName string `json:"credentialName,omitempty"`
+}
+
+type GatewayServer struct {
+	Port  GatewayPort        `json:"port"`
+	Hosts []string           `json:"hosts"`
+	Tls   *ServerTLSSettings `json:"tls,omitempty"`
+}
+
+type GatewaySpec struct {
+	Selector map[string]string `json:"selector"`
+	Servers  []GatewayServer   `json:"servers"`
+}
+
+type Gateway struct {
+	ApiVersion        string `json:"apiVersion"`
+	Kind              string `json:"kind"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              GatewaySpec `json:"spec"`
+}
+
+type HTTPCookie struct {
+	Name string `json:"name"`
+	Path string `json:"path,omitempty"`
+	Ttl  string `json:"ttl"`
+}
+
+type ConsistentHashLB struct {
+	HttpCookie *HTTPCookie `json:"httpCookie,omitempty"`
+}
+
+// LoadBalancerSettings sets either a simple algorithm or consistent hashing, not both
+type LoadBalancerSettings struct {
+	Simple         string            `json:"simple,omitempty"`
+	ConsistentHash *ConsistentHashLB `json:"consistentHash,omitempty"`
+}
+
+// ClientTLSSettings originates TLS to the destination, e.g. with mode SIMPLE
+type ClientTLSSettings struct {
+	Mode string `json:"mode"`
+	Sni  string `json:"sni,omitempty"`
+}
+
+type TrafficPolicy struct {
+	LoadBalancer      *LoadBalancerSettings `json:"loadBalancer,omitempty"`
+	Tls               *ClientTLSSettings    `json:"tls,omitempty"`
+	PortLevelSettings []PortTrafficPolicy   `json:"portLevelSettings,omitempty"`
+}
+
+// PortTrafficPolicy overrides the traffic policy of a DestinationRule for one port of the host
+type PortTrafficPolicy struct {
+	Port PortSelector       `json:"port"`
+	Tls  *ClientTLSSettings `json:"tls,omitempty"`
+}
+
+type DestinationRuleSpec struct {
+	Host          string        `json:"host"`
+	TrafficPolicy TrafficPolicy `json:"trafficPolicy"`
+}
+
+type DestinationRule struct {
+	ApiVersion        string `json:"apiVersion"`
+	Kind              string `json:"kind"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              DestinationRuleSpec `json:"spec"`
+}
+
+type ServiceEntryPort struct {
+	Number   int    `json:"number"`
+	Protocol string `json:"protocol"`
+	Name     string `json:"name"`
+}
+
+// ServiceEntryEndpoint is an address the sidecars send the traffic of a ServiceEntry to
+type ServiceEntryEndpoint struct {
+	Address string         `json:"address"`
+	Ports   map[string]int `json:"ports,omitempty"`
+}
+
+type ServiceEntrySpec struct {
+	Hosts      []string               `json:"hosts"`
+	Ports      []ServiceEntryPort     `json:"ports"`
+	Location   string                 `json:"location"`
+	Resolution string                 `json:"resolution"`
+	Endpoints  []ServiceEntryEndpoint `json:"endpoints,omitempty"`
+}
+
+// ServiceEntry adds a host to the service registry of the mesh, so that VirtualServices can route to it
+type ServiceEntry struct {
+	ApiVersion        string `json:"apiVersion"`
+	Kind              string `json:"kind"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              ServiceEntrySpec `json:"spec"`
+}
+
+type WorkloadSelector struct {
+	Labels map[string]string `json:"labels"`
+}
+
+type EnvoyFilterSubFilterMatch struct {
+	Name string `json:"name"`
+}
+
+type EnvoyFilterFilterMatch struct {
+	Name      string                     `json:"name"`
+	SubFilter *EnvoyFilterSubFilterMatch `json:"subFilter,omitempty"`
+}
+
+type EnvoyFilterChainMatch struct {
+	Filter EnvoyFilterFilterMatch `json:"filter"`
+}
+
+type EnvoyFilterListenerMatch struct {
+	PortNumber  int                   `json:"portNumber,omitempty"`
+	FilterChain EnvoyFilterChainMatch `json:"filterChain"`
+}
+
+type EnvoyFilterMatch struct {
+	Context  string                    `json:"context"`
+	Listener *EnvoyFilterListenerMatch `json:"listener,omitempty"`
+}
+
+// EnvoyHTTPFilter is an http filter of Envoy with its config in the Struct format of Istio 1.4
+type EnvoyHTTPFilter struct {
+	Name   string      `json:"name"`
+	Config interface{} `json:"config"`
+}
+
+type EnvoyFilterPatch struct {
+	Operation string          `json:"operation"`
+	Value     EnvoyHTTPFilter `json:"value"`
+}
+
+type EnvoyConfigObjectPatch struct {
+	ApplyTo string           `json:"applyTo"`
+	Match   EnvoyFilterMatch `json:"match"`
+	Patch   EnvoyFilterPatch `json:"patch"`
+}
+
+type EnvoyFilterSpec struct {
+	WorkloadSelector WorkloadSelector         `json:"workloadSelector"`
+	ConfigPatches    []EnvoyConfigObjectPatch `json:"configPatches"`
+}
+
+// EnvoyFilter patches the Envoy config of the sidecars of the workloads it selects in its namespace
+type EnvoyFilter struct {
+	ApiVersion        string `json:"apiVersion"`
+	Kind              string `json:"kind"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              EnvoyFilterSpec `json:"spec"`
+}
+
+// ParentReference attaches a Gateway API route to a Gateway
+type ParentReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Port      *int   `json:"port,omitempty"`
+}
+
+type HTTPPathMatch struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type HTTPRouteMatch struct {
+	Path *HTTPPathMatch `json:"path,omitempty"`
+}
+
+type HTTPHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HTTPHeaderFilter struct {
+	Set    []HTTPHeader `json:"set,omitempty"`
+	Add    []HTTPHeader `json:"add,omitempty"`
+	Remove []string     `json:"remove,omitempty"`
+}
+
+type HTTPRouteFilter struct {
+	Type                   string            `json:"type"`
+	RequestHeaderModifier  *HTTPHeaderFilter `json:"requestHeaderModifier,omitempty"`
+	ResponseHeaderModifier *HTTPHeaderFilter `json:"responseHeaderModifier,omitempty"`
+}
+
+type BackendRef struct {
+	Name    string            `json:"name"`
+	Port    int               `json:"port"`
+	Weight  *int              `json:"weight,omitempty"`
+	Filters []HTTPRouteFilter `json:"filters,omitempty"`
+}
+
+type HTTPRouteRule struct {
+	Matches     []HTTPRouteMatch `json:"matches,omitempty"`
+	BackendRefs []BackendRef     `json:"backendRefs"`
+}
+
+type GatewayAPIHTTPRouteSpec struct {
+	ParentRefs []ParentReference `json:"parentRefs"`
+	Hostnames  []string          `json:"hostnames"`
+	Rules      []HTTPRouteRule   `json:"rules"`
+}
+
+// GatewayAPIHTTPRoute is a gateway.networking.k8s.io HTTPRoute, not to be confused with the Istio HTTPRoute
+type GatewayAPIHTTPRoute struct {
+	ApiVersion        string `json:"apiVersion"`
+	Kind              string `json:"kind"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              GatewayAPIHTTPRouteSpec `json:"spec"`
+}
+
+type TCPRouteRule struct {
+	BackendRefs []BackendRef `json:"backendRefs"`
+}
+
+type GatewayAPITCPRouteSpec struct {
+	ParentRefs []ParentReference `json:"parentRefs"`
+	Rules      []TCPRouteRule    `json:"rules"`
+}
+
+// GatewayAPITCPRoute is a gateway.networking.k8s.io TCPRoute, not to be confused with the Istio TCPRoute
+type GatewayAPITCPRoute struct {
+	ApiVersion        string `json:"apiVersion"`
+	Kind              string `json:"kind"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              GatewayAPITCPRouteSpec `json:"spec"`
+}
+
+// MatchCondition of a Contour route, only one of the fields should be set
+type MatchCondition struct {
+	Prefix string `json:"prefix,omitempty"`
+	Exact  string `json:"exact,omitempty"`
+}
+
+type HeaderValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HeadersPolicy struct {
+	Set    []HeaderValue `json:"set,omitempty"`
+	Remove []string      `json:"remove,omitempty"`
+}
+
+type ContourService struct {
+	Name                 string         `json:"name"`
+	Port                 int            `json:"port"`
+	Weight               *int           `json:"weight,omitempty"`
+	RequestHeadersPolicy *HeadersPolicy `json:"requestHeadersPolicy,omitempty"`
+}
+
+type ContourRoute struct {
+	Conditions []MatchCondition `json:"conditions,omitempty"`
+	Services   []ContourService `json:"services"`
+}
+
+type VirtualHost struct {
+	Fqdn string `json:"fqdn"`
+}
+
+type HTTPProxySpec struct {
+	VirtualHost VirtualHost    `json:"virtualhost"`
+	Routes      []ContourRoute `json:"routes"`
+}
+
+type HTTPProxy struct {
+	ApiVersion        string `json:"apiVersion"`
+	Kind              string `json:"kind"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              HTTPProxySpec `json:"spec"`
+}
+
+type ServiceBackendPort struct {
+	Number int `json:"number"`
+}
+
+type IngressServiceBackend struct {
+	Name string             `json:"name"`
+	Port ServiceBackendPort `json:"port"`
+}
+
+type IngressBackend struct {
+	Service IngressServiceBackend `json:"service"`
+}
+
+type HTTPIngressPath struct {
+	Path     string         `json:"path"`
+	PathType string         `json:"pathType"`
+	Backend  IngressBackend `json:"backend"`
+}
+
+type HTTPIngressRuleValue struct {
+	Paths []HTTPIngressPath `json:"paths"`
+}
+
+type IngressRule struct {
+	Host string               `json:"host"`
+	HTTP HTTPIngressRuleValue `json:"http"`
+}
+
+type IngressSpec struct {
+	IngressClassName string        `json:"ingressClassName,omitempty"`
+	Rules            []IngressRule `json:"rules"`
+}
+
+type Ingress struct {
+	ApiVersion        string `json:"apiVersion"`
+	Kind              string `json:"kind"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              IngressSpec `json:"spec"`
+}
+
+type LabelSelector struct {
+	MatchLabels map[string]string `json:"matchLabels"`
+}
+
+// NetworkPolicyPeer selects pods in the namespace of the NetworkPolicy, or all pods of the selected namespaces
+type NetworkPolicyPeer struct {
+	PodSelector       *LabelSelector `json:"podSelector,omitempty"`
+	NamespaceSelector *LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+type NetworkPolicyPort struct {
+	Protocol string `json:"protocol"`
+	Port     int    `json:"port"`
+	EndPort  *int   `json:"endPort,omitempty"`
+}
+
+type NetworkPolicyIngressRule struct {
+	From  []NetworkPolicyPeer `json:"from"`
+	Ports []NetworkPolicyPort `json:"ports,omitempty"`
+}
+
+type NetworkPolicySpec struct {
+	PodSelector LabelSelector              `json:"podSelector"`
+	PolicyTypes []string                   `json:"policyTypes"`
+	Ingress     []NetworkPolicyIngressRule `json:"ingress"`
+}
+
+type NetworkPolicy struct {
+	ApiVersion        string `json:"apiVersion"`
+	Kind              string `json:"kind"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              NetworkPolicySpec `json:"spec"`
+}
@@ -0,0 +1,119 @@
+package webhook
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NetworkPolicyBuilder enforces container to container network policies with a NetworkPolicy
+// for every app that is a route or policy destination. Only the pods of the allowed namespaces,
+// e.g. the ingress gateway, and the source apps of the policies can connect to the app pods.
+// A default-deny NetworkPolicy for the whole namespace covers the apps that are neither, like the
+// default-deny container networking of CF, since pods that no NetworkPolicy selects accept everything.
+// Istio AuthorizationPolicies can only tell sources apart by service account, which all app pods share,
+// so the pods are selected by their cloudfoundry.org/app_guid label instead.
+// The Sidecar of the workloads namespace still has to allow egress to the Services of other apps.
+type NetworkPolicyBuilder struct {
+	// Labels of the namespaces whose pods can always connect to the app pods, no namespace when empty
+	AllowedNamespaceSelector map[string]string
+}
+
+func (b *NetworkPolicyBuilder) BuildPolicies(policies []models.Policy, routes []models.Route, template Template) []K8sResource {
+	policiesForApp := map[string][]models.Policy{}
+	for _, route := range routes {
+		for _, dest := range route.Destinations {
+			policiesForApp[dest.App.Guid] = policiesForApp[dest.App.Guid]
+		}
+	}
+	for _, policy := range policies {
+		policiesForApp[policy.DestinationAppGuid] = append(policiesForApp[policy.DestinationAppGuid], policy)
+	}
+
+	appGuids := make([]string, 0, len(policiesForApp))
+	for appGuid := range policiesForApp {
+		appGuids = append(appGuids, appGuid)
+	}
+	// Sorting so that the results are stable
+	sort.Strings(appGuids)
+
+	resources := []K8sResource{defaultDenyNetworkPolicy(template)}
+	for _, appGuid := range appGuids {
+		resources = append(resources, b.appToNetworkPolicy(appGuid, policiesForApp[appGuid], template))
+	}
+	return resources
+}
+
+func (b *NetworkPolicyBuilder) appToNetworkPolicy(appGuid string, policies []models.Policy, template Template) NetworkPolicy {
+	// an empty list of ingress rules denies everything
+	ingress := []NetworkPolicyIngressRule{}
+	if len(b.AllowedNamespaceSelector) != 0 {
+		ingress = append(ingress, NetworkPolicyIngressRule{
+			From: []NetworkPolicyPeer{{NamespaceSelector: &LabelSelector{MatchLabels: b.AllowedNamespaceSelector}}},
+		})
+	}
+	for _, policy := range policies {
+		port := NetworkPolicyPort{
+			Protocol: strings.ToUpper(policy.Protocol),
+			Port:     policy.StartPort,
+		}
+		if policy.EndPort > policy.StartPort {
+			port.EndPort = models.IntPtr(policy.EndPort)
+		}
+		ingress = append(ingress, NetworkPolicyIngressRule{
+			From:  []NetworkPolicyPeer{{PodSelector: appSelector(policy.SourceAppGuid)}},
+			Ports: []NetworkPolicyPort{port},
+		})
+	}
+
+	labels := cloneLabels(template.ObjectMeta.Labels)
+	labels["cloudfoundry.org/app_guid"] = appGuid
+	return NetworkPolicy{
+		ApiVersion: "networking.k8s.io/v1",
+		Kind:       "NetworkPolicy",
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   template.childName(NetworkPolicyName(appGuid)),
+			Labels: labels,
+		},
+		Spec: NetworkPolicySpec{
+			PodSelector: *appSelector(appGuid),
+			PolicyTypes: []string{"Ingress"},
+			Ingress:     ingress,
+		},
+	}
+}
+
+// defaultDenyNetworkPolicy selects every pod of the namespace without allowing any ingress,
+// the NetworkPolicies of the apps add to it what they allow
+func defaultDenyNetworkPolicy(template Template) NetworkPolicy {
+	return NetworkPolicy{
+		ApiVersion: "networking.k8s.io/v1",
+		Kind:       "NetworkPolicy",
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   template.childName(DefaultDenyNetworkPolicyName),
+			Labels: cloneLabels(template.ObjectMeta.Labels),
+		},
+		Spec: NetworkPolicySpec{
+			PodSelector: LabelSelector{MatchLabels: map[string]string{}},
+			PolicyTypes: []string{"Ingress"},
+			Ingress:     []NetworkPolicyIngressRule{},
+		},
+	}
+}
+
+func appSelector(appGuid string) *LabelSelector {
+	return &LabelSelector{MatchLabels: map[string]string{"cloudfoundry.org/app_guid": appGuid}}
+}
+
+// Name of the NetworkPolicy that denies ingress to the pods of the namespace that no app NetworkPolicy allows,
+// it cannot collide with the names of NetworkPolicyName
+const DefaultDenyNetworkPolicyName = "np-default-deny"
+
+// app guids are valid resource names already
+func NetworkPolicyName(appGuid string) string {
+	return fmt.Sprintf("np-%s", appGuid)
+}
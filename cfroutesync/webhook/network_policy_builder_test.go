@@ -0,0 +1,126 @@
+package webhook_test
+
+import (
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/webhook"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("NetworkPolicyBuilder", func() {
+	var (
+		template webhook.Template
+		builder  webhook.NetworkPolicyBuilder
+		routes   []models.Route
+		policies []models.Policy
+	)
+
+	appSelector := func(appGuid string) *webhook.LabelSelector {
+		return &webhook.LabelSelector{MatchLabels: map[string]string{"cloudfoundry.org/app_guid": appGuid}}
+	}
+
+	istioNamespaces := webhook.NetworkPolicyIngressRule{
+		From: []webhook.NetworkPolicyPeer{
+			{NamespaceSelector: &webhook.LabelSelector{MatchLabels: map[string]string{"istio-system-ns": ""}}},
+		},
+	}
+
+	BeforeEach(func() {
+		template = webhook.Template{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{"cloudfoundry.org/bulk-sync-route": "true"},
+			},
+		}
+		builder = webhook.NetworkPolicyBuilder{
+			AllowedNamespaceSelector: map[string]string{"istio-system-ns": ""},
+		}
+		routes = []models.Route{
+			{
+				Guid: "route-0",
+				Destinations: []models.Destination{
+					{Guid: "dest-0", App: models.App{Guid: "app-0"}},
+					{Guid: "dest-1", App: models.App{Guid: "app-1"}},
+				},
+			},
+		}
+		policies = []models.Policy{
+			{SourceAppGuid: "app-0", DestinationAppGuid: "app-1", Protocol: "tcp", StartPort: 8080, EndPort: 8080},
+			{SourceAppGuid: "app-1", DestinationAppGuid: "app-2", Protocol: "udp", StartPort: 5000, EndPort: 5010},
+		}
+	})
+
+	It("returns a NetworkPolicy per app that only allows the allowed namespaces and the policy sources", func() {
+		networkPolicy := func(appGuid string, ingress ...webhook.NetworkPolicyIngressRule) webhook.NetworkPolicy {
+			return webhook.NetworkPolicy{
+				ApiVersion: "networking.k8s.io/v1",
+				Kind:       "NetworkPolicy",
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "np-" + appGuid,
+					Labels: map[string]string{
+						"cloudfoundry.org/bulk-sync-route": "true",
+						"cloudfoundry.org/app_guid":        appGuid,
+					},
+				},
+				Spec: webhook.NetworkPolicySpec{
+					PodSelector: *appSelector(appGuid),
+					PolicyTypes: []string{"Ingress"},
+					Ingress:     append([]webhook.NetworkPolicyIngressRule{istioNamespaces}, ingress...),
+				},
+			}
+		}
+
+		resources := builder.BuildPolicies(policies, routes, template)
+
+		Expect(resources[1:]).To(Equal([]webhook.K8sResource{
+			networkPolicy("app-0"),
+			networkPolicy("app-1", webhook.NetworkPolicyIngressRule{
+				From:  []webhook.NetworkPolicyPeer{{PodSelector: appSelector("app-0")}},
+				Ports: []webhook.NetworkPolicyPort{{Protocol: "TCP", Port: 8080}},
+			}),
+			networkPolicy("app-2", webhook.NetworkPolicyIngressRule{
+				From:  []webhook.NetworkPolicyPeer{{PodSelector: appSelector("app-1")}},
+				Ports: []webhook.NetworkPolicyPort{{Protocol: "UDP", Port: 5000, EndPort: models.IntPtr(5010)}},
+			}),
+		}))
+	})
+
+	It("denies ingress to every pod of the namespace by default, including apps without routes or policies", func() {
+		resources := builder.BuildPolicies(policies, routes, template)
+
+		Expect(resources[0]).To(Equal(webhook.NetworkPolicy{
+			ApiVersion: "networking.k8s.io/v1",
+			Kind:       "NetworkPolicy",
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "np-default-deny",
+				Labels: map[string]string{"cloudfoundry.org/bulk-sync-route": "true"},
+			},
+			Spec: webhook.NetworkPolicySpec{
+				PodSelector: webhook.LabelSelector{MatchLabels: map[string]string{}},
+				PolicyTypes: []string{"Ingress"},
+				Ingress:     []webhook.NetworkPolicyIngressRule{},
+			},
+		}))
+	})
+
+	Context("when an app has no routes and no policies", func() {
+		It("is still selected by the default-deny NetworkPolicy", func() {
+			resources := builder.BuildPolicies(nil, nil, template)
+
+			Expect(resources).To(HaveLen(1))
+			Expect(resources[0].(webhook.NetworkPolicy).Name).To(Equal("np-default-deny"))
+		})
+	})
+
+	Context("when no namespaces are allowed", func() {
+		It("only allows the policy sources", func() {
+			builder.AllowedNamespaceSelector = nil
+
+			resources := builder.BuildPolicies(nil, routes, template)
+
+			Expect(resources).To(HaveLen(3))
+			Expect(resources[1].(webhook.NetworkPolicy).Spec.Ingress).To(Equal([]webhook.NetworkPolicyIngressRule{}))
+		})
+	})
+})
@@ -0,0 +1,130 @@
+package webhook
+
+import (
+	"fmt"
+	"sort"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RequestSizeBuilder limits the size of the requests to routes with the max request size annotation.
+// EnvoyFilters only apply to the ingress gateway from its own namespace, so the limit is enforced by the
+// sidecars of the destinations instead: it builds an EnvoyFilter for every app process that inserts the
+// buffer filter of Envoy in front of the ports of the process. The sidecar cannot tell the routes
+// apart, so a port reached by several routes gets the largest of their limits, and no limit when
+// any of them has none. Requests are buffered up to the limit before they reach the app.
+type RequestSizeBuilder struct{}
+
+// the port limits of one app process
+type processRequestSizes struct {
+	destination models.Destination
+	// max request bytes by port, 0 is unlimited
+	ports map[int]int64
+}
+
+func (b *RequestSizeBuilder) Build(routes []models.Route, template Template) []K8sResource {
+	processes := map[string]*processRequestSizes{}
+	for _, route := range httpRoutes(routes) {
+		// the VirtualServiceBuilder already logs the invalid annotations
+		policy, _ := parseAnnotations(route)
+		maxRequestBytes := policy.MaxRequestBytes
+		for _, destination := range route.Destinations {
+			key := destination.App.Guid + "/" + destination.App.Process.Type
+			process, ok := processes[key]
+			if !ok {
+				process = &processRequestSizes{destination: destination, ports: map[int]int64{}}
+				processes[key] = process
+			}
+			limit, seen := process.ports[destination.Port]
+			if seen && limit == 0 {
+				// already unlimited
+				continue
+			}
+			if !seen || maxRequestBytes == 0 || maxRequestBytes > limit {
+				process.ports[destination.Port] = maxRequestBytes
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(processes))
+	for key := range processes {
+		keys = append(keys, key)
+	}
+	// Sorting so that the results are stable
+	sort.Strings(keys)
+
+	resources := []K8sResource{}
+	for _, key := range keys {
+		if envoyFilter, ok := processes[key].envoyFilter(template); ok {
+			resources = append(resources, envoyFilter)
+		}
+	}
+	return resources
+}
+
+func (p *processRequestSizes) envoyFilter(template Template) (EnvoyFilter, bool) {
+	ports := make([]int, 0, len(p.ports))
+	for port, limit := range p.ports {
+		if limit != 0 {
+			ports = append(ports, port)
+		}
+	}
+	if len(ports) == 0 {
+		return EnvoyFilter{}, false
+	}
+	sort.Ints(ports)
+
+	var patches []EnvoyConfigObjectPatch
+	for _, port := range ports {
+		patches = append(patches, EnvoyConfigObjectPatch{
+			ApplyTo: "HTTP_FILTER",
+			Match: EnvoyFilterMatch{
+				Context: "SIDECAR_INBOUND",
+				Listener: &EnvoyFilterListenerMatch{
+					PortNumber: port,
+					FilterChain: EnvoyFilterChainMatch{
+						Filter: EnvoyFilterFilterMatch{
+							Name:      "envoy.http_connection_manager",
+							SubFilter: &EnvoyFilterSubFilterMatch{Name: "envoy.router"},
+						},
+					},
+				},
+			},
+			Patch: EnvoyFilterPatch{
+				Operation: "INSERT_BEFORE",
+				Value: EnvoyHTTPFilter{
+					Name:   "envoy.buffer",
+					Config: map[string]int64{"max_request_bytes": p.ports[port]},
+				},
+			},
+		})
+	}
+
+	app := p.destination.App
+	return EnvoyFilter{
+		ApiVersion: "networking.istio.io/v1alpha3",
+		Kind:       "EnvoyFilter",
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   template.childName(RequestSizeEnvoyFilterName(app)),
+			Labels: cloneLabels(template.ObjectMeta.Labels),
+			Annotations: map[string]string{
+				"cloudfoundry.org/app_guid": app.Guid,
+				"cloudfoundry.org/process":  app.Process.Type,
+			},
+		},
+		Spec: EnvoyFilterSpec{
+			// the labels of the app pods, like the selector of the ServiceBuilder
+			WorkloadSelector: WorkloadSelector{Labels: map[string]string{
+				"cloudfoundry.org/app_guid":     app.Guid,
+				"cloudfoundry.org/process_type": app.Process.Type,
+			}},
+			ConfigPatches: patches,
+		},
+	}, true
+}
+
+func RequestSizeEnvoyFilterName(app models.App) string {
+	return hashedName("max-request-size", fmt.Sprintf("%s/%s", app.Guid, app.Process.Type))
+}
@@ -0,0 +1,141 @@
+package webhook_test
+
+import (
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/webhook"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("RequestSizeBuilder", func() {
+	var (
+		builder  webhook.RequestSizeBuilder
+		template webhook.Template
+	)
+
+	app := models.App{Guid: "app-guid", Process: models.Process{Type: "web"}}
+
+	route := func(guid string, maxRequestSize string, ports ...int) models.Route {
+		r := models.Route{
+			Guid:   guid,
+			Host:   guid,
+			Url:    guid + ".example.com",
+			Domain: models.Domain{Name: "example.com"},
+		}
+		if maxRequestSize != "" {
+			r.Annotations = map[string]string{webhook.AnnotationMaxRequestSize: maxRequestSize}
+		}
+		for _, port := range ports {
+			r.Destinations = append(r.Destinations, models.Destination{Guid: guid + "-dest", App: app, Port: port})
+		}
+		return r
+	}
+
+	bufferPatch := func(port int, maxRequestBytes int64) webhook.EnvoyConfigObjectPatch {
+		return webhook.EnvoyConfigObjectPatch{
+			ApplyTo: "HTTP_FILTER",
+			Match: webhook.EnvoyFilterMatch{
+				Context: "SIDECAR_INBOUND",
+				Listener: &webhook.EnvoyFilterListenerMatch{
+					PortNumber: port,
+					FilterChain: webhook.EnvoyFilterChainMatch{
+						Filter: webhook.EnvoyFilterFilterMatch{
+							Name:      "envoy.http_connection_manager",
+							SubFilter: &webhook.EnvoyFilterSubFilterMatch{Name: "envoy.router"},
+						},
+					},
+				},
+			},
+			Patch: webhook.EnvoyFilterPatch{
+				Operation: "INSERT_BEFORE",
+				Value: webhook.EnvoyHTTPFilter{
+					Name:   "envoy.buffer",
+					Config: map[string]int64{"max_request_bytes": maxRequestBytes},
+				},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		builder = webhook.RequestSizeBuilder{}
+		template = testTemplate()
+	})
+
+	It("returns an EnvoyFilter that limits the requests to the ports of the app process", func() {
+		routes := []models.Route{
+			route("route-0", "1Mi", 8080),
+			route("route-1", "100", 9090),
+		}
+
+		Expect(builder.Build(routes, template)).To(Equal([]webhook.K8sResource{
+			webhook.EnvoyFilter{
+				ApiVersion: "networking.istio.io/v1alpha3",
+				Kind:       "EnvoyFilter",
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   webhook.RequestSizeEnvoyFilterName(app),
+					Labels: map[string]string{"cloudfoundry.org/bulk-sync-route": "true"},
+					Annotations: map[string]string{
+						"cloudfoundry.org/app_guid": "app-guid",
+						"cloudfoundry.org/process":  "web",
+					},
+				},
+				Spec: webhook.EnvoyFilterSpec{
+					WorkloadSelector: webhook.WorkloadSelector{Labels: map[string]string{
+						"cloudfoundry.org/app_guid":     "app-guid",
+						"cloudfoundry.org/process_type": "web",
+					}},
+					ConfigPatches: []webhook.EnvoyConfigObjectPatch{
+						bufferPatch(8080, 1024*1024),
+						bufferPatch(9090, 100),
+					},
+				},
+			},
+		}))
+	})
+
+	Context("when several routes reach the same port", func() {
+		It("uses the largest of their limits", func() {
+			routes := []models.Route{
+				route("route-0", "1Mi", 8080),
+				route("route-1", "2Mi", 8080),
+			}
+
+			envoyFilter := builder.Build(routes, template)[0].(webhook.EnvoyFilter)
+			Expect(envoyFilter.Spec.ConfigPatches).To(Equal([]webhook.EnvoyConfigObjectPatch{bufferPatch(8080, 2*1024*1024)}))
+		})
+
+		Context("when one of them has no limit", func() {
+			It("does not limit the port", func() {
+				routes := []models.Route{
+					route("route-0", "1Mi", 8080, 9090),
+					route("route-1", "", 8080),
+				}
+
+				envoyFilter := builder.Build(routes, template)[0].(webhook.EnvoyFilter)
+				Expect(envoyFilter.Spec.ConfigPatches).To(Equal([]webhook.EnvoyConfigObjectPatch{bufferPatch(9090, 1024*1024)}))
+			})
+		})
+	})
+
+	Context("when no route limits the request size", func() {
+		It("returns nothing", func() {
+			routes := []models.Route{
+				route("route-0", "", 8080),
+				route("route-1", "potato", 8080),
+			}
+
+			Expect(builder.Build(routes, template)).To(Equal([]webhook.K8sResource{}))
+		})
+	})
+
+	Context("when the template has a parent name", func() {
+		It("scopes the name of the EnvoyFilter to the parent", func() {
+			template.ParentName = "some-parent"
+
+			envoyFilter := builder.Build([]models.Route{route("route-0", "1Mi", 8080)}, template)[0].(webhook.EnvoyFilter)
+			Expect(envoyFilter.Name).To(Equal(webhook.RequestSizeEnvoyFilterName(app) + "-some-parent"))
+		})
+	})
+})
@@ -0,0 +1,205 @@
+package webhook
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Annotations app teams can set on their routes in Cloud Controller to configure how the VirtualServiceBuilder
+// and the xDS backend route them,
+// e.g. cf curl -X PATCH /v3/routes/GUID -d '{"metadata":{"annotations":{"cfroutesync.cloudfoundry.org/timeout":"30s"}}}'
+const (
+	// Timeout of requests to the route as a duration like 30s or 2m
+	AnnotationTimeout = "cfroutesync.cloudfoundry.org/timeout"
+
+	// Number of times failed requests to the route are retried, at most MaxRetries
+	AnnotationRetries = "cfroutesync.cloudfoundry.org/retries"
+
+	// Comma separated origins like https://example.com that browsers may make cross origin requests to the route from, or *
+	AnnotationCORSAllowOrigins = "cfroutesync.cloudfoundry.org/cors-allow-origins"
+
+	// Largest size of the body of requests to the route as a quantity like 10Mi or 1M, larger requests are
+	// rejected with 413. See RequestSizeBuilder for how the Istio backend enforces it.
+	AnnotationMaxRequestSize = "cfroutesync.cloudfoundry.org/max-request-size"
+)
+
+// Retrying more often than this puts more load on an app than it saves requests
+const MaxRetries = 10
+
+// The buffer filter of Envoy limits requests to at most this many bytes
+const MaxRequestSizeLimit = math.MaxUint32
+
+// RoutePolicy is the traffic policy of a route, taken from its annotations
+type RoutePolicy struct {
+	// Timeout of the requests, 0 when the route has none
+	Timeout time.Duration
+
+	// Number of retries, nil when the route does not configure retries
+	Retries *int
+
+	// Origins cross origin requests are allowed from, none when empty
+	CORSAllowOrigins []string
+
+	// Largest size of request bodies in bytes, 0 when the route has no limit
+	MaxRequestBytes int64
+}
+
+// AnnotationPolicy parses the annotations of a route. Invalid annotations are logged and ignored,
+// like invalid route options, so that a typo does not take the route down. InvalidAnnotations
+// reports them on the parents, so that they do not go unnoticed.
+func AnnotationPolicy(route models.Route) RoutePolicy {
+	policy, errs := parseAnnotations(route)
+	for _, err := range errs {
+		log.Error(err)
+	}
+	return policy
+}
+
+// InvalidAnnotations describes every invalid annotation of the routes, with the route and the reason
+func InvalidAnnotations(routes []models.Route) []string {
+	var invalid []string
+	for _, route := range routes {
+		_, errs := parseAnnotations(route)
+		for _, err := range errs {
+			invalid = append(invalid, err.Error())
+		}
+	}
+	return invalid
+}
+
+// invalidAnnotationError is an annotation of a route that is ignored because its value is invalid
+type invalidAnnotationError struct {
+	route string
+	key   string
+	value string
+	err   error
+}
+
+func (e *invalidAnnotationError) Error() string {
+	return fmt.Sprintf("ignoring invalid annotation %s '%s' of route %s: %s", e.key, e.value, e.route, e.err)
+}
+
+func parseAnnotations(route models.Route) (RoutePolicy, []error) {
+	var policy RoutePolicy
+	var errs []error
+
+	if value, ok := route.Annotations[AnnotationTimeout]; ok {
+		timeout, err := parseTimeout(value)
+		if err != nil {
+			errs = append(errs, &invalidAnnotationError{route.Guid, AnnotationTimeout, value, err})
+		} else {
+			policy.Timeout = timeout
+		}
+	}
+
+	if value, ok := route.Annotations[AnnotationRetries]; ok {
+		retries, err := parseRetries(value)
+		if err != nil {
+			errs = append(errs, &invalidAnnotationError{route.Guid, AnnotationRetries, value, err})
+		} else {
+			policy.Retries = models.IntPtr(retries)
+		}
+	}
+
+	if value, ok := route.Annotations[AnnotationCORSAllowOrigins]; ok {
+		origins, err := parseOrigins(value)
+		if err != nil {
+			errs = append(errs, &invalidAnnotationError{route.Guid, AnnotationCORSAllowOrigins, value, err})
+		} else {
+			policy.CORSAllowOrigins = origins
+		}
+	}
+
+	if value, ok := route.Annotations[AnnotationMaxRequestSize]; ok {
+		maxRequestBytes, err := parseMaxRequestSize(value)
+		if err != nil {
+			errs = append(errs, &invalidAnnotationError{route.Guid, AnnotationMaxRequestSize, value, err})
+		} else {
+			policy.MaxRequestBytes = maxRequestBytes
+		}
+	}
+
+	return policy, errs
+}
+
+func (p RoutePolicy) apply(httpRoute *HTTPRoute) {
+	if p.Timeout != 0 {
+		// in the seconds format of Istio
+		httpRoute.Timeout = strconv.FormatFloat(p.Timeout.Seconds(), 'f', -1, 64) + "s"
+	}
+	if p.Retries != nil {
+		httpRoute.Retries = &HTTPRetry{Attempts: *p.Retries}
+	}
+	if len(p.CORSAllowOrigins) != 0 {
+		httpRoute.CorsPolicy = &CorsPolicy{AllowOrigin: p.CORSAllowOrigins}
+	}
+}
+
+func parseTimeout(value string) (time.Duration, error) {
+	timeout, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("timeout must be a duration like 30s: %w", err)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("timeout must be positive")
+	}
+	return timeout, nil
+}
+
+func parseRetries(value string) (int, error) {
+	retries, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("retries must be a number: %w", err)
+	}
+	if retries < 0 || retries > MaxRetries {
+		return 0, fmt.Errorf("retries must be between 0 and %d", MaxRetries)
+	}
+	return retries, nil
+}
+
+func parseMaxRequestSize(value string) (int64, error) {
+	quantity, err := resource.ParseQuantity(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("max request size must be a quantity like 10Mi: %w", err)
+	}
+	size, ok := quantity.AsInt64()
+	if !ok || size <= 0 || size > MaxRequestSizeLimit {
+		return 0, fmt.Errorf("max request size must be a whole number of bytes between 1 and %d", int64(MaxRequestSizeLimit))
+	}
+	return size, nil
+}
+
+func parseOrigins(value string) ([]string, error) {
+	var origins []string
+	for _, origin := range strings.Split(value, ",") {
+		origin = strings.TrimSpace(origin)
+		if origin == "" {
+			continue
+		}
+		if origin != "*" {
+			originUrl, err := url.Parse(origin)
+			if err != nil {
+				return nil, fmt.Errorf("origin '%s': %w", origin, err)
+			}
+			if (originUrl.Scheme != "http" && originUrl.Scheme != "https") || originUrl.Host == "" ||
+				(originUrl.Path != "" && originUrl.Path != "/") || originUrl.RawQuery != "" {
+				return nil, fmt.Errorf("origin '%s' must be a scheme and host like https://example.com", origin)
+			}
+			origin = originUrl.Scheme + "://" + originUrl.Host
+		}
+		origins = append(origins, origin)
+	}
+	if len(origins) == 0 {
+		return nil, fmt.Errorf("no origins")
+	}
+	return origins, nil
+}
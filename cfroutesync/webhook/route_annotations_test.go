@@ -0,0 +1,151 @@
+package webhook_test
+
+import (
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/webhook"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Route annotations", func() {
+	var (
+		builder webhook.VirtualServiceBuilder
+		route   models.Route
+	)
+
+	build := func() webhook.HTTPRoute {
+		resources := builder.Build([]models.Route{route}, webhook.Template{})
+		Expect(resources).To(HaveLen(1))
+		httpRoutes := resources[0].(webhook.VirtualService).Spec.Http
+		Expect(httpRoutes).To(HaveLen(1))
+		return httpRoutes[0]
+	}
+
+	BeforeEach(func() {
+		builder = webhook.VirtualServiceBuilder{IstioGateways: []string{"some-gateway"}}
+		route = models.Route{
+			Guid:         "route-guid",
+			Host:         "app",
+			Url:          "app.example.com",
+			Domain:       models.Domain{Name: "example.com"},
+			Destinations: []models.Destination{{Guid: "dest-guid", Port: 8080}},
+			Annotations: map[string]string{
+				webhook.AnnotationTimeout:          "1m30s",
+				webhook.AnnotationRetries:          "3",
+				webhook.AnnotationCORSAllowOrigins: "https://example.com, http://other.example.com:8080/",
+				"some-other-annotation":            "potato",
+			},
+		}
+	})
+
+	It("sets the timeout, retries and cors policy of the http route", func() {
+		httpRoute := build()
+		Expect(httpRoute.Timeout).To(Equal("90s"))
+		Expect(httpRoute.Retries).To(Equal(&webhook.HTTPRetry{Attempts: 3}))
+		Expect(httpRoute.CorsPolicy).To(Equal(&webhook.CorsPolicy{
+			AllowOrigin: []string{"https://example.com", "http://other.example.com:8080"},
+		}))
+	})
+
+	It("allows disabling retries", func() {
+		route.Annotations[webhook.AnnotationRetries] = "0"
+		Expect(build().Retries).To(Equal(&webhook.HTTPRetry{Attempts: 0}))
+	})
+
+	Context("when the route has no annotations", func() {
+		BeforeEach(func() {
+			route.Annotations = nil
+		})
+
+		It("leaves the defaults of Istio", func() {
+			httpRoute := build()
+			Expect(httpRoute.Timeout).To(BeEmpty())
+			Expect(httpRoute.Retries).To(BeNil())
+			Expect(httpRoute.CorsPolicy).To(BeNil())
+		})
+	})
+
+	Context("when the timeout is invalid", func() {
+		It("ignores it, keeping the route and the other annotations", func() {
+			for _, timeout := range []string{"30", "-5s"} {
+				route.Annotations[webhook.AnnotationTimeout] = timeout
+				httpRoute := build()
+				Expect(httpRoute.Timeout).To(BeEmpty())
+				Expect(httpRoute.Retries).To(Equal(&webhook.HTTPRetry{Attempts: 3}))
+			}
+		})
+	})
+
+	Context("when the retries are invalid", func() {
+		It("ignores them, keeping the route and the other annotations", func() {
+			for _, retries := range []string{"potato", "-1", "11"} {
+				route.Annotations[webhook.AnnotationRetries] = retries
+				httpRoute := build()
+				Expect(httpRoute.Retries).To(BeNil())
+				Expect(httpRoute.Timeout).To(Equal("90s"))
+			}
+		})
+	})
+
+	Context("when the cors allow origins are invalid", func() {
+		It("ignores them, keeping the route and the other annotations", func() {
+			for _, origins := range []string{"example.com", "https://example.com/potato", " , "} {
+				route.Annotations[webhook.AnnotationCORSAllowOrigins] = origins
+				httpRoute := build()
+				Expect(httpRoute.CorsPolicy).To(BeNil())
+				Expect(httpRoute.Timeout).To(Equal("90s"))
+			}
+		})
+	})
+
+	Describe("AnnotationPolicy", func() {
+		It("parses the max request size as a quantity of bytes", func() {
+			for value, bytes := range map[string]int64{"10Mi": 10 * 1024 * 1024, "1M": 1000000, " 512 ": 512} {
+				route.Annotations[webhook.AnnotationMaxRequestSize] = value
+				Expect(webhook.AnnotationPolicy(route).MaxRequestBytes).To(Equal(bytes))
+			}
+		})
+
+		Context("when the route has no max request size", func() {
+			It("has no limit", func() {
+				Expect(webhook.AnnotationPolicy(route).MaxRequestBytes).To(BeZero())
+			})
+		})
+
+		Context("when the max request size is invalid", func() {
+			It("ignores it, keeping the other annotations", func() {
+				for _, size := range []string{"potato", "0", "-1Mi", "0.5", "5Gi"} {
+					route.Annotations[webhook.AnnotationMaxRequestSize] = size
+					policy := webhook.AnnotationPolicy(route)
+					Expect(policy.MaxRequestBytes).To(BeZero())
+					Expect(policy.Retries).To(Equal(models.IntPtr(3)))
+				}
+			})
+		})
+	})
+
+	Describe("InvalidAnnotations", func() {
+		It("describes the invalid annotations of every route", func() {
+			route.Annotations[webhook.AnnotationTimeout] = "30"
+			otherRoute := route
+			otherRoute.Guid = "other-route-guid"
+			otherRoute.Annotations = map[string]string{
+				webhook.AnnotationRetries:        "11",
+				webhook.AnnotationMaxRequestSize: "5Gi",
+			}
+
+			Expect(webhook.InvalidAnnotations([]models.Route{route, otherRoute})).To(ConsistOf(
+				`ignoring invalid annotation cfroutesync.cloudfoundry.org/timeout '30' of route route-guid: timeout must be a duration like 30s: time: missing unit in duration "30"`,
+				`ignoring invalid annotation cfroutesync.cloudfoundry.org/retries '11' of route other-route-guid: retries must be between 0 and 10`,
+				`ignoring invalid annotation cfroutesync.cloudfoundry.org/max-request-size '5Gi' of route other-route-guid: max request size must be a whole number of bytes between 1 and 4294967295`,
+			))
+		})
+
+		Context("when the annotations are valid", func() {
+			It("returns nothing", func() {
+				Expect(webhook.InvalidAnnotations([]models.Route{route})).To(BeEmpty())
+			})
+		})
+	})
+})
@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+)
+
+// Select returns the routes that match the selector, in their original order
+func (s RouteSelector) Select(routes []models.Route) []models.Route {
+	if s.isEmpty() {
+		return routes
+	}
+
+	selected := []models.Route{}
+	for _, route := range routes {
+		if s.Matches(route) {
+			selected = append(selected, route)
+		}
+	}
+	return selected
+}
+
+func (s RouteSelector) Matches(route models.Route) bool {
+	if !matchesAny(s.OrganizationGuids, route.Space.Organization.Guid) ||
+		!matchesAny(s.SpaceGuids, route.Space.Guid) ||
+		!matchesAny(s.DomainGuids, route.Domain.Guid) {
+		return false
+	}
+	for key, value := range s.MatchLabels {
+		if routeValue, ok := route.Labels[key]; !ok || routeValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+func (s RouteSelector) isEmpty() bool {
+	return len(s.OrganizationGuids) == 0 && len(s.SpaceGuids) == 0 && len(s.DomainGuids) == 0 && len(s.MatchLabels) == 0
+}
+
+// matchesAny is true when the guid is one of the guids, or there are no guids to match
+func matchesAny(guids []string, guid string) bool {
+	if len(guids) == 0 {
+		return true
+	}
+	for _, g := range guids {
+		if g == guid {
+			return true
+		}
+	}
+	return false
+}
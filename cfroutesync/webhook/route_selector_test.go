@@ -0,0 +1,70 @@
+package webhook_test
+
+import (
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/webhook"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RouteSelector", func() {
+	var routes []models.Route
+
+	route := func(guid, org, space, domain string, labels map[string]string) models.Route {
+		return models.Route{
+			Guid:   guid,
+			Space:  models.Space{Guid: space, Organization: models.Organization{Guid: org}},
+			Domain: models.Domain{Guid: domain},
+			Labels: labels,
+		}
+	}
+
+	guids := func(routes []models.Route) []string {
+		result := []string{}
+		for _, route := range routes {
+			result = append(result, route.Guid)
+		}
+		return result
+	}
+
+	BeforeEach(func() {
+		routes = []models.Route{
+			route("route-0", "org-0", "space-0", "domain-0", map[string]string{"segment": "potato"}),
+			route("route-1", "org-0", "space-1", "domain-1", nil),
+			route("route-2", "org-1", "space-2", "domain-0", map[string]string{"segment": "tomato"}),
+		}
+	})
+
+	It("selects every route when it is empty", func() {
+		Expect(webhook.RouteSelector{}.Select(routes)).To(Equal(routes))
+	})
+
+	It("selects the routes of the organizations", func() {
+		selector := webhook.RouteSelector{OrganizationGuids: []string{"org-0"}}
+		Expect(guids(selector.Select(routes))).To(Equal([]string{"route-0", "route-1"}))
+	})
+
+	It("selects the routes of the spaces", func() {
+		selector := webhook.RouteSelector{SpaceGuids: []string{"space-1", "space-2"}}
+		Expect(guids(selector.Select(routes))).To(Equal([]string{"route-1", "route-2"}))
+	})
+
+	It("selects the routes of the domains", func() {
+		selector := webhook.RouteSelector{DomainGuids: []string{"domain-0"}}
+		Expect(guids(selector.Select(routes))).To(Equal([]string{"route-0", "route-2"}))
+	})
+
+	It("selects the routes with all the labels", func() {
+		selector := webhook.RouteSelector{MatchLabels: map[string]string{"segment": "potato"}}
+		Expect(guids(selector.Select(routes))).To(Equal([]string{"route-0"}))
+	})
+
+	It("selects the routes that match all of the fields", func() {
+		selector := webhook.RouteSelector{OrganizationGuids: []string{"org-0"}, DomainGuids: []string{"domain-0"}}
+		Expect(guids(selector.Select(routes))).To(Equal([]string{"route-0"}))
+
+		selector = webhook.RouteSelector{OrganizationGuids: []string{"org-1"}, SpaceGuids: []string{"space-0"}}
+		Expect(selector.Select(routes)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,117 @@
+package webhook
+
+import (
+	"fmt"
+	"sort"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RouteServiceBuilder makes the route services that the VirtualServiceBuilder sends traffic through
+// reachable from the mesh. It builds a ServiceEntry for every route service host with all of its ports,
+// and for hosts with https route services a DestinationRule that originates TLS on those ports.
+// Istio applies only one DestinationRule per host, so there is one per host rather than per port.
+type RouteServiceBuilder struct{}
+
+func (b *RouteServiceBuilder) Build(routes []models.Route, template Template) []K8sResource {
+	// the scheme of every port of every route service host
+	routeServiceHosts := map[string]map[int]string{}
+	for _, route := range httpRoutes(routes) {
+		if route.RouteServiceUrl == "" || len(route.Destinations) == 0 {
+			continue
+		}
+		routeServiceUrl, err := parseRouteServiceUrl(route.RouteServiceUrl)
+		if err != nil {
+			log.WithError(err).Errorf("unable to create ServiceEntry for route %s", route.Guid)
+			continue
+		}
+		host := routeServiceUrl.Hostname()
+		if routeServiceHosts[host] == nil {
+			routeServiceHosts[host] = map[int]string{}
+		}
+		routeServiceHosts[host][routeServicePort(routeServiceUrl)] = routeServiceUrl.Scheme
+	}
+
+	hosts := make([]string, 0, len(routeServiceHosts))
+	for host := range routeServiceHosts {
+		hosts = append(hosts, host)
+	}
+	// Sorting so that the results are stable
+	sort.Strings(hosts)
+
+	resources := []K8sResource{}
+	for _, host := range hosts {
+		schemes := routeServiceHosts[host]
+		ports := make([]int, 0, len(schemes))
+		for port := range schemes {
+			ports = append(ports, port)
+		}
+		sort.Ints(ports)
+
+		objectMeta := metav1.ObjectMeta{
+			Name:   template.childName(RouteServiceEntryName(host)),
+			Labels: cloneLabels(template.ObjectMeta.Labels),
+			Annotations: map[string]string{
+				"cloudfoundry.org/route-service": host,
+			},
+		}
+
+		// the sidecar sends plain http to every port, the DestinationRule upgrades it to TLS on the https ports
+		var serviceEntryPorts []ServiceEntryPort
+		var tlsPorts []PortTrafficPolicy
+		for _, port := range ports {
+			serviceEntryPorts = append(serviceEntryPorts, ServiceEntryPort{Number: port, Protocol: "HTTP", Name: fmt.Sprintf("http-%d", port)})
+			if schemes[port] == "https" {
+				tlsPorts = append(tlsPorts, PortTrafficPolicy{
+					Port: PortSelector{Number: port},
+					Tls:  &ClientTLSSettings{Mode: "SIMPLE", Sni: host},
+				})
+			}
+		}
+
+		resources = append(resources, ServiceEntry{
+			ApiVersion: "networking.istio.io/v1alpha3",
+			Kind:       "ServiceEntry",
+			ObjectMeta: objectMeta,
+			Spec: ServiceEntrySpec{
+				Hosts:      []string{host},
+				Ports:      serviceEntryPorts,
+				Location:   "MESH_EXTERNAL",
+				Resolution: "DNS",
+			},
+		})
+		if len(tlsPorts) > 0 {
+			resources = append(resources, DestinationRule{
+				ApiVersion: "networking.istio.io/v1alpha3",
+				Kind:       "DestinationRule",
+				ObjectMeta: objectMeta,
+				Spec: DestinationRuleSpec{
+					Host:          host,
+					TrafficPolicy: TrafficPolicy{PortLevelSettings: tlsPorts},
+				},
+			})
+		}
+	}
+	return resources
+}
+
+func RouteServiceEntryName(host string) string {
+	return hashedName("rs", host)
+}
+
+// WithoutRouteServices drops the routes bound to a route service for the backends that cannot
+// send traffic through route services, rather than routing around the route service
+func WithoutRouteServices(routes []models.Route, backend string) []models.Route {
+	var filtered []models.Route
+	for _, route := range routes {
+		if route.RouteServiceUrl != "" && len(route.Destinations) != 0 {
+			log.Errorf("skipping route %s: it is bound to a route service, which %s does not support", route.Guid, backend)
+			continue
+		}
+		filtered = append(filtered, route)
+	}
+	return filtered
+}
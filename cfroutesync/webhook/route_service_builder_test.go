@@ -0,0 +1,118 @@
+package webhook_test
+
+import (
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/webhook"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("RouteServiceBuilder", func() {
+	var (
+		template webhook.Template
+		builder  webhook.RouteServiceBuilder
+		routes   []models.Route
+	)
+
+	route := func(guid string, routeServiceUrl string) models.Route {
+		return models.Route{
+			Guid:            guid,
+			Host:            guid,
+			Url:             guid + ".example.com",
+			RouteServiceUrl: routeServiceUrl,
+			Domain:          models.Domain{Name: "example.com"},
+			Destinations:    []models.Destination{{Guid: "dest-" + guid, Port: 8080}},
+		}
+	}
+
+	BeforeEach(func() {
+		template = webhook.Template{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{"cloudfoundry.org/bulk-sync-route": "true"},
+			},
+		}
+		builder = webhook.RouteServiceBuilder{}
+		routes = []models.Route{
+			route("route-0", "https://route-service.example.com/auth"),
+			route("route-1", "https://route-service.example.com/other"),
+			route("route-2", "http://plain.example.com:8000"),
+			route("route-3", ""),
+		}
+	})
+
+	It("returns a ServiceEntry per route service host with all of its ports, originating tls on the https ports", func() {
+		routes = append(routes,
+			route("route-4", "https://route-service.example.com:8443/auth"),
+			route("route-5", "http://route-service.example.com:8080"),
+		)
+		httpsMeta := metav1.ObjectMeta{
+			Name:        webhook.RouteServiceEntryName("route-service.example.com"),
+			Labels:      map[string]string{"cloudfoundry.org/bulk-sync-route": "true"},
+			Annotations: map[string]string{"cloudfoundry.org/route-service": "route-service.example.com"},
+		}
+		httpMeta := metav1.ObjectMeta{
+			Name:        webhook.RouteServiceEntryName("plain.example.com"),
+			Labels:      map[string]string{"cloudfoundry.org/bulk-sync-route": "true"},
+			Annotations: map[string]string{"cloudfoundry.org/route-service": "plain.example.com"},
+		}
+
+		Expect(builder.Build(routes, template)).To(Equal([]webhook.K8sResource{
+			webhook.ServiceEntry{
+				ApiVersion: "networking.istio.io/v1alpha3",
+				Kind:       "ServiceEntry",
+				ObjectMeta: httpMeta,
+				Spec: webhook.ServiceEntrySpec{
+					Hosts:      []string{"plain.example.com"},
+					Ports:      []webhook.ServiceEntryPort{{Number: 8000, Protocol: "HTTP", Name: "http-8000"}},
+					Location:   "MESH_EXTERNAL",
+					Resolution: "DNS",
+				},
+			},
+			webhook.ServiceEntry{
+				ApiVersion: "networking.istio.io/v1alpha3",
+				Kind:       "ServiceEntry",
+				ObjectMeta: httpsMeta,
+				Spec: webhook.ServiceEntrySpec{
+					Hosts: []string{"route-service.example.com"},
+					Ports: []webhook.ServiceEntryPort{
+						{Number: 443, Protocol: "HTTP", Name: "http-443"},
+						{Number: 8080, Protocol: "HTTP", Name: "http-8080"},
+						{Number: 8443, Protocol: "HTTP", Name: "http-8443"},
+					},
+					Location:   "MESH_EXTERNAL",
+					Resolution: "DNS",
+				},
+			},
+			webhook.DestinationRule{
+				ApiVersion: "networking.istio.io/v1alpha3",
+				Kind:       "DestinationRule",
+				ObjectMeta: httpsMeta,
+				Spec: webhook.DestinationRuleSpec{
+					Host: "route-service.example.com",
+					TrafficPolicy: webhook.TrafficPolicy{
+						PortLevelSettings: []webhook.PortTrafficPolicy{
+							{
+								Port: webhook.PortSelector{Number: 443},
+								Tls:  &webhook.ClientTLSSettings{Mode: "SIMPLE", Sni: "route-service.example.com"},
+							},
+							{
+								Port: webhook.PortSelector{Number: 8443},
+								Tls:  &webhook.ClientTLSSettings{Mode: "SIMPLE", Sni: "route-service.example.com"},
+							},
+						},
+					},
+				},
+			},
+		}))
+	})
+
+	Context("when the route service url is invalid", func() {
+		It("skips it", func() {
+			routes = []models.Route{route("route-0", "ftp://route-service.example.com")}
+
+			Expect(builder.Build(routes, template)).To(Equal([]webhook.K8sResource{}))
+		})
+	})
+})
@@ -21,15 +21,15 @@ func (b *ServiceBuilder) Build(routes []models.Route, template Template) []K8sRe
 }
 
 func routeToServices(route models.Route, template Template) []Service {
-	const httpPortName = "http"
 	const podLabelPrefix = "cloudfoundry.org/"
 	services := []Service{}
 	for _, dest := range route.Destinations {
+		portProtocol := servicePortProtocol(route, dest)
 		service := Service{
 			ApiVersion: "v1",
 			Kind:       "Service",
 			ObjectMeta: metav1.ObjectMeta{
-				Name:        serviceName(dest),
+				Name:        template.serviceName(dest),
 				Labels:      cloneLabels(template.ObjectMeta.Labels),
 				Annotations: map[string]string{},
 			},
@@ -40,8 +40,9 @@ func routeToServices(route models.Route, template Template) []Service {
 				},
 				Ports: []ServicePort{
 					{
-						Port: dest.Port,
-						Name: httpPortName,
+						Port:        dest.Port,
+						Name:        portProtocol,
+						AppProtocol: portProtocol,
 					}},
 			},
 		}
@@ -54,7 +55,21 @@ func routeToServices(route models.Route, template Template) []Service {
 	return services
 }
 
+// servicePortProtocol returns the protocol Istio should use for the destination.
+// Istio picks it from the appProtocol of the service port, or on older clusters from the port name.
+func servicePortProtocol(route models.Route, dest models.Destination) string {
+	if route.IsTCP() {
+		return "tcp"
+	}
+	switch dest.Protocol {
+	case models.ProtocolHTTP2:
+		return "http2"
+	default:
+		return "http"
+	}
+}
+
 // service names cannot start with numbers
-func serviceName(dest models.Destination) string {
+func ServiceName(dest models.Destination) string {
 	return fmt.Sprintf("s-%s", dest.Guid)
 }
@@ -129,8 +129,9 @@ var _ = Describe("ServiceBuilder", func() {
 
 					Ports: []webhook.ServicePort{
 						webhook.ServicePort{
-							Port: 9000,
-							Name: "http",
+							Port:        9000,
+							Name:        "http",
+							AppProtocol: "http",
 						},
 					},
 				},
@@ -159,8 +160,9 @@ var _ = Describe("ServiceBuilder", func() {
 
 					Ports: []webhook.ServicePort{
 						webhook.ServicePort{
-							Port: 9001,
-							Name: "http",
+							Port:        9001,
+							Name:        "http",
+							AppProtocol: "http",
 						},
 					},
 				},
@@ -189,8 +191,9 @@ var _ = Describe("ServiceBuilder", func() {
 
 					Ports: []webhook.ServicePort{
 						webhook.ServicePort{
-							Port: 8080,
-							Name: "http",
+							Port:        8080,
+							Name:        "http",
+							AppProtocol: "http",
 						},
 					},
 				},
@@ -219,8 +222,9 @@ var _ = Describe("ServiceBuilder", func() {
 
 					Ports: []webhook.ServicePort{
 						webhook.ServicePort{
-							Port: 8080,
-							Name: "http",
+							Port:        8080,
+							Name:        "http",
+							AppProtocol: "http",
 						},
 					},
 				},
@@ -252,4 +256,134 @@ var _ = Describe("ServiceBuilder", func() {
 			Expect(builder.Build(routes, template)).To(Equal([]webhook.K8sResource{}))
 		})
 	})
+	Context("when a route is a tcp route", func() {
+		It("names the service port tcp so that Istio treats the traffic as opaque tcp", func() {
+			routes := []models.Route{
+				models.Route{
+					Guid:     "route-guid-0",
+					Url:      "tcp.example.com:1234",
+					Protocol: models.ProtocolTCP,
+					Port:     1234,
+					Domain: models.Domain{
+						Guid: "domain-0-guid",
+						Name: "tcp.example.com",
+					},
+					Destinations: []models.Destination{
+						models.Destination{
+							Guid: "route-0-destination-guid-0",
+							App: models.App{
+								Guid:    "app-guid-0",
+								Process: models.Process{Type: "process-type-1"},
+							},
+							Port: 5432,
+						},
+					},
+				},
+			}
+
+			builder := webhook.ServiceBuilder{}
+			services := builder.Build(routes, template)
+			Expect(services).To(HaveLen(1))
+			Expect(services[0].(webhook.Service).Spec.Ports).To(Equal([]webhook.ServicePort{
+				{Port: 5432, Name: "tcp", AppProtocol: "tcp"},
+			}))
+		})
+	})
+	Context("when a destination speaks http2", func() {
+		It("names the service port after the protocol and sets the appProtocol", func() {
+			destination := func(guid, protocol string) models.Destination {
+				return models.Destination{
+					Guid: guid,
+					App: models.App{
+						Guid:    "app-guid-0",
+						Process: models.Process{Type: "web"},
+					},
+					Port:     8080,
+					Protocol: protocol,
+				}
+			}
+			routes := []models.Route{
+				models.Route{
+					Guid:     "route-guid-0",
+					Host:     "test0",
+					Protocol: models.ProtocolHTTP,
+					Domain: models.Domain{
+						Guid: "domain-0-guid",
+						Name: "domain0.example.com",
+					},
+					Destinations: []models.Destination{
+						destination("destination-http1", models.ProtocolHTTP1),
+						destination("destination-http2", models.ProtocolHTTP2),
+						destination("destination-unset", ""),
+					},
+				},
+			}
+
+			builder := webhook.ServiceBuilder{}
+			services := builder.Build(routes, template)
+
+			var ports []webhook.ServicePort
+			for _, service := range services {
+				ports = append(ports, service.(webhook.Service).Spec.Ports...)
+			}
+			Expect(ports).To(Equal([]webhook.ServicePort{
+				{Port: 8080, Name: "http", AppProtocol: "http"},
+				{Port: 8080, Name: "http2", AppProtocol: "http2"},
+				{Port: 8080, Name: "http", AppProtocol: "http"},
+			}))
+		})
+	})
+	Context("when the parent selects only some of the routes", func() {
+		It("suffixes the names of the Services with the parent name", func() {
+			template.ParentName = "some-parent"
+			routes := []models.Route{
+				{
+					Guid:   "route-guid-0",
+					Host:   "test0",
+					Domain: models.Domain{Guid: "domain-0-guid", Name: "domain0.example.com"},
+					Destinations: []models.Destination{
+						{
+							Guid: "route-0-destination-guid-0",
+							App:  models.App{Guid: "app-guid-0", Process: models.Process{Type: "web"}},
+							Port: 8080,
+						},
+					},
+				},
+			}
+
+			builder := webhook.ServiceBuilder{}
+			services := builder.Build(routes, template)
+
+			Expect(services).To(HaveLen(1))
+			Expect(services[0].(webhook.Service).Name).To(Equal("s-route-0-destination-guid-0-some-parent"))
+		})
+
+		Context("and the name would get longer than a DNS label", func() {
+			It("suffixes the names of the Services with a hash of the parent name", func() {
+				template.ParentName = "some-parent-with-a-long-name"
+				destination := models.Destination{
+					Guid: "f8a4f6fb-5ec5-4bcb-9ac6-6ba4ad2b4d52",
+					App:  models.App{Guid: "app-guid-0", Process: models.Process{Type: "web"}},
+					Port: 8080,
+				}
+				routes := []models.Route{
+					{
+						Guid:         "route-guid-0",
+						Host:         "test0",
+						Domain:       models.Domain{Guid: "domain-0-guid", Name: "domain0.example.com"},
+						Destinations: []models.Destination{destination},
+					},
+				}
+
+				builder := webhook.ServiceBuilder{}
+				services := builder.Build(routes, template)
+
+				Expect(services).To(HaveLen(1))
+				name := services[0].(webhook.Service).Name
+				Expect(name).To(HavePrefix(webhook.ServiceName(destination) + "-"))
+				Expect(len(name)).To(BeNumerically("<=", 63))
+				Expect(name).To(MatchRegexp(`^[a-z]([-a-z0-9]*[a-z0-9])?$`))
+			})
+		})
+	})
 })
@@ -2,14 +2,27 @@ package webhook
 
 import (
 	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+	"crypto/sha256"
 	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
 )
 
 type K8sResource interface{}
 
 type SyncResponse struct {
+	Status   SyncStatus    `json:"status"`
 	Children []K8sResource `json:"children"`
 }
+
+// SyncStatus is the status metacontroller sets on the parent, the reconciler copies it into the BulkSyncStatus
+type SyncStatus struct {
+	// See BulkSyncStatus.InvalidAnnotations
+	InvalidAnnotations []string `json:"invalidAnnotations,omitempty"`
+}
 type SyncRequest struct {
 	Parent BulkSync `json:"parent"`
 }
@@ -19,6 +32,19 @@ type K8sResourceBuilder interface {
 	Build([]models.Route, Template) []K8sResource
 }
 
+//go:generate counterfeiter -o fakes/k8s_header_policy_builder.go --fake-name K8sHeaderPolicyBuilder . K8sHeaderPolicyBuilder
+
+// K8sHeaderPolicyBuilder is a K8sResourceBuilder that can apply the header policies of the parent to the resources it builds
+type K8sHeaderPolicyBuilder interface {
+	K8sResourceBuilder
+	BuildWithHeaders([]models.Route, Template, HeaderPolicy) []K8sResource
+}
+
+//go:generate counterfeiter -o fakes/k8s_policy_builder.go --fake-name K8sPolicyBuilder . K8sPolicyBuilder
+type K8sPolicyBuilder interface {
+	BuildPolicies([]models.Policy, []models.Route, Template) []K8sResource
+}
+
 //go:generate counterfeiter -o fakes/snapshot_repo.go --fake-name SnapshotRepo . snapshotRepo
 type snapshotRepo interface {
 	Get() (*models.RouteSnapshot, bool)
@@ -29,6 +55,20 @@ var UninitializedError = errors.New("uninitialized: have not yet synchronized wi
 type Lineage struct {
 	RouteSnapshotRepo   snapshotRepo
 	K8sResourceBuilders []K8sResourceBuilder
+	K8sPolicyBuilders   []K8sPolicyBuilder
+
+	staleMutex sync.Mutex
+	stale      bool
+
+	headerPoliciesMutex sync.Mutex
+	// the header policies of the parents by namespace and name, parsed when their headers change
+	headerPolicies map[string]parsedHeaderPolicy
+}
+
+type parsedHeaderPolicy struct {
+	headers VirtualServiceHeaders
+	policy  HeaderPolicy
+	err     error
 }
 
 // Sync generates child resources for a metacontroller /sync request
@@ -37,14 +77,121 @@ func (m *Lineage) Sync(syncRequest SyncRequest) (*SyncResponse, error) {
 	if !ok {
 		return nil, UninitializedError
 	}
+	m.logStaleChange(snapshot)
+	spec := syncRequest.Parent.Spec
+	if err := validateSelector(spec); err != nil {
+		return nil, err
+	}
+	headerPolicy, err := m.headerPolicy(syncRequest.Parent)
+	if err != nil {
+		return nil, fmt.Errorf("invalid headers: %w", err)
+	}
 	children := make([]K8sResource, 0)
+	routes := spec.RouteSelector.Select(snapshot.Routes)
+	if !spec.RouteSelector.isEmpty() {
+		// parents that split the routes between them share the namespace, so their children
+		// are told apart by the name of the parent. A single parent keeps the plain names.
+		spec.Template.ParentName = syncRequest.Parent.Name
+	}
 	for _, builder := range m.K8sResourceBuilders {
-		children = append(children, builder.Build(snapshot.Routes, syncRequest.Parent.Spec.Template)...)
+		if headerPolicyBuilder, ok := builder.(K8sHeaderPolicyBuilder); ok {
+			children = append(children, headerPolicyBuilder.BuildWithHeaders(routes, spec.Template, headerPolicy)...)
+			continue
+		}
+		children = append(children, builder.Build(routes, spec.Template)...)
+	}
+	for _, builder := range m.K8sPolicyBuilders {
+		children = append(children, builder.BuildPolicies(snapshot.Policies, routes, spec.Template)...)
 	}
 
 	response := &SyncResponse{
+		Status:   SyncStatus{InvalidAnnotations: InvalidAnnotations(routes)},
 		Children: children,
 	}
 
 	return response, nil
 }
+
+// maxNameLength is the longest name of a resource, as for DNS subdomains
+const maxNameLength = 253
+
+// maxServiceNameLength is the longest name of a Service, which has to be a DNS-1035 label
+const maxServiceNameLength = 63
+
+// childName suffixes the name of a child with the name of its parent, see Template.ParentName
+func (t Template) childName(name string) string {
+	return t.scopedName(name, maxNameLength)
+}
+
+// serviceName is the name of the Service of a destination among the children of the parent
+func (t Template) serviceName(destination models.Destination) string {
+	return t.scopedName(ServiceName(destination), maxServiceNameLength)
+}
+
+// scopedName suffixes the name with the name of the parent, or with a hash of it
+// when the name would get longer than maxLength
+func (t Template) scopedName(name string, maxLength int) string {
+	if t.ParentName == "" {
+		return name
+	}
+	scoped := fmt.Sprintf("%s-%s", name, t.ParentName)
+	if len(scoped) > maxLength {
+		sum := sha256.Sum256([]byte(t.ParentName))
+		scoped = fmt.Sprintf("%s-%x", name, sum[:8])
+	}
+	return scoped
+}
+
+// validateSelector makes sure that the children match the selector of their parent. The selector does not
+// select routes, that is what the RouteSelector is for. It tells metacontroller which children to adopt,
+// so children that do not match it would be left behind without an owner.
+func validateSelector(spec BulkSyncSpec) error {
+	for key, value := range spec.Selector.MatchLabels {
+		if templateValue, ok := spec.Template.Labels[key]; !ok || templateValue != value {
+			return fmt.Errorf("selector label %s=%s is missing from the template labels, use the routeSelector to select routes", key, value)
+		}
+	}
+	return nil
+}
+
+// headerPolicy returns the parsed headers of the parent. They are only parsed and validated again when they
+// change, so that invalid headers are logged once rather than on every sync.
+func (m *Lineage) headerPolicy(parent BulkSync) (HeaderPolicy, error) {
+	m.headerPoliciesMutex.Lock()
+	defer m.headerPoliciesMutex.Unlock()
+
+	key := parent.Namespace + "/" + parent.Name
+	parsed, ok := m.headerPolicies[key]
+	if ok && reflect.DeepEqual(parsed.headers, parent.Spec.Headers) {
+		return parsed.policy, parsed.err
+	}
+
+	policy, err := ParseHeaderPolicy(parent.Spec.Headers)
+	if err != nil {
+		log.WithError(err).WithFields(log.Fields{
+			"namespace": parent.Namespace,
+			"name":      parent.Name,
+		}).Error("invalid headers of route bulk sync")
+	}
+	if m.headerPolicies == nil {
+		m.headerPolicies = make(map[string]parsedHeaderPolicy)
+	}
+	m.headerPolicies[key] = parsedHeaderPolicy{headers: parent.Spec.Headers, policy: policy, err: err}
+	return policy, err
+}
+
+// logStaleChange logs when the served snapshot becomes or stops being stale, rather than on every sync
+func (m *Lineage) logStaleChange(snapshot *models.RouteSnapshot) {
+	m.staleMutex.Lock()
+	defer m.staleMutex.Unlock()
+
+	if snapshot.Stale == m.stale {
+		return
+	}
+	m.stale = snapshot.Stale
+	if snapshot.Stale {
+		log.WithFields(log.Fields{"generation": snapshot.Generation}).Warn("serving stale snapshot restored from disk")
+	} else {
+		log.WithFields(log.Fields{"generation": snapshot.Generation}).Info("serving up to date snapshot")
+	}
+}
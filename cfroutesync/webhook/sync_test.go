@@ -1,12 +1,16 @@
 package webhook_test
 
 import (
+	"bytes"
+	"strings"
+
 	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
 	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/webhook"
 	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/webhook/fakes"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -114,6 +118,157 @@ var _ = Describe("Sync", func() {
 		Expect(syncResponse.Children).To(Equal(expectedChildren))
 	})
 
+	It("reports the invalid annotations of the routes in the status", func() {
+		fullSnapshot.Routes[0].Annotations = map[string]string{webhook.AnnotationRetries: "potato"}
+
+		syncResponse, err := lineage.Sync(syncRequest)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(syncResponse.Status.InvalidAnnotations).To(HaveLen(1))
+		Expect(syncResponse.Status.InvalidAnnotations[0]).To(HavePrefix("ignoring invalid annotation cfroutesync.cloudfoundry.org/retries 'potato' of route route-guid-0"))
+	})
+
+	Context("when there are policy builders", func() {
+		var fakePolicyBuilder *fakes.K8sPolicyBuilder
+
+		BeforeEach(func() {
+			fullSnapshot.Policies = []models.Policy{
+				{SourceAppGuid: "app-0", DestinationAppGuid: "app-1", Protocol: "tcp", StartPort: 8080, EndPort: 8080},
+			}
+			fakePolicyBuilder = &fakes.K8sPolicyBuilder{}
+			fakePolicyBuilder.BuildPoliciesReturns([]webhook.K8sResource{webhook.NetworkPolicy{Kind: "NetworkPolicy1"}})
+			lineage.K8sPolicyBuilders = []webhook.K8sPolicyBuilder{fakePolicyBuilder}
+		})
+
+		It("appends the resources built from the policies", func() {
+			syncResponse, err := lineage.Sync(syncRequest)
+			Expect(err).ToNot(HaveOccurred())
+
+			policies, routes, template := fakePolicyBuilder.BuildPoliciesArgsForCall(0)
+			Expect(policies).To(Equal(fullSnapshot.Policies))
+			Expect(routes).To(Equal(fullSnapshot.Routes))
+			Expect(template).To(Equal(syncRequest.Parent.Spec.Template))
+
+			Expect(syncResponse.Children).To(HaveLen(5))
+			Expect(syncResponse.Children[4]).To(Equal(webhook.NetworkPolicy{Kind: "NetworkPolicy1"}))
+		})
+	})
+
+	Context("when the parent selects routes", func() {
+		BeforeEach(func() {
+			fullSnapshot.Routes = append(fullSnapshot.Routes, models.Route{
+				Guid:  "route-guid-1",
+				Space: models.Space{Guid: "space-guid-1"},
+			})
+			syncRequest.Parent.Spec.RouteSelector = webhook.RouteSelector{SpaceGuids: []string{"space-guid-1"}}
+			syncRequest.Parent.Name = "some-parent"
+		})
+
+		It("builds the children from the selected routes only", func() {
+			_, err := lineage.Sync(syncRequest)
+			Expect(err).ToNot(HaveOccurred())
+
+			routes, _ := fakeServiceBuilder.BuildArgsForCall(0)
+			Expect(routes).To(Equal(fullSnapshot.Routes[1:]))
+			routes, _ = fakeVirtualServiceBuilder.BuildArgsForCall(0)
+			Expect(routes).To(Equal(fullSnapshot.Routes[1:]))
+		})
+
+		It("has the builders put the parent name in the child names, so that parents in the same namespace do not collide", func() {
+			_, err := lineage.Sync(syncRequest)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, template := fakeServiceBuilder.BuildArgsForCall(0)
+			Expect(template.ParentName).To(Equal("some-parent"))
+			Expect(template.Labels).To(Equal(syncRequest.Parent.Spec.Template.Labels))
+		})
+	})
+
+	Context("when the selector matches the labels of the template", func() {
+		It("builds the children", func() {
+			syncRequest.Parent.Spec.Selector = webhook.Selector{
+				MatchLabels: map[string]string{"cloudfoundry.org/bulk-sync-route": "true"},
+			}
+
+			syncResponse, err := lineage.Sync(syncRequest)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(syncResponse.Children).To(HaveLen(4))
+		})
+	})
+
+	Context("when the selector does not match the labels of the template", func() {
+		It("returns an error rather than building children that the parent does not select", func() {
+			syncRequest.Parent.Spec.Selector = webhook.Selector{
+				MatchLabels: map[string]string{"cloudfoundry.org/bulk-sync-route": "potato"},
+			}
+
+			_, err := lineage.Sync(syncRequest)
+			Expect(err).To(MatchError(ContainSubstring("selector label cloudfoundry.org/bulk-sync-route=potato is missing from the template labels")))
+			Expect(fakeServiceBuilder.BuildCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when a builder applies header policies", func() {
+		var fakeHeaderPolicyBuilder *fakes.K8sHeaderPolicyBuilder
+
+		BeforeEach(func() {
+			syncRequest.Parent.Spec.Headers = webhook.VirtualServiceHeaders{
+				Response: webhook.VirtualServiceHeaderOperations{Remove: []string{"Server"}},
+			}
+			fakeHeaderPolicyBuilder = &fakes.K8sHeaderPolicyBuilder{}
+			fakeHeaderPolicyBuilder.BuildWithHeadersReturns([]webhook.K8sResource{webhook.VirtualService{Kind: "VirtualService3"}})
+			lineage.K8sResourceBuilders = []webhook.K8sResourceBuilder{fakeHeaderPolicyBuilder}
+		})
+
+		It("passes the parsed headers of the parent to it", func() {
+			syncResponse, err := lineage.Sync(syncRequest)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fakeHeaderPolicyBuilder.BuildCallCount()).To(Equal(0))
+
+			routes, template, policy := fakeHeaderPolicyBuilder.BuildWithHeadersArgsForCall(0)
+			Expect(routes).To(Equal(fullSnapshot.Routes))
+			Expect(template).To(Equal(syncRequest.Parent.Spec.Template))
+			expectedPolicy, err := webhook.ParseHeaderPolicy(syncRequest.Parent.Spec.Headers)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy).To(Equal(expectedPolicy))
+			Expect(syncResponse.Children).To(Equal([]webhook.K8sResource{webhook.VirtualService{Kind: "VirtualService3"}}))
+		})
+
+		Context("when the headers are invalid", func() {
+			var logs *bytes.Buffer
+
+			BeforeEach(func() {
+				syncRequest.Parent.Name = "some-parent"
+				syncRequest.Parent.Spec.Headers.Request.Set = map[string]string{"X-Broken": "{{.Route.Host"}
+				logs = &bytes.Buffer{}
+				log.SetOutput(logs)
+			})
+
+			AfterEach(func() {
+				log.SetOutput(GinkgoWriter)
+			})
+
+			It("fails the sync and logs the error only once", func() {
+				_, err := lineage.Sync(syncRequest)
+				Expect(err).To(MatchError(ContainSubstring("invalid headers: request header set 'X-Broken'")))
+				_, err = lineage.Sync(syncRequest)
+				Expect(err).To(MatchError(ContainSubstring("invalid headers: request header set 'X-Broken'")))
+
+				Expect(fakeHeaderPolicyBuilder.BuildWithHeadersCallCount()).To(Equal(0))
+				Expect(strings.Count(logs.String(), "invalid headers of route bulk sync")).To(Equal(1))
+			})
+
+			It("parses the headers again once they are fixed", func() {
+				_, err := lineage.Sync(syncRequest)
+				Expect(err).To(HaveOccurred())
+
+				syncRequest.Parent.Spec.Headers.Request.Set = map[string]string{"X-Fixed": "{{.Route.Host}}"}
+				_, err = lineage.Sync(syncRequest)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeHeaderPolicyBuilder.BuildWithHeadersCallCount()).To(Equal(1))
+			})
+		})
+	})
+
 	Context("when there's snapshot but it does not contain any routes", func() {
 		BeforeEach(func() {
 			fakeSnapshotRepo.GetReturns(&models.RouteSnapshot{}, true)
@@ -129,6 +284,33 @@ var _ = Describe("Sync", func() {
 		})
 	})
 
+	Context("when the snapshot is stale", func() {
+		var logs *bytes.Buffer
+
+		BeforeEach(func() {
+			fullSnapshot.Stale = true
+			logs = &bytes.Buffer{}
+			log.SetOutput(logs)
+		})
+
+		AfterEach(func() {
+			log.SetOutput(GinkgoWriter)
+		})
+
+		It("warns once until the snapshot stops being stale", func() {
+			for i := 0; i < 3; i++ {
+				_, err := lineage.Sync(syncRequest)
+				Expect(err).ToNot(HaveOccurred())
+			}
+			Expect(strings.Count(logs.String(), "serving stale snapshot restored from disk")).To(Equal(1))
+
+			fullSnapshot.Stale = false
+			_, err := lineage.Sync(syncRequest)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(strings.Count(logs.String(), "serving up to date snapshot")).To(Equal(1))
+		})
+	})
+
 	Context("when the repo says no snapshot is available", func() {
 		BeforeEach(func() {
 			fakeSnapshotRepo.GetReturns(nil, false)
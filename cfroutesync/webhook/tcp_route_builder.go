@@ -0,0 +1,139 @@
+package webhook
+
+import (
+	"fmt"
+	"sort"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Name of the Gateway that exposes the ports of all TCP routes
+const TCPGatewayName = "cf-tcp-routes"
+
+// TCPRouteBuilder builds a Gateway with a TCP server for every port that has a TCP route,
+// and a VirtualService per port that forwards the traffic to the route's destinations.
+// The ingress gateway's Service has to expose the ports of the router groups for traffic to arrive.
+type TCPRouteBuilder struct {
+	// Labels of the ingress gateway pods that serve the TCP ports
+	GatewaySelector map[string]string
+}
+
+func (b *TCPRouteBuilder) Build(routes []models.Route, template Template) []K8sResource {
+	routesForPort := GroupTCPRoutesByPort(routes)
+	if len(routesForPort) == 0 {
+		return []K8sResource{}
+	}
+
+	ports := make([]int, 0, len(routesForPort))
+	for port := range routesForPort {
+		ports = append(ports, port)
+	}
+	// Sorting so that the results are stable
+	sort.Ints(ports)
+
+	gateway := Gateway{
+		ApiVersion: "networking.istio.io/v1alpha3",
+		Kind:       "Gateway",
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   template.childName(TCPGatewayName),
+			Labels: cloneLabels(template.ObjectMeta.Labels),
+		},
+		Spec: GatewaySpec{Selector: b.GatewaySelector},
+	}
+	virtualServices := []K8sResource{}
+
+	for _, port := range ports {
+		route := routesForPort[port]
+		tcpDestinations, err := destinationsToTCPRouteDestinations(route, template)
+		if err != nil {
+			log.WithError(err).Errorf("unable to create VirtualService for tcp port %d", port)
+			continue
+		}
+
+		gateway.Spec.Servers = append(gateway.Spec.Servers, GatewayServer{
+			Port: GatewayPort{
+				Number:   port,
+				Name:     fmt.Sprintf("tcp-%d", port),
+				Protocol: "TCP",
+			},
+			Hosts: []string{"*"},
+		})
+
+		virtualServices = append(virtualServices, VirtualService{
+			ApiVersion: "networking.istio.io/v1alpha3",
+			Kind:       "VirtualService",
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   template.childName(TCPVirtualServiceName(port)),
+				Labels: cloneLabels(template.ObjectMeta.Labels),
+				Annotations: map[string]string{
+					"cloudfoundry.org/route": route.Guid,
+					"cloudfoundry.org/url":   route.Url,
+				},
+			},
+			Spec: VirtualServiceSpec{
+				Hosts:    []string{"*"},
+				Gateways: []string{template.childName(TCPGatewayName)},
+				Tcp: []TCPRoute{{
+					Match: []L4MatchAttributes{{Port: port}},
+					Route: tcpDestinations,
+				}},
+			},
+		})
+	}
+
+	if len(gateway.Spec.Servers) == 0 {
+		return []K8sResource{}
+	}
+	return append([]K8sResource{gateway}, virtualServices...)
+}
+
+// GroupTCPRoutesByPort returns the TCP route with destinations for every port.
+// A port can only be reserved by one route, so conflicting routes are logged and skipped.
+func GroupTCPRoutesByPort(routes []models.Route) map[int]models.Route {
+	routesForPort := make(map[int]models.Route)
+	for _, route := range routes {
+		if !route.IsTCP() || len(route.Destinations) == 0 {
+			continue
+		}
+		if existing, ok := routesForPort[route.Port]; ok {
+			if existing.Guid < route.Guid {
+				log.Errorf("tcp routes %s and %s both use port %d, ignoring %s", existing.Guid, route.Guid, route.Port, route.Guid)
+				continue
+			}
+			log.Errorf("tcp routes %s and %s both use port %d, ignoring %s", route.Guid, existing.Guid, route.Port, existing.Guid)
+		}
+		routesForPort[route.Port] = route
+	}
+	return routesForPort
+}
+
+func destinationsToTCPRouteDestinations(route models.Route, template Template) ([]RouteDestination, error) {
+	err := validateWeights(route, route.Destinations)
+	if err != nil {
+		return nil, err
+	}
+
+	tcpDestinations := make([]RouteDestination, 0, len(route.Destinations))
+	for _, destination := range route.Destinations {
+		tcpDestinations = append(tcpDestinations, RouteDestination{
+			Destination: VirtualServiceDestination{
+				Host: template.serviceName(destination),
+				Port: &PortSelector{Number: destination.Port},
+			},
+			Weight: destination.Weight,
+		})
+	}
+	if len(route.Destinations) > 1 && route.Destinations[0].Weight == nil {
+		for i, weight := range evenWeights(len(route.Destinations)) {
+			tcpDestinations[i].Weight = models.IntPtr(weight)
+		}
+	}
+	return tcpDestinations, nil
+}
+
+func TCPVirtualServiceName(port int) string {
+	return fmt.Sprintf("vs-tcp-%d", port)
+}
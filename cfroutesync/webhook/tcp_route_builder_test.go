@@ -0,0 +1,199 @@
+package webhook_test
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/webhook"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("TCPRouteBuilder", func() {
+	var (
+		template webhook.Template
+		builder  webhook.TCPRouteBuilder
+		routes   []models.Route
+	)
+
+	tcpRoute := func(guid string, port int, destinations ...models.Destination) models.Route {
+		return models.Route{
+			Guid:     guid,
+			Url:      fmt.Sprintf("tcp.example.com:%d", port),
+			Protocol: models.ProtocolTCP,
+			Port:     port,
+			Domain: models.Domain{
+				Guid: "tcp-domain-guid",
+				Name: "tcp.example.com",
+			},
+			Destinations: destinations,
+		}
+	}
+
+	BeforeEach(func() {
+		template = testTemplate()
+		builder = webhook.TCPRouteBuilder{
+			GatewaySelector: map[string]string{"istio": "ingressgateway"},
+		}
+		routes = []models.Route{
+			tcpRoute("route-guid-1", 2002, testDestination("dest-1", 5432, nil)),
+			tcpRoute("route-guid-0", 2001,
+				testDestination("dest-0a", 8080, nil),
+				testDestination("dest-0b", 8080, nil),
+				testDestination("dest-0c", 8080, nil),
+			),
+			{
+				Guid:         "http-route-guid",
+				Host:         "www",
+				Protocol:     models.ProtocolHTTP,
+				Domain:       models.Domain{Name: "example.com"},
+				Destinations: []models.Destination{testDestination("dest-http", 8080, nil)},
+			},
+		}
+	})
+
+	It("returns a Gateway with a server per port and a VirtualService per port", func() {
+		resources := builder.Build(routes, template)
+
+		Expect(resources).To(Equal([]webhook.K8sResource{
+			webhook.Gateway{
+				ApiVersion: "networking.istio.io/v1alpha3",
+				Kind:       "Gateway",
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "cf-tcp-routes",
+					Labels: map[string]string{"cloudfoundry.org/bulk-sync-route": "true"},
+				},
+				Spec: webhook.GatewaySpec{
+					Selector: map[string]string{"istio": "ingressgateway"},
+					Servers: []webhook.GatewayServer{
+						{
+							Port:  webhook.GatewayPort{Number: 2001, Name: "tcp-2001", Protocol: "TCP"},
+							Hosts: []string{"*"},
+						},
+						{
+							Port:  webhook.GatewayPort{Number: 2002, Name: "tcp-2002", Protocol: "TCP"},
+							Hosts: []string{"*"},
+						},
+					},
+				},
+			},
+			webhook.VirtualService{
+				ApiVersion: "networking.istio.io/v1alpha3",
+				Kind:       "VirtualService",
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "vs-tcp-2001",
+					Labels: map[string]string{"cloudfoundry.org/bulk-sync-route": "true"},
+					Annotations: map[string]string{
+						"cloudfoundry.org/route": "route-guid-0",
+						"cloudfoundry.org/url":   routes[1].Url,
+					},
+				},
+				Spec: webhook.VirtualServiceSpec{
+					Hosts:    []string{"*"},
+					Gateways: []string{"cf-tcp-routes"},
+					Tcp: []webhook.TCPRoute{{
+						Match: []webhook.L4MatchAttributes{{Port: 2001}},
+						Route: []webhook.RouteDestination{
+							{
+								Destination: webhook.VirtualServiceDestination{Host: "s-dest-0a", Port: &webhook.PortSelector{Number: 8080}},
+								Weight:      models.IntPtr(34),
+							},
+							{
+								Destination: webhook.VirtualServiceDestination{Host: "s-dest-0b", Port: &webhook.PortSelector{Number: 8080}},
+								Weight:      models.IntPtr(33),
+							},
+							{
+								Destination: webhook.VirtualServiceDestination{Host: "s-dest-0c", Port: &webhook.PortSelector{Number: 8080}},
+								Weight:      models.IntPtr(33),
+							},
+						},
+					}},
+				},
+			},
+			webhook.VirtualService{
+				ApiVersion: "networking.istio.io/v1alpha3",
+				Kind:       "VirtualService",
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "vs-tcp-2002",
+					Labels: map[string]string{"cloudfoundry.org/bulk-sync-route": "true"},
+					Annotations: map[string]string{
+						"cloudfoundry.org/route": "route-guid-1",
+						"cloudfoundry.org/url":   routes[0].Url,
+					},
+				},
+				Spec: webhook.VirtualServiceSpec{
+					Hosts:    []string{"*"},
+					Gateways: []string{"cf-tcp-routes"},
+					Tcp: []webhook.TCPRoute{{
+						Match: []webhook.L4MatchAttributes{{Port: 2002}},
+						Route: []webhook.RouteDestination{
+							{
+								Destination: webhook.VirtualServiceDestination{Host: "s-dest-1", Port: &webhook.PortSelector{Number: 5432}},
+							},
+						},
+					}},
+				},
+			},
+		}))
+	})
+
+	Context("when there are no tcp routes with destinations", func() {
+		It("returns nothing, not even the Gateway", func() {
+			routes = []models.Route{routes[2], tcpRoute("route-guid-2", 2003)}
+
+			Expect(builder.Build(routes, template)).To(Equal([]webhook.K8sResource{}))
+		})
+	})
+
+	Context("when the parent selects only some of the routes", func() {
+		It("suffixes the names of the Gateway and of the VirtualServices with the parent name", func() {
+			template.ParentName = "some-parent"
+
+			resources := builder.Build(routes, template)
+
+			Expect(resources).To(HaveLen(3))
+			Expect(resources[0].(webhook.Gateway).Name).To(Equal("cf-tcp-routes-some-parent"))
+			vs := resources[1].(webhook.VirtualService)
+			Expect(vs.Name).To(Equal("vs-tcp-2001-some-parent"))
+			Expect(vs.Spec.Gateways).To(Equal([]string{"cf-tcp-routes-some-parent"}))
+			Expect(vs.Spec.Tcp[0].Route[0].Destination.Host).To(HaveSuffix("-some-parent"))
+		})
+	})
+
+	Context("when two routes use the same port", func() {
+		It("keeps the route with the lowest guid", func() {
+			routes = []models.Route{
+				tcpRoute("route-guid-b", 2001, testDestination("dest-b", 8080, nil)),
+				tcpRoute("route-guid-a", 2001, testDestination("dest-a", 8080, nil)),
+			}
+
+			resources := builder.Build(routes, template)
+
+			Expect(resources).To(HaveLen(2))
+			vs := resources[1].(webhook.VirtualService)
+			Expect(vs.Annotations["cloudfoundry.org/route"]).To(Equal("route-guid-a"))
+		})
+	})
+
+	Context("when the weights of a route are invalid", func() {
+		It("skips the port", func() {
+			routes = []models.Route{
+				tcpRoute("route-guid-0", 2001,
+					testDestination("dest-0a", 8080, models.IntPtr(10)),
+					testDestination("dest-0b", 8080, models.IntPtr(10)),
+				),
+				tcpRoute("route-guid-1", 2002, testDestination("dest-1", 5432, nil)),
+			}
+
+			resources := builder.Build(routes, template)
+
+			Expect(resources).To(HaveLen(2))
+			gateway := resources[0].(webhook.Gateway)
+			Expect(gateway.Spec.Servers).To(HaveLen(1))
+			Expect(gateway.Spec.Servers[0].Port.Number).To(Equal(2002))
+			Expect(resources[1].(webhook.VirtualService).Name).To(Equal("vs-tcp-2002"))
+		})
+	})
+})
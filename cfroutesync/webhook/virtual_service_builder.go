@@ -2,10 +2,14 @@ package webhook
 
 import (
 	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/routeservice"
 	"crypto/sha256"
 	"errors"
 	"fmt"
+	"net/url"
 	"sort"
+	"strconv"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -19,20 +23,39 @@ const MeshInternalGateway = "mesh"
 // https://istio.io/docs/concepts/traffic-management/
 const IstioExpectedWeight = int(100)
 
+// Name of the http routes that send requests to a route service. The ingress gateway only checks
+// the requests of routes with this name with cfroutesync, see istio-routeservice.yaml
+const RouteServiceRouteName = "cf-route-service"
+
 type VirtualServiceBuilder struct {
 	IstioGateways []string
+
+	// Attach to the Gateway of the HTTPSGatewayBuilder as well
+	HTTPSGateway bool
+
+	// Route requests through the route services bound to routes. The ingress gateway signs the requests
+	// and verifies the ones the route services send back, see routeservice.CheckHandler. Routes bound to
+	// a route service are skipped without it, so that traffic never bypasses the route service.
+	RouteServices bool
 }
 
 func (b *VirtualServiceBuilder) Build(routes []models.Route, template Template) []K8sResource {
+	return b.BuildWithHeaders(routes, template, HeaderPolicy{})
+}
+
+// BuildWithHeaders builds the VirtualServices with the headers of the parent added to every destination.
+// The CF-* request headers always take precedence, so that apps can trust them.
+func (b *VirtualServiceBuilder) BuildWithHeaders(routes []models.Route, template Template, policy HeaderPolicy) []K8sResource {
 	resources := []K8sResource{}
 
-	routesForFQDN := groupByFQDN(routes)
+	// TCP routes are built by the TCPRouteBuilder
+	routesForFQDN := groupByFQDN(httpRoutes(routes))
 	sortedFQDNs := sortFQDNs(routesForFQDN)
 
 	for _, fqdn := range sortedFQDNs {
 		destinations := destinationsForFQDN(fqdn, routesForFQDN)
 		if len(destinations) != 0 {
-			virtualService, err := b.fqdnToVirtualService(fqdn, routesForFQDN[fqdn], template)
+			virtualService, err := b.fqdnToVirtualService(fqdn, RoutesForFQDN(fqdn, routesForFQDN), template, policy)
 			if err == nil {
 				resources = append(resources, virtualService)
 			} else {
@@ -44,12 +67,12 @@ func (b *VirtualServiceBuilder) Build(routes []models.Route, template Template)
 	return resources
 }
 
-func (b *VirtualServiceBuilder) fqdnToVirtualService(fqdn string, routes []models.Route, template Template) (VirtualService, error) {
+func (b *VirtualServiceBuilder) fqdnToVirtualService(fqdn string, routes []models.Route, template Template, headers HeaderPolicy) (VirtualService, error) {
 	vs := VirtualService{
 		ApiVersion: "networking.istio.io/v1alpha3",
 		Kind:       "VirtualService",
 		ObjectMeta: metav1.ObjectMeta{
-			Name:   VirtualServiceName(fqdn),
+			Name:   template.childName(VirtualServiceName(fqdn)),
 			Labels: cloneLabels(template.ObjectMeta.Labels),
 			Annotations: map[string]string{
 				"cloudfoundry.org/fqdn": fqdn,
@@ -66,31 +89,127 @@ func (b *VirtualServiceBuilder) fqdnToVirtualService(fqdn string, routes []model
 	if routes[0].Domain.Internal {
 		vs.Spec.Gateways = []string{MeshInternalGateway}
 	} else {
-		vs.Spec.Gateways = b.IstioGateways
+		vs.Spec.Gateways = append([]string{}, b.IstioGateways...)
+		if b.HTTPSGateway {
+			vs.Spec.Gateways = append(vs.Spec.Gateways, template.childName(HTTPSGatewayName))
+		}
 	}
 
-	sortRoutes(routes)
-
 	for _, route := range routes {
 		if len(route.Destinations) != 0 {
-			istioDestinations, err := destinationsToHttpRouteDestinations(route, route.Destinations)
+			istioDestinations, err := destinationsToHttpRouteDestinations(route, route.Destinations, template, headers)
 			if err != nil {
 				return VirtualService{}, err
 			}
 
+			policy := AnnotationPolicy(route)
+
+			if route.RouteServiceUrl != "" {
+				routeServiceRoutes, err := b.routeServiceRoutes(route, istioDestinations)
+				if err != nil {
+					log.WithError(err).Errorf("skipping route %s of fqdn '%s'", route.Guid, fqdn)
+					continue
+				}
+				for i := range routeServiceRoutes {
+					policy.apply(&routeServiceRoutes[i])
+				}
+				vs.Spec.Http = append(vs.Spec.Http, routeServiceRoutes...)
+				continue
+			}
+
 			istioRoute := HTTPRoute{
 				Route: istioDestinations,
 			}
 			if route.Path != "" {
-				istioRoute.Match = []HTTPMatchRequest{{Uri: HTTPPrefixMatch{Prefix: route.Path}}}
+				istioRoute.Match = pathMatches(route.Path)
 			}
+			policy.apply(&istioRoute)
 			vs.Spec.Http = append(vs.Spec.Http, istioRoute)
 		}
 	}
 
+	if len(vs.Spec.Http) == 0 {
+		return VirtualService{}, errors.New("no route of the fqdn can be routed")
+	}
+
 	return vs, nil
 }
 
+// routeServiceRoutes sends requests to the route service first. Only the requests that the route service
+// forwards back with a valid signature are marked as verified by the ingress gateway, and go on to the
+// destinations of the route. The gateway adds the signature headers to the requests to the route service.
+func (b *VirtualServiceBuilder) routeServiceRoutes(route models.Route, destinations []HTTPRouteDestination) ([]HTTPRoute, error) {
+	if !b.RouteServices {
+		return nil, fmt.Errorf("route %s is bound to a route service, but no route service secret is configured", route.Guid)
+	}
+
+	routeServiceUrl, err := parseRouteServiceUrl(route.RouteServiceUrl)
+	if err != nil {
+		return nil, fmt.Errorf("route %s: %w", route.Guid, err)
+	}
+
+	verifiedMatches := []HTTPMatchRequest{{Uri: StringMatch{Prefix: "/"}}}
+	if route.Path != "" {
+		verifiedMatches = pathMatches(route.Path)
+	}
+	for i := range verifiedMatches {
+		verifiedMatches[i].Headers = map[string]StringMatch{
+			routeservice.VerifiedHeader: {Exact: "true"},
+		}
+	}
+
+	toRouteService := HTTPRoute{
+		Name: RouteServiceRouteName,
+		Rewrite: &HTTPRewrite{
+			Uri:       routeServiceUrl.RequestURI(),
+			Authority: routeServiceUrl.Host,
+		},
+		Route: []HTTPRouteDestination{
+			{
+				Destination: VirtualServiceDestination{
+					Host: routeServiceUrl.Hostname(),
+					Port: &PortSelector{Number: routeServicePort(routeServiceUrl)},
+				},
+			},
+		},
+	}
+	if route.Path != "" {
+		toRouteService.Match = pathMatches(route.Path)
+	}
+
+	return []HTTPRoute{{Match: verifiedMatches, Route: destinations}, toRouteService}, nil
+}
+
+func parseRouteServiceUrl(rawUrl string) (*url.URL, error) {
+	routeServiceUrl, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid route service url: %w", err)
+	}
+	if routeServiceUrl.Scheme != "https" && routeServiceUrl.Scheme != "http" {
+		return nil, fmt.Errorf("invalid route service url %s: scheme must be https or http", rawUrl)
+	}
+	return routeServiceUrl, nil
+}
+
+func routeServicePort(routeServiceUrl *url.URL) int {
+	// url.Parse has validated that the port is numeric
+	if number, err := strconv.Atoi(routeServiceUrl.Port()); err == nil {
+		return number
+	}
+	if routeServiceUrl.Scheme == "http" {
+		return 80
+	}
+	return 443
+}
+
+// pathMatches matches the path the way gorouter does: the route /foo matches /foo and /foo/bar, but not /foobar
+func pathMatches(path string) []HTTPMatchRequest {
+	return []HTTPMatchRequest{
+		{Uri: StringMatch{Exact: path}},
+		{Uri: StringMatch{Prefix: path + "/"}},
+	}
+}
+
 func destinationsForFQDN(fqdn string, routesByFQDN map[string][]models.Route) []models.Destination {
 	destinations := make([]models.Destination, 0)
 	routes := routesByFQDN[fqdn]
@@ -100,6 +219,16 @@ func destinationsForFQDN(fqdn string, routesByFQDN map[string][]models.Route) []
 	return destinations
 }
 
+func httpRoutes(routes []models.Route) []models.Route {
+	var filtered []models.Route
+	for _, route := range routes {
+		if !route.IsTCP() {
+			filtered = append(filtered, route)
+		}
+	}
+	return filtered
+}
+
 func groupByFQDN(routes []models.Route) map[string][]models.Route {
 	fqdns := make(map[string][]models.Route)
 	for _, route := range routes {
@@ -119,12 +248,78 @@ func sortFQDNs(fqdns map[string][]models.Route) []string {
 	return fqdnSlice
 }
 
+// RoutesForFQDN returns the routes of an fqdn followed by the wildcard routes it falls back to,
+// in the order they have to be matched in
+func RoutesForFQDN(fqdn string, routesByFQDN map[string][]models.Route) []models.Route {
+	routes := append([]models.Route{}, routesByFQDN[fqdn]...)
+	routes = append(routes, wildcardFallbackRoutes(fqdn, routesByFQDN)...)
+	sortRoutes(routes)
+	return routes
+}
+
+// sortRoutes orders the routes of an fqdn so that the longest path matches first,
+// and routes of the fqdn itself match before the wildcard routes it falls back to
 func sortRoutes(routes []models.Route) {
 	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].IsWildcard() != routes[j].IsWildcard() {
+			return !routes[i].IsWildcard()
+		}
 		return routes[i].Url > routes[j].Url
 	})
 }
 
+// wildcardFQDN returns the fqdn of the wildcard routes that gorouter falls back to for an fqdn,
+// e.g. *.example.com for app.example.com
+func wildcardFQDN(fqdn string) string {
+	dot := strings.Index(fqdn, ".")
+	if dot == -1 {
+		return ""
+	}
+	return models.WildcardHost + fqdn[dot:]
+}
+
+// wildcardFallbackRoutes returns the wildcard routes covering an fqdn that requests fall back to
+// when no path of the fqdn's own routes matches, like gorouter does. Istio picks the VirtualService
+// with the most specific host, so the wildcard routes have to be repeated in the VirtualService of the fqdn.
+// Wildcard routes that are shadowed by a route of the fqdn with the same path, or that disagree
+// on whether the domain is internal, are logged and left out.
+func wildcardFallbackRoutes(fqdn string, routesByFQDN map[string][]models.Route) []models.Route {
+	wildcard := wildcardFQDN(fqdn)
+	if wildcard == "" || wildcard == fqdn {
+		return nil
+	}
+
+	routes := routesByFQDN[fqdn]
+	paths := map[string]models.Route{}
+	for _, route := range routes {
+		if len(route.Destinations) != 0 {
+			paths[route.Path] = route
+		}
+	}
+	if _, ok := paths[""]; ok {
+		// the route without a path matches every request for the fqdn
+		return nil
+	}
+
+	var fallbacks []models.Route
+	for _, wildcardRoute := range routesByFQDN[wildcard] {
+		if len(wildcardRoute.Destinations) == 0 {
+			continue
+		}
+		if wildcardRoute.Domain.Internal != routes[0].Domain.Internal {
+			log.Errorf("wildcard route %s and route %s overlap on fqdn %s but disagree on whether or not the domain is internal, ignoring %s",
+				wildcardRoute.Guid, routes[0].Guid, fqdn, wildcardRoute.Guid)
+			continue
+		}
+		if route, ok := paths[wildcardRoute.Path]; ok {
+			log.Warnf("route %s shadows wildcard route %s on fqdn %s for path '%s'", route.Guid, wildcardRoute.Guid, fqdn, route.Path)
+			continue
+		}
+		fallbacks = append(fallbacks, wildcardRoute)
+	}
+	return fallbacks
+}
+
 func cloneLabels(template map[string]string) map[string]string {
 	labels := make(map[string]string)
 	for k, v := range template {
@@ -133,7 +328,7 @@ func cloneLabels(template map[string]string) map[string]string {
 	return labels
 }
 
-func destinationsToHttpRouteDestinations(route models.Route, destinations []models.Destination) ([]HTTPRouteDestination, error) {
+func destinationsToHttpRouteDestinations(route models.Route, destinations []models.Destination, template Template, headers HeaderPolicy) ([]HTTPRouteDestination, error) {
 	err := validateWeights(route, destinations)
 	if err != nil {
 		return nil, err
@@ -142,18 +337,15 @@ func destinationsToHttpRouteDestinations(route models.Route, destinations []mode
 	for _, destination := range destinations {
 		httpDestination := HTTPRouteDestination{
 			Destination: VirtualServiceDestination{
-				Host: serviceName(destination),
-			},
-			Headers: VirtualServiceHeaders{
-				Request: VirtualServiceHeaderOperations{
-					Set: map[string]string{
-						"CF-App-Id":           destination.App.Guid,
-						"CF-App-Process-Type": destination.App.Process.Type,
-						"CF-Space-Id":         route.Space.Guid,
-						"CF-Organization-Id":  route.Space.Organization.Guid,
-					},
-				},
+				Host: template.serviceName(destination),
 			},
+			Headers: headers.render(route, destination),
+		}
+		if httpDestination.Headers.Request.Set == nil {
+			httpDestination.Headers.Request.Set = map[string]string{}
+		}
+		for _, header := range DestinationHeaders(route, destination) {
+			httpDestination.Headers.Request.Set[header.Name] = header.Value
 		}
 		if destination.Weight != nil {
 			httpDestination.Weight = destination.Weight
@@ -161,20 +353,38 @@ func destinationsToHttpRouteDestinations(route models.Route, destinations []mode
 		httpDestinations = append(httpDestinations, httpDestination)
 	}
 	if len(destinations) > 1 && destinations[0].Weight == nil {
-		n := len(destinations)
-		for i, _ := range httpDestinations {
-			weight := int(IstioExpectedWeight / n)
-			if i == 0 {
-				// pad the first destination's weight to ensure all weights sum to 100
-				remainder := IstioExpectedWeight - n*weight
-				weight += remainder
-			}
+		for i, weight := range evenWeights(len(destinations)) {
 			httpDestinations[i].Weight = models.IntPtr(weight)
 		}
 	}
 	return httpDestinations, nil
 }
 
+// DestinationHeaders are the CF headers set on every request routed to the destination of a route
+func DestinationHeaders(route models.Route, destination models.Destination) []HTTPHeader {
+	return []HTTPHeader{
+		{Name: "CF-App-Id", Value: destination.App.Guid},
+		{Name: "CF-App-Process-Type", Value: destination.App.Process.Type},
+		{Name: "CF-Space-Id", Value: route.Space.Guid},
+		{Name: "CF-Organization-Id", Value: route.Space.Organization.Guid},
+	}
+}
+
+// evenWeights splits 100% across n destinations
+func evenWeights(n int) []int {
+	weights := make([]int, n)
+	for i := range weights {
+		weight := int(IstioExpectedWeight / n)
+		if i == 0 {
+			// pad the first destination's weight to ensure all weights sum to 100
+			remainder := IstioExpectedWeight - n*weight
+			weight += remainder
+		}
+		weights[i] = weight
+	}
+	return weights
+}
+
 func validateWeights(route models.Route, destinations []models.Destination) error {
 	// Cloud Controller validates these scenarios
 	//
@@ -220,6 +430,12 @@ func validateRoutesForFQDN(routes []models.Route) error {
 
 // virtual service names cannot contain special characters
 func VirtualServiceName(fqdn string) string {
-	sum := sha256.Sum256([]byte(fqdn))
-	return fmt.Sprintf("vs-%x", sum)
+	return hashedName("vs", fqdn)
+}
+
+// hashedName returns a valid resource name for a value such as an fqdn, made of
+// the prefix and the sha256 of the value
+func hashedName(prefix, value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return fmt.Sprintf("%s-%x", prefix, sum)
 }
@@ -1,6 +1,8 @@
 package webhook_test
 
 import (
+	"encoding/json"
+
 	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
 	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/webhook"
 	"fmt"
@@ -115,7 +117,10 @@ var _ = Describe("VirtualServiceBuilder", func() {
 					Gateways: []string{"some-gateway0", "some-gateway1"},
 					Http: []webhook.HTTPRoute{
 						{
-							Match: []webhook.HTTPMatchRequest{{Uri: webhook.HTTPPrefixMatch{Prefix: "/path0"}}},
+							Match: []webhook.HTTPMatchRequest{
+								{Uri: webhook.StringMatch{Exact: "/path0"}},
+								{Uri: webhook.StringMatch{Prefix: "/path0/"}},
+							},
 							Route: []webhook.HTTPRouteDestination{
 								{
 									Destination: webhook.VirtualServiceDestination{Host: "s-route-0-destination-guid-0"},
@@ -271,7 +276,10 @@ var _ = Describe("VirtualServiceBuilder", func() {
 								Gateways: []string{"some-gateway0", "some-gateway1"},
 								Http: []webhook.HTTPRoute{
 									{
-										Match: []webhook.HTTPMatchRequest{{Uri: webhook.HTTPPrefixMatch{Prefix: "/path0"}}},
+										Match: []webhook.HTTPMatchRequest{
+											{Uri: webhook.StringMatch{Exact: "/path0"}},
+											{Uri: webhook.StringMatch{Prefix: "/path0/"}},
+										},
 										Route: []webhook.HTTPRouteDestination{
 											{
 												Destination: webhook.VirtualServiceDestination{Host: "s-route-0-destination-guid-0"},
@@ -371,7 +379,10 @@ var _ = Describe("VirtualServiceBuilder", func() {
 								Gateways: []string{"some-gateway0", "some-gateway1"},
 								Http: []webhook.HTTPRoute{
 									{
-										Match: []webhook.HTTPMatchRequest{{Uri: webhook.HTTPPrefixMatch{Prefix: "/path0"}}},
+										Match: []webhook.HTTPMatchRequest{
+											{Uri: webhook.StringMatch{Exact: "/path0"}},
+											{Uri: webhook.StringMatch{Prefix: "/path0/"}},
+										},
 										Route: []webhook.HTTPRouteDestination{
 											{
 												Destination: webhook.VirtualServiceDestination{Host: "s-route-0-destination-guid-0"},
@@ -442,7 +453,10 @@ var _ = Describe("VirtualServiceBuilder", func() {
 								Gateways: []string{"some-gateway0", "some-gateway1"},
 								Http: []webhook.HTTPRoute{
 									{
-										Match: []webhook.HTTPMatchRequest{{Uri: webhook.HTTPPrefixMatch{Prefix: "/path0"}}},
+										Match: []webhook.HTTPMatchRequest{
+											{Uri: webhook.StringMatch{Exact: "/path0"}},
+											{Uri: webhook.StringMatch{Prefix: "/path0/"}},
+										},
 										Route: []webhook.HTTPRouteDestination{
 											{
 												Destination: webhook.VirtualServiceDestination{Host: "s-route-0-destination-guid-0"},
@@ -560,7 +574,10 @@ var _ = Describe("VirtualServiceBuilder", func() {
 								Gateways: []string{"some-gateway0", "some-gateway1"},
 								Http: []webhook.HTTPRoute{
 									{
-										Match: []webhook.HTTPMatchRequest{{Uri: webhook.HTTPPrefixMatch{Prefix: "/path0"}}},
+										Match: []webhook.HTTPMatchRequest{
+											{Uri: webhook.StringMatch{Exact: "/path0"}},
+											{Uri: webhook.StringMatch{Prefix: "/path0/"}},
+										},
 										Route: []webhook.HTTPRouteDestination{
 											{
 												Destination: webhook.VirtualServiceDestination{Host: "s-route-0-destination-guid-0"},
@@ -678,7 +695,10 @@ var _ = Describe("VirtualServiceBuilder", func() {
 								Gateways: []string{"some-gateway0", "some-gateway1"},
 								Http: []webhook.HTTPRoute{
 									{
-										Match: []webhook.HTTPMatchRequest{{Uri: webhook.HTTPPrefixMatch{Prefix: "/path0"}}},
+										Match: []webhook.HTTPMatchRequest{
+											{Uri: webhook.StringMatch{Exact: "/path0"}},
+											{Uri: webhook.StringMatch{Prefix: "/path0/"}},
+										},
 										Route: []webhook.HTTPRouteDestination{
 											{
 												Destination: webhook.VirtualServiceDestination{Host: "s-route-0-destination-guid-0"},
@@ -900,7 +920,10 @@ var _ = Describe("VirtualServiceBuilder", func() {
 						Gateways: []string{"some-gateway0", "some-gateway1"},
 						Http: []webhook.HTTPRoute{
 							{
-								Match: []webhook.HTTPMatchRequest{{Uri: webhook.HTTPPrefixMatch{Prefix: "/path0/deeper"}}},
+								Match: []webhook.HTTPMatchRequest{
+									{Uri: webhook.StringMatch{Exact: "/path0/deeper"}},
+									{Uri: webhook.StringMatch{Prefix: "/path0/deeper/"}},
+								},
 								Route: []webhook.HTTPRouteDestination{
 									{
 										Destination: webhook.VirtualServiceDestination{Host: "s-route-1-destination-guid-0"},
@@ -919,7 +942,10 @@ var _ = Describe("VirtualServiceBuilder", func() {
 								},
 							},
 							{
-								Match: []webhook.HTTPMatchRequest{{Uri: webhook.HTTPPrefixMatch{Prefix: "/path0"}}},
+								Match: []webhook.HTTPMatchRequest{
+									{Uri: webhook.StringMatch{Exact: "/path0"}},
+									{Uri: webhook.StringMatch{Prefix: "/path0/"}},
+								},
 								Route: []webhook.HTTPRouteDestination{
 									{
 										Destination: webhook.VirtualServiceDestination{Host: "s-route-0-destination-guid-0"},
@@ -1018,7 +1044,10 @@ var _ = Describe("VirtualServiceBuilder", func() {
 							Gateways: []string{"some-gateway0", "some-gateway1"},
 							Http: []webhook.HTTPRoute{
 								{
-									Match: []webhook.HTTPMatchRequest{{Uri: webhook.HTTPPrefixMatch{Prefix: "/path0"}}},
+									Match: []webhook.HTTPMatchRequest{
+										{Uri: webhook.StringMatch{Exact: "/path0"}},
+										{Uri: webhook.StringMatch{Prefix: "/path0/"}},
+									},
 									Route: []webhook.HTTPRouteDestination{
 										{
 											Destination: webhook.VirtualServiceDestination{Host: "s-route-0-destination-guid-0"},
@@ -1216,6 +1245,38 @@ var _ = Describe("VirtualServiceBuilder", func() {
 		})
 	})
 
+	Context("when a route is a tcp route", func() {
+		It("does not create a VirtualService, the TCPRouteBuilder does", func() {
+			routes := []models.Route{
+				models.Route{
+					Guid:     "route-guid-0",
+					Url:      "tcp.example.com:1234",
+					Protocol: models.ProtocolTCP,
+					Port:     1234,
+					Domain: models.Domain{
+						Guid: "domain-0-guid",
+						Name: "tcp.example.com",
+					},
+					Destinations: []models.Destination{
+						models.Destination{
+							Guid: "route-0-destination-guid-0",
+							App: models.App{
+								Guid:    "app-guid-0",
+								Process: models.Process{Type: "process-type-1"},
+							},
+							Port: 5432,
+						},
+					},
+				},
+			}
+
+			builder := webhook.VirtualServiceBuilder{
+				IstioGateways: []string{"some-gateway0", "some-gateway1"},
+			}
+			Expect(builder.Build(routes, template)).To(Equal([]webhook.K8sResource{}))
+		})
+	})
+
 	Context("when a destination has no weight", func() {
 		It("omits weight on the VirtualService", func() {
 			routes := []models.Route{
@@ -1297,6 +1358,311 @@ var _ = Describe("VirtualServiceBuilder", func() {
 			Expect(builder.Build(routes, template)).To(Equal(expectedVirtualServices))
 		})
 	})
+
+	Context("when there are wildcard routes", func() {
+		var (
+			routes  []models.Route
+			builder webhook.VirtualServiceBuilder
+		)
+
+		route := func(guid, host, path string, internal bool) models.Route {
+			domain := "example.com"
+			if internal {
+				domain = "apps.internal"
+			}
+			return models.Route{
+				Guid:         guid,
+				Host:         host,
+				Path:         path,
+				Url:          host + "." + domain + path,
+				Domain:       models.Domain{Name: domain, Internal: internal},
+				Destinations: []models.Destination{{Guid: "dest-" + guid, Port: 8080}},
+			}
+		}
+
+		httpRouteHosts := func(vs webhook.VirtualService) []string {
+			hosts := []string{}
+			for _, httpRoute := range vs.Spec.Http {
+				hosts = append(hosts, httpRoute.Route[0].Destination.Host)
+			}
+			return hosts
+		}
+
+		BeforeEach(func() {
+			builder = webhook.VirtualServiceBuilder{IstioGateways: []string{"some-gateway0"}}
+			routes = []models.Route{
+				route("wildcard-root", "*", "", false),
+				route("wildcard-path", "*", "/path", false),
+				route("specific-path", "app", "/path", false),
+				route("specific-other", "app", "/other", false),
+			}
+		})
+
+		It("uses the wildcard fqdn as host", func() {
+			resources := builder.Build(routes, template)
+			Expect(resources).To(HaveLen(2))
+			wildcardVS := resources[0].(webhook.VirtualService)
+			Expect(wildcardVS.Name).To(Equal(webhook.VirtualServiceName("*.example.com")))
+			Expect(wildcardVS.Spec.Hosts).To(Equal([]string{"*.example.com"}))
+			Expect(httpRouteHosts(wildcardVS)).To(Equal([]string{"s-dest-wildcard-path", "s-dest-wildcard-root"}))
+		})
+
+		It("falls back to the wildcard routes after the routes of the specific host", func() {
+			resources := builder.Build(routes, template)
+			Expect(resources).To(HaveLen(2))
+			specificVS := resources[1].(webhook.VirtualService)
+			Expect(specificVS.Spec.Hosts).To(Equal([]string{"app.example.com"}))
+			Expect(httpRouteHosts(specificVS)).To(Equal([]string{
+				"s-dest-specific-path",
+				"s-dest-specific-other",
+				"s-dest-wildcard-root",
+			}))
+		})
+
+		Context("when the specific host has a route without a path", func() {
+			BeforeEach(func() {
+				routes = append(routes, route("specific-root", "app", "", false))
+			})
+
+			It("does not fall back to the wildcard routes", func() {
+				resources := builder.Build(routes, template)
+				Expect(resources).To(HaveLen(2))
+				Expect(httpRouteHosts(resources[1].(webhook.VirtualService))).To(Equal([]string{
+					"s-dest-specific-path",
+					"s-dest-specific-other",
+					"s-dest-specific-root",
+				}))
+			})
+		})
+
+		Context("when the wildcard route is internal and the specific route is not", func() {
+			BeforeEach(func() {
+				routes = []models.Route{
+					route("wildcard-root", "*", "", true),
+					route("specific-path", "app", "/path", false),
+				}
+				routes[0].Domain.Name = "example.com"
+				routes[0].Url = "*.example.com"
+			})
+
+			It("does not fall back to the conflicting wildcard route", func() {
+				resources := builder.Build(routes, template)
+				Expect(resources).To(HaveLen(2))
+				specificVS := resources[1].(webhook.VirtualService)
+				Expect(specificVS.Spec.Gateways).To(Equal([]string{"some-gateway0"}))
+				Expect(httpRouteHosts(specificVS)).To(Equal([]string{"s-dest-specific-path"}))
+			})
+		})
+	})
+
+	Context("when the HTTPS gateway is built", func() {
+		var routes []models.Route
+
+		BeforeEach(func() {
+			routes = []models.Route{
+				{
+					Guid:         "route-guid-0",
+					Host:         "test0",
+					Url:          "test0.domain0.example.com",
+					Domain:       models.Domain{Guid: "domain-0-guid", Name: "domain0.example.com"},
+					Destinations: []models.Destination{{Guid: "destination-guid-0", Port: 8080}},
+				},
+			}
+		})
+
+		It("attaches the VirtualServices to it", func() {
+			builder := webhook.VirtualServiceBuilder{IstioGateways: []string{"some-gateway0"}, HTTPSGateway: true}
+
+			vs := builder.Build(routes, template)[0].(webhook.VirtualService)
+
+			Expect(vs.Name).To(Equal(webhook.VirtualServiceName("test0.domain0.example.com")))
+			Expect(vs.Spec.Gateways).To(Equal([]string{"some-gateway0", "cf-https-domains"}))
+		})
+
+		Context("and the parent selects only some of the routes", func() {
+			It("suffixes the names of the VirtualService and of the Gateway with the parent name", func() {
+				builder := webhook.VirtualServiceBuilder{IstioGateways: []string{"some-gateway0"}, HTTPSGateway: true}
+				template.ParentName = "some-parent"
+
+				vs := builder.Build(routes, template)[0].(webhook.VirtualService)
+
+				Expect(vs.Name).To(Equal(webhook.VirtualServiceName("test0.domain0.example.com") + "-some-parent"))
+				Expect(vs.Spec.Gateways).To(Equal([]string{"some-gateway0", "cf-https-domains-some-parent"}))
+				Expect(vs.Spec.Http[0].Route[0].Destination.Host).To(HaveSuffix("-some-parent"))
+			})
+
+			Context("and the name would get too long", func() {
+				It("suffixes the names with a hash of the parent name", func() {
+					builder := webhook.VirtualServiceBuilder{IstioGateways: []string{"some-gateway0"}}
+					template.ParentName = strings.Repeat("a", 250)
+
+					vs := builder.Build(routes, template)[0].(webhook.VirtualService)
+
+					Expect(vs.Name).To(HavePrefix(webhook.VirtualServiceName("test0.domain0.example.com") + "-"))
+					Expect(len(vs.Name)).To(Equal(len(webhook.VirtualServiceName("test0.domain0.example.com")) + 17))
+					Expect(len(vs.Spec.Http[0].Route[0].Destination.Host)).To(BeNumerically("<=", 63))
+				})
+			})
+		})
+	})
+
+	Context("when a route is bound to a route service", func() {
+		var (
+			routes  []models.Route
+			builder webhook.VirtualServiceBuilder
+		)
+
+		BeforeEach(func() {
+			routes = []models.Route{
+				{
+					Guid:            "route-guid-0",
+					Host:            "test0",
+					Path:            "/path0",
+					Url:             "test0.domain0.example.com/path0",
+					RouteServiceUrl: "https://route-service.example.com/auth?tenant=0",
+					Domain:          models.Domain{Guid: "domain-0-guid", Name: "domain0.example.com"},
+					Space:           models.Space{Guid: "space-guid-0", Organization: models.Organization{Guid: "org-guid-0"}},
+					Destinations: []models.Destination{
+						{
+							Guid: "route-0-destination-guid-0",
+							App:  models.App{Guid: "app-guid-0", Process: models.Process{Type: "web"}},
+							Port: 8080,
+						},
+					},
+				},
+				{
+					Guid:   "route-guid-1",
+					Host:   "test0",
+					Url:    "test0.domain0.example.com",
+					Domain: models.Domain{Guid: "domain-0-guid", Name: "domain0.example.com"},
+					Space:  models.Space{Guid: "space-guid-0", Organization: models.Organization{Guid: "org-guid-0"}},
+					Destinations: []models.Destination{
+						{
+							Guid: "route-1-destination-guid-0",
+							App:  models.App{Guid: "app-guid-1", Process: models.Process{Type: "web"}},
+							Port: 8080,
+						},
+					},
+				},
+			}
+			builder = webhook.VirtualServiceBuilder{
+				IstioGateways: []string{"some-gateway0"},
+				RouteServices: true,
+			}
+		})
+
+		It("sends requests through the route service and only verified requests to the destinations", func() {
+			resources := builder.Build(routes, template)
+
+			Expect(resources).To(HaveLen(1))
+			http := resources[0].(webhook.VirtualService).Spec.Http
+			Expect(http).To(HaveLen(3))
+
+			verifiedOnly := map[string]webhook.StringMatch{"x-cf-route-service-verified": {Exact: "true"}}
+			Expect(http[0].Match).To(Equal([]webhook.HTTPMatchRequest{
+				{Uri: webhook.StringMatch{Exact: "/path0"}, Headers: verifiedOnly},
+				{Uri: webhook.StringMatch{Prefix: "/path0/"}, Headers: verifiedOnly},
+			}))
+			Expect(http[0].Route[0].Destination.Host).To(Equal("s-route-0-destination-guid-0"))
+
+			Expect(http[1]).To(Equal(webhook.HTTPRoute{
+				Name: "cf-route-service",
+				Match: []webhook.HTTPMatchRequest{
+					{Uri: webhook.StringMatch{Exact: "/path0"}},
+					{Uri: webhook.StringMatch{Prefix: "/path0/"}},
+				},
+				Rewrite: &webhook.HTTPRewrite{Uri: "/auth?tenant=0", Authority: "route-service.example.com"},
+				Route: []webhook.HTTPRouteDestination{
+					{
+						Destination: webhook.VirtualServiceDestination{
+							Host: "route-service.example.com",
+							Port: &webhook.PortSelector{Number: 443},
+						},
+					},
+				},
+			}))
+
+			Expect(http[2].Match).To(BeNil())
+			Expect(http[2].Route[0].Destination.Host).To(Equal("s-route-1-destination-guid-0"))
+		})
+
+		It("names only the routes to the route service, which the ingress gateway checks", func() {
+			http := builder.Build(routes, template)[0].(webhook.VirtualService).Spec.Http
+
+			Expect(http[0].Name).To(BeEmpty())
+			Expect(http[1].Name).To(Equal(webhook.RouteServiceRouteName))
+			Expect(http[2].Name).To(BeEmpty())
+		})
+
+		It("does not publish any signature in the VirtualService", func() {
+			bytes, err := json.Marshal(builder.Build(routes, template))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(bytes)).NotTo(ContainSubstring("X-CF-Proxy-Signature"))
+			Expect(string(bytes)).NotTo(ContainSubstring("X-CF-Proxy-Metadata"))
+		})
+
+		It("matches on lowercase header names, as Istio requires", func() {
+			http := builder.Build(routes, template)[0].(webhook.VirtualService).Spec.Http
+
+			for _, match := range http[0].Match {
+				for name := range match.Headers {
+					Expect(name).To(Equal(strings.ToLower(name)))
+				}
+			}
+		})
+
+		Context("when the route has no path", func() {
+			It("matches every path", func() {
+				routes = routes[:1]
+				routes[0].Path = ""
+				routes[0].Url = "test0.domain0.example.com"
+
+				http := builder.Build(routes, template)[0].(webhook.VirtualService).Spec.Http
+
+				Expect(http[0].Match).To(Equal([]webhook.HTTPMatchRequest{
+					{
+						Uri:     webhook.StringMatch{Prefix: "/"},
+						Headers: map[string]webhook.StringMatch{"x-cf-route-service-verified": {Exact: "true"}},
+					},
+				}))
+				Expect(http[1].Match).To(BeNil())
+			})
+		})
+
+		Context("when no route service secret is configured", func() {
+			It("skips the bound route rather than bypassing the route service", func() {
+				builder.RouteServices = false
+
+				resources := builder.Build(routes, template)
+
+				Expect(resources).To(HaveLen(1))
+				http := resources[0].(webhook.VirtualService).Spec.Http
+				Expect(http).To(HaveLen(1))
+				Expect(http[0].Route[0].Destination.Host).To(Equal("s-route-1-destination-guid-0"))
+			})
+
+			Context("and every route of the fqdn is bound", func() {
+				It("does not create the VirtualService", func() {
+					builder.RouteServices = false
+
+					Expect(builder.Build(routes[:1], template)).To(Equal([]webhook.K8sResource{}))
+				})
+			})
+		})
+
+		Context("when the route service url is not http or https", func() {
+			It("skips the bound route", func() {
+				routes[0].RouteServiceUrl = "ftp://route-service.example.com"
+
+				resources := builder.Build(routes, template)
+
+				Expect(resources).To(HaveLen(1))
+				http := resources[0].(webhook.VirtualService).Spec.Http
+				Expect(http).To(HaveLen(1))
+				Expect(http[0].Route[0].Destination.Host).To(Equal("s-route-1-destination-guid-0"))
+			})
+		})
+	})
 })
 
 var _ = Describe("VirtualServiceName", func() {
@@ -1324,3 +1690,21 @@ var _ = Describe("VirtualServiceName", func() {
 			Equal("vs-b2b7f04662a35e5d54b33c988c8ee4ddfdbcd33c5fbd0eb11e5c011009641015"))
 	})
 })
+
+var _ = Describe("HTTPMatchRequest", func() {
+	It("serializes only the form of the uri match that is set", func() {
+		matches := []webhook.HTTPMatchRequest{
+			{Uri: webhook.StringMatch{Exact: "/foo"}},
+			{Uri: webhook.StringMatch{Prefix: "/foo/"}},
+			{Uri: webhook.StringMatch{Regex: "/foo(/.*)?"}},
+		}
+
+		bytes, err := json.Marshal(matches)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bytes).To(MatchJSON(`[
+			{"uri": {"exact": "/foo"}},
+			{"uri": {"prefix": "/foo/"}},
+			{"uri": {"regex": "/foo(/.*)?"}}
+		]`))
+	})
+})
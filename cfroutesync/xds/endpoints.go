@@ -0,0 +1,103 @@
+package xds
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// the labels of app pods, which the Services of the ServiceBuilder select on as well
+const (
+	appGuidLabel     = "cloudfoundry.org/app_guid"
+	processTypeLabel = "cloudfoundry.org/process_type"
+)
+
+// PodEndpoints looks up the addresses of the ready app pods of destinations in a cache of the pods
+// of the workloads namespace, which is kept up to date by watching the API server
+type PodEndpoints struct {
+	Pods corev1listers.PodNamespaceLister
+
+	changes chan struct{}
+}
+
+// NewPodEndpoints starts watching the app pods of the namespace until the context is done,
+// and returns once the cache holds all of them
+func NewPodEndpoints(ctx context.Context, clientset kubernetes.Interface, namespace string) (*PodEndpoints, error) {
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = appGuidLabel
+		}),
+	)
+	podInformer := factory.Core().V1().Pods()
+
+	e := &PodEndpoints{
+		Pods:    podInformer.Lister().Pods(namespace),
+		changes: make(chan struct{}, 1),
+	}
+	podInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { e.changed() },
+		UpdateFunc: func(interface{}, interface{}) { e.changed() },
+		DeleteFunc: func(interface{}) { e.changed() },
+	})
+
+	factory.Start(ctx.Done())
+	for informerType, synced := range factory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			return nil, fmt.Errorf("syncing the cache of %s", informerType)
+		}
+	}
+	return e, nil
+}
+
+// Changes receives a value after pods changed, changes in between receives are coalesced
+func (e *PodEndpoints) Changes() <-chan struct{} {
+	return e.changes
+}
+
+func (e *PodEndpoints) changed() {
+	select {
+	case e.changes <- struct{}{}:
+	default:
+	}
+}
+
+// Addresses returns the sorted IPs of the ready pods of the destination's app process
+func (e *PodEndpoints) Addresses(destination models.Destination) ([]string, error) {
+	pods, err := e.Pods.List(labels.SelectorFromSet(labels.Set{
+		appGuidLabel:     destination.App.Guid,
+		processTypeLabel: destination.App.Process.Type,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := []string{}
+	for _, pod := range pods {
+		if pod.DeletionTimestamp == nil && pod.Status.PodIP != "" && isReady(pod) {
+			addresses = append(addresses, pod.Status.PodIP)
+		}
+	}
+	// Sorting so that the results are stable
+	sort.Strings(addresses)
+	return addresses, nil
+}
+
+func isReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package xds_test
+
+import (
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/xds"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+var _ = Describe("PodEndpoints", func() {
+	var (
+		podEndpoints *xds.PodEndpoints
+		indexer      cache.Indexer
+		destination  models.Destination
+	)
+
+	pod := func(name, appGuid, processType, ip string, ready corev1.ConditionStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "cf-workloads",
+				Labels: map[string]string{
+					"cloudfoundry.org/app_guid":     appGuid,
+					"cloudfoundry.org/process_type": processType,
+				},
+			},
+			Status: corev1.PodStatus{
+				PodIP:      ip,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		indexer = cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+		podEndpoints = &xds.PodEndpoints{
+			Pods: corev1listers.NewPodLister(indexer).Pods("cf-workloads"),
+		}
+		destination = models.Destination{
+			Guid: "dest-0",
+			App:  models.App{Guid: "app-0", Process: models.Process{Type: "web"}},
+			Port: 8080,
+		}
+	})
+
+	It("returns the sorted addresses of the ready pods of the app process", func() {
+		Expect(indexer.Add(pod("pod-1", "app-0", "web", "10.0.0.2", corev1.ConditionTrue))).To(Succeed())
+		Expect(indexer.Add(pod("pod-0", "app-0", "web", "10.0.0.1", corev1.ConditionTrue))).To(Succeed())
+		Expect(indexer.Add(pod("pod-2", "app-0", "web", "10.0.0.3", corev1.ConditionFalse))).To(Succeed())
+		Expect(indexer.Add(pod("pod-3", "app-0", "web", "", corev1.ConditionTrue))).To(Succeed())
+		Expect(indexer.Add(pod("pod-4", "app-0", "worker", "10.0.0.4", corev1.ConditionTrue))).To(Succeed())
+		Expect(indexer.Add(pod("pod-5", "app-1", "web", "10.0.0.5", corev1.ConditionTrue))).To(Succeed())
+
+		terminating := pod("pod-6", "app-0", "web", "10.0.0.6", corev1.ConditionTrue)
+		terminating.DeletionTimestamp = &metav1.Time{}
+		Expect(indexer.Add(terminating)).To(Succeed())
+
+		addresses, err := podEndpoints.Addresses(destination)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(addresses).To(Equal([]string{"10.0.0.1", "10.0.0.2"}))
+	})
+
+	Context("when the app has no pods", func() {
+		It("returns no addresses", func() {
+			addresses, err := podEndpoints.Addresses(destination)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(addresses).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,68 @@
+package xds
+
+import (
+	"context"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+
+	"github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	log "github.com/sirupsen/logrus"
+)
+
+//go:generate counterfeiter -o fakes/snapshot_setter.go --fake-name SnapshotSetter . snapshotSetter
+type snapshotSetter interface {
+	SetSnapshot(node string, snapshot cache.Snapshot) error
+}
+
+//go:generate counterfeiter -o fakes/translator.go --fake-name Translator . translator
+type translator interface {
+	Translate(routes []models.Route) (cache.Snapshot, error)
+}
+
+//go:generate counterfeiter -o fakes/snapshot_repo.go --fake-name SnapshotRepo . snapshotRepo
+type snapshotRepo interface {
+	Subscribe() (<-chan *models.RouteSnapshot, func())
+}
+
+// Pusher translates every new route snapshot into Envoy resources and puts them into the xDS cache,
+// from where they are pushed to the connected Envoys
+type Pusher struct {
+	Cache        snapshotSetter
+	Translator   translator
+	SnapshotRepo snapshotRepo
+
+	// Receives when the endpoints of the destinations changed, to push the latest snapshot again
+	EndpointChanges <-chan struct{}
+}
+
+// Run pushes until the context is done
+func (p *Pusher) Run(ctx context.Context) {
+	updates, unsubscribe := p.SnapshotRepo.Subscribe()
+	defer unsubscribe()
+
+	var latest *models.RouteSnapshot
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case latest = <-updates:
+		case <-p.EndpointChanges:
+		}
+
+		if latest == nil {
+			continue
+		}
+		if err := p.PushOnce(latest); err != nil {
+			log.WithError(err).Error("pushing xds resources")
+		}
+	}
+}
+
+// PushOnce translates the routes of the snapshot and sets them for all Envoys
+func (p *Pusher) PushOnce(snapshot *models.RouteSnapshot) error {
+	resources, err := p.Translator.Translate(snapshot.Routes)
+	if err != nil {
+		return err
+	}
+	return p.Cache.SetSnapshot(AllNodes, resources)
+}
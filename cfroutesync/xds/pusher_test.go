@@ -0,0 +1,107 @@
+package xds_test
+
+import (
+	"context"
+	"errors"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/xds"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/xds/fakes"
+
+	"github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Pusher", func() {
+	var (
+		pusher        *xds.Pusher
+		snapshotCache *fakes.SnapshotSetter
+		translator    *fakes.Translator
+		snapshotRepo  *fakes.SnapshotRepo
+		updates       chan *models.RouteSnapshot
+		changes       chan struct{}
+		resources     cache.Snapshot
+	)
+
+	BeforeEach(func() {
+		snapshotCache = &fakes.SnapshotSetter{}
+		translator = &fakes.Translator{}
+		resources = cache.NewSnapshot("some-version", nil, nil, nil, nil, nil, nil)
+		translator.TranslateReturns(resources, nil)
+
+		updates = make(chan *models.RouteSnapshot, 1)
+		snapshotRepo = &fakes.SnapshotRepo{}
+		snapshotRepo.SubscribeReturns(updates, func() {})
+
+		changes = make(chan struct{}, 1)
+
+		pusher = &xds.Pusher{
+			Cache:           snapshotCache,
+			Translator:      translator,
+			SnapshotRepo:    snapshotRepo,
+			EndpointChanges: changes,
+		}
+	})
+
+	Describe("PushOnce", func() {
+		It("translates the routes and sets the resources for all nodes", func() {
+			snapshot := &models.RouteSnapshot{Routes: []models.Route{{Guid: "route-0"}}}
+
+			Expect(pusher.PushOnce(snapshot)).To(Succeed())
+
+			routes := translator.TranslateArgsForCall(0)
+			Expect(routes).To(Equal(snapshot.Routes))
+			node, set := snapshotCache.SetSnapshotArgsForCall(0)
+			Expect(node).To(Equal(xds.AllNodes))
+			Expect(set).To(Equal(resources))
+		})
+
+		Context("when translating fails", func() {
+			It("returns the error and keeps the current resources", func() {
+				translator.TranslateReturns(cache.Snapshot{}, errors.New("potato"))
+
+				Expect(pusher.PushOnce(&models.RouteSnapshot{})).To(MatchError("potato"))
+				Expect(snapshotCache.SetSnapshotCallCount()).To(Equal(0))
+			})
+		})
+	})
+
+	Describe("Run", func() {
+		It("pushes every new snapshot until the context is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				pusher.Run(ctx)
+				close(done)
+			}()
+
+			updates <- &models.RouteSnapshot{Routes: []models.Route{{Guid: "route-0"}}}
+			Eventually(snapshotCache.SetSnapshotCallCount).Should(Equal(1))
+
+			updates <- &models.RouteSnapshot{Routes: []models.Route{{Guid: "route-1"}}}
+			Eventually(snapshotCache.SetSnapshotCallCount).Should(Equal(2))
+			routes := translator.TranslateArgsForCall(1)
+			Expect(routes).To(Equal([]models.Route{{Guid: "route-1"}}))
+
+			cancel()
+			Eventually(done).Should(BeClosed())
+		})
+
+		It("pushes the latest snapshot again when the endpoints change", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go pusher.Run(ctx)
+
+			changes <- struct{}{}
+			Consistently(snapshotCache.SetSnapshotCallCount).Should(Equal(0))
+
+			updates <- &models.RouteSnapshot{Routes: []models.Route{{Guid: "route-0"}}}
+			Eventually(snapshotCache.SetSnapshotCallCount).Should(Equal(1))
+
+			changes <- struct{}{}
+			Eventually(snapshotCache.SetSnapshotCallCount).Should(Equal(2))
+			Expect(translator.TranslateArgsForCall(1)).To(Equal([]models.Route{{Guid: "route-0"}}))
+		})
+	})
+})
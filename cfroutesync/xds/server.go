@@ -0,0 +1,43 @@
+package xds
+
+import (
+	"context"
+
+	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	clusterservice "github.com/envoyproxy/go-control-plane/envoy/service/cluster/v3"
+	discoverygrpc "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	endpointservice "github.com/envoyproxy/go-control-plane/envoy/service/endpoint/v3"
+	listenerservice "github.com/envoyproxy/go-control-plane/envoy/service/listener/v3"
+	routeservice "github.com/envoyproxy/go-control-plane/envoy/service/route/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/server/v3"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+// Every Envoy gets the same resources, so they are all keyed by this node id in the cache
+const AllNodes = "cfroutesync"
+
+type allNodesHash struct{}
+
+func (allNodesHash) ID(*core.Node) string {
+	return AllNodes
+}
+
+// NewSnapshotCache returns a cache that serves the same snapshot to every Envoy over ADS
+func NewSnapshotCache() cache.SnapshotCache {
+	return cache.NewSnapshotCache(true, allNodesHash{}, log.StandardLogger())
+}
+
+// NewGRPCServer returns a gRPC server for ADS and the separate RDS, CDS, EDS and LDS services
+func NewGRPCServer(ctx context.Context, snapshotCache cache.Cache) *grpc.Server {
+	xdsServer := server.NewServer(ctx, snapshotCache, nil)
+
+	grpcServer := grpc.NewServer()
+	discoverygrpc.RegisterAggregatedDiscoveryServiceServer(grpcServer, xdsServer)
+	routeservice.RegisterRouteDiscoveryServiceServer(grpcServer, xdsServer)
+	clusterservice.RegisterClusterDiscoveryServiceServer(grpcServer, xdsServer)
+	endpointservice.RegisterEndpointDiscoveryServiceServer(grpcServer, xdsServer)
+	listenerservice.RegisterListenerDiscoveryServiceServer(grpcServer, xdsServer)
+	return grpcServer
+}
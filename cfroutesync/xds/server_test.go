@@ -0,0 +1,69 @@
+package xds_test
+
+import (
+	"context"
+	"net"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/xds"
+
+	cluster "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	discoverygrpc "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	"github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+)
+
+var _ = Describe("GRPC server", func() {
+	var (
+		ctx           context.Context
+		cancel        context.CancelFunc
+		snapshotCache cache.SnapshotCache
+		grpcServer    *grpc.Server
+		conn          *grpc.ClientConn
+	)
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		snapshotCache = xds.NewSnapshotCache()
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		grpcServer = xds.NewGRPCServer(ctx, snapshotCache)
+		go grpcServer.Serve(listener)
+
+		conn, err = grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		conn.Close()
+		grpcServer.Stop()
+		cancel()
+	})
+
+	It("serves the resources set for all nodes to any Envoy over ADS", func() {
+		snapshot := cache.NewSnapshot("1", nil,
+			[]types.Resource{&cluster.Cluster{Name: "s-dest-0"}},
+			nil, nil, nil, nil)
+		Expect(snapshotCache.SetSnapshot(xds.AllNodes, snapshot)).To(Succeed())
+
+		stream, err := discoverygrpc.NewAggregatedDiscoveryServiceClient(conn).StreamAggregatedResources(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stream.Send(&discoverygrpc.DiscoveryRequest{
+			Node:    &core.Node{Id: "some-envoy"},
+			TypeUrl: resource.ClusterType,
+		})).To(Succeed())
+
+		response, err := stream.Recv()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.VersionInfo).To(Equal("1"))
+		Expect(response.Resources).To(HaveLen(1))
+		received := &cluster.Cluster{}
+		Expect(response.Resources[0].UnmarshalTo(received)).To(Succeed())
+		Expect(received.Name).To(Equal("s-dest-0"))
+	})
+})
@@ -0,0 +1,463 @@
+package xds
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"time"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/webhook"
+
+	cluster "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	endpoint "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	listener "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	route "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	buffer "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/buffer/v3"
+	cors "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/cors/v3"
+	router "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/router/v3"
+	hcm "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	tcpproxy "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/tcp_proxy/v3"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	"github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	protov1 "github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// Name of the HTTP listener and of its route configuration
+const HTTPResourceName = "cf-http"
+
+const clusterConnectTimeout = 5 * time.Second
+
+// The failures retried for routes with the retries annotation, the same as the default of Istio
+const retryOn = "connect-failure,refused-stream,unavailable,cancelled,retriable-status-codes"
+
+//go:generate counterfeiter -o fakes/endpoints_getter.go --fake-name EndpointsGetter . endpointsGetter
+type endpointsGetter interface {
+	Addresses(destination models.Destination) ([]string, error)
+}
+
+// Translator turns routes into Envoy resources. Every destination becomes an EDS cluster,
+// whose endpoints are the addresses of the ready app pods of the destination.
+type Translator struct {
+	// Port of the Envoy listener for HTTP routes
+	HTTPListenerPort int
+
+	Endpoints endpointsGetter
+}
+
+// Translate returns a snapshot of the resources for the routes. Every resource type is versioned
+// by the hash of its resources, so that Envoy only receives the types that changed.
+func (t *Translator) Translate(routes []models.Route) (cache.Snapshot, error) {
+	var clusters, loadAssignments, listeners []types.Resource
+
+	httpRouteConfig := &route.RouteConfiguration{Name: HTTPResourceName}
+	seenClusters := map[string]bool{}
+
+	addClusters := func(r models.Route) error {
+		for _, destination := range r.Destinations {
+			name := webhook.ServiceName(destination)
+			if seenClusters[name] {
+				continue
+			}
+			seenClusters[name] = true
+
+			addresses, err := t.Endpoints.Addresses(destination)
+			if err != nil {
+				return fmt.Errorf("looking up the endpoints of destination %s: %w", destination.Guid, err)
+			}
+			clusters = append(clusters, buildCluster(name, destination))
+			loadAssignments = append(loadAssignments, buildLoadAssignment(name, addresses, destination.Port))
+		}
+		return nil
+	}
+
+	routesForFQDN := map[string][]models.Route{}
+	for _, r := range webhook.WithoutRouteServices(routes, "the xDS backend") {
+		if len(r.Destinations) == 0 || r.IsTCP() {
+			continue
+		}
+		if r.Domain.Internal {
+			// Envoy only serves as the ingress, internal routes need a service mesh
+			continue
+		}
+		routesForFQDN[r.FQDN()] = append(routesForFQDN[r.FQDN()], r)
+	}
+
+	fqdns := make([]string, 0, len(routesForFQDN))
+	for fqdn := range routesForFQDN {
+		fqdns = append(fqdns, fqdn)
+	}
+	// Sorting so that the results are stable
+	sort.Strings(fqdns)
+
+	for _, fqdn := range fqdns {
+		// wildcard routes the fqdn falls back to are repeated in its virtual host, since
+		// Envoy only matches the routes of the virtual host with the most specific domain
+		fqdnRoutes := webhook.RoutesForFQDN(fqdn, routesForFQDN)
+		for _, r := range fqdnRoutes {
+			if err := addClusters(r); err != nil {
+				return cache.Snapshot{}, err
+			}
+		}
+		virtualHost, err := buildVirtualHost(fqdn, fqdnRoutes)
+		if err != nil {
+			return cache.Snapshot{}, err
+		}
+		httpRouteConfig.VirtualHosts = append(httpRouteConfig.VirtualHosts, virtualHost)
+	}
+
+	// a port can only be reserved by one route, like for the TCPRouteBuilder
+	routesForPort := webhook.GroupTCPRoutesByPort(routes)
+	ports := make([]int, 0, len(routesForPort))
+	for port := range routesForPort {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+	for _, port := range ports {
+		if port == t.HTTPListenerPort {
+			// a second listener on the address would make Envoy reject every listener of the update
+			log.Errorf("skipping tcp route %s: port %d is the port of the http listener", routesForPort[port].Guid, port)
+			continue
+		}
+		tcpListener, err := buildTCPListener(routesForPort[port])
+		if err != nil {
+			return cache.Snapshot{}, err
+		}
+		listeners = append(listeners, tcpListener)
+		if err := addClusters(routesForPort[port]); err != nil {
+			return cache.Snapshot{}, err
+		}
+	}
+
+	httpListener, err := buildHTTPListener(t.HTTPListenerPort)
+	if err != nil {
+		return cache.Snapshot{}, err
+	}
+	listeners = append([]types.Resource{httpListener}, listeners...)
+
+	snapshot := cache.Snapshot{}
+	for typ, resources := range map[types.ResponseType][]types.Resource{
+		types.Cluster:  clusters,
+		types.Endpoint: loadAssignments,
+		types.Route:    {httpRouteConfig},
+		types.Listener: listeners,
+	} {
+		version, err := versionOf(resources)
+		if err != nil {
+			return cache.Snapshot{}, err
+		}
+		snapshot.Resources[typ] = cache.NewResources(version, resources)
+	}
+	return snapshot, nil
+}
+
+func buildCluster(name string, destination models.Destination) *cluster.Cluster {
+	c := &cluster.Cluster{
+		Name:                 name,
+		ConnectTimeout:       ptypes.DurationProto(clusterConnectTimeout),
+		ClusterDiscoveryType: &cluster.Cluster_Type{Type: cluster.Cluster_EDS},
+		EdsClusterConfig: &cluster.Cluster_EdsClusterConfig{
+			EdsConfig: adsConfigSource(),
+		},
+	}
+	if destination.Protocol == models.ProtocolHTTP2 {
+		c.Http2ProtocolOptions = &core.Http2ProtocolOptions{}
+	}
+	return c
+}
+
+// buildLoadAssignment balances the requests of a cluster over the pods with the given addresses
+func buildLoadAssignment(name string, addresses []string, port int) *endpoint.ClusterLoadAssignment {
+	lbEndpoints := []*endpoint.LbEndpoint{}
+	for _, address := range addresses {
+		lbEndpoints = append(lbEndpoints, &endpoint.LbEndpoint{
+			HostIdentifier: &endpoint.LbEndpoint_Endpoint{
+				Endpoint: &endpoint.Endpoint{Address: socketAddress(address, port)},
+			},
+		})
+	}
+	return &endpoint.ClusterLoadAssignment{
+		ClusterName: name,
+		Endpoints:   []*endpoint.LocalityLbEndpoints{{LbEndpoints: lbEndpoints}},
+	}
+}
+
+// buildVirtualHost matches the routes in the order of webhook.RoutesForFQDN. The buffer filter is
+// disabled for the virtual host and only enabled for the routes that limit the request size.
+func buildVirtualHost(fqdn string, routes []models.Route) (*route.VirtualHost, error) {
+	disableBuffer, err := anypb.New(&buffer.BufferPerRoute{
+		Override: &buffer.BufferPerRoute_Disabled{Disabled: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	virtualHost := &route.VirtualHost{
+		Name:                 fqdn,
+		Domains:              []string{fqdn},
+		TypedPerFilterConfig: map[string]*anypb.Any{wellknown.Buffer: disableBuffer},
+	}
+	for _, r := range routes {
+		action := &route.Route_Route{Route: routeAction(r)}
+		filterConfig, err := routeFilterConfig(r)
+		if err != nil {
+			return nil, err
+		}
+
+		if r.Path == "" {
+			virtualHost.Routes = append(virtualHost.Routes, &route.Route{
+				Match:                &route.RouteMatch{PathSpecifier: &route.RouteMatch_Prefix{Prefix: "/"}},
+				Action:               action,
+				TypedPerFilterConfig: filterConfig,
+			})
+			continue
+		}
+		// /foo matches /foo and /foo/bar, but not /foobar
+		virtualHost.Routes = append(virtualHost.Routes,
+			&route.Route{
+				Match:                &route.RouteMatch{PathSpecifier: &route.RouteMatch_Path{Path: r.Path}},
+				Action:               action,
+				TypedPerFilterConfig: filterConfig,
+			},
+			&route.Route{
+				Match:                &route.RouteMatch{PathSpecifier: &route.RouteMatch_Prefix{Prefix: r.Path + "/"}},
+				Action:               action,
+				TypedPerFilterConfig: filterConfig,
+			},
+		)
+	}
+	return virtualHost, nil
+}
+
+// routeFilterConfig enables the buffer filter for routes with the max request size annotation,
+// which rejects larger requests with a 413
+func routeFilterConfig(r models.Route) (map[string]*anypb.Any, error) {
+	policy := webhook.AnnotationPolicy(r)
+	if policy.MaxRequestBytes == 0 {
+		return nil, nil
+	}
+	limit, err := anypb.New(&buffer.BufferPerRoute{
+		Override: &buffer.BufferPerRoute_Buffer{Buffer: &buffer.Buffer{
+			MaxRequestBytes: &wrappers.UInt32Value{Value: uint32(policy.MaxRequestBytes)},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]*anypb.Any{wellknown.Buffer: limit}, nil
+}
+
+// routeAction sends the requests to the destinations with the traffic policy of the route's annotations
+func routeAction(r models.Route) *route.RouteAction {
+	action := &route.RouteAction{
+		ClusterSpecifier: &route.RouteAction_WeightedClusters{
+			WeightedClusters: weightedClusters(r),
+		},
+	}
+
+	policy := webhook.AnnotationPolicy(r)
+	if policy.Timeout != 0 {
+		action.Timeout = ptypes.DurationProto(policy.Timeout)
+	}
+	if policy.Retries != nil {
+		action.RetryPolicy = &route.RetryPolicy{
+			RetryOn:    retryOn,
+			NumRetries: &wrappers.UInt32Value{Value: uint32(*policy.Retries)},
+		}
+	}
+	if len(policy.CORSAllowOrigins) != 0 {
+		cors := &route.CorsPolicy{}
+		for _, origin := range policy.CORSAllowOrigins {
+			cors.AllowOriginStringMatch = append(cors.AllowOriginStringMatch, originMatcher(origin))
+		}
+		action.Cors = cors
+	}
+	return action
+}
+
+func originMatcher(origin string) *matcher.StringMatcher {
+	if origin == "*" {
+		return &matcher.StringMatcher{MatchPattern: &matcher.StringMatcher_SafeRegex{
+			SafeRegex: &matcher.RegexMatcher{
+				EngineType: &matcher.RegexMatcher_GoogleRe2{GoogleRe2: &matcher.RegexMatcher_GoogleRE2{}},
+				Regex:      ".*",
+			},
+		}}
+	}
+	return &matcher.StringMatcher{MatchPattern: &matcher.StringMatcher_Exact{Exact: origin}}
+}
+
+func weightedClusters(r models.Route) *route.WeightedCluster {
+	weights := destinationWeights(r.Destinations)
+	weighted := &route.WeightedCluster{}
+	total := 0
+	for i, destination := range r.Destinations {
+		total += weights[i]
+		headers := []*core.HeaderValueOption{}
+		for _, header := range webhook.DestinationHeaders(r, destination) {
+			headers = append(headers, setHeader(header.Name, header.Value))
+		}
+		weighted.Clusters = append(weighted.Clusters, &route.WeightedCluster_ClusterWeight{
+			Name:                webhook.ServiceName(destination),
+			Weight:              &wrappers.UInt32Value{Value: uint32(weights[i])},
+			RequestHeadersToAdd: headers,
+		})
+	}
+	weighted.TotalWeight = &wrappers.UInt32Value{Value: uint32(total)}
+	return weighted
+}
+
+// destinationWeights returns the weights of the destinations, or an even split when they have none
+func destinationWeights(destinations []models.Destination) []int {
+	weights := make([]int, len(destinations))
+	for i, destination := range destinations {
+		if destination.Weight == nil {
+			weights[i] = 1
+		} else {
+			weights[i] = *destination.Weight
+		}
+	}
+	return weights
+}
+
+func setHeader(name, value string) *core.HeaderValueOption {
+	return &core.HeaderValueOption{
+		Header: &core.HeaderValue{Key: name, Value: value},
+		Append: &wrappers.BoolValue{Value: false},
+	}
+}
+
+func buildHTTPListener(port int) (*listener.Listener, error) {
+	corsConfig, err := anypb.New(&cors.Cors{})
+	if err != nil {
+		return nil, err
+	}
+	// the limit of the listener is never applied, since the virtual hosts disable the filter and
+	// the routes with the max request size annotation replace the limit
+	bufferConfig, err := anypb.New(&buffer.Buffer{
+		MaxRequestBytes: &wrappers.UInt32Value{Value: webhook.MaxRequestSizeLimit},
+	})
+	if err != nil {
+		return nil, err
+	}
+	routerConfig, err := anypb.New(&router.Router{})
+	if err != nil {
+		return nil, err
+	}
+	manager, err := anypb.New(&hcm.HttpConnectionManager{
+		StatPrefix: HTTPResourceName,
+		RouteSpecifier: &hcm.HttpConnectionManager_Rds{
+			Rds: &hcm.Rds{
+				ConfigSource:    adsConfigSource(),
+				RouteConfigName: HTTPResourceName,
+			},
+		},
+		StripPortMode: &hcm.HttpConnectionManager_StripAnyHostPort{StripAnyHostPort: true},
+		HttpFilters: []*hcm.HttpFilter{
+			{
+				// applies the cors policies of the routes
+				Name:       wellknown.CORS,
+				ConfigType: &hcm.HttpFilter_TypedConfig{TypedConfig: corsConfig},
+			},
+			{
+				// limits the request size of the routes with the max request size annotation
+				Name:       wellknown.Buffer,
+				ConfigType: &hcm.HttpFilter_TypedConfig{TypedConfig: bufferConfig},
+			},
+			{
+				Name:       wellknown.Router,
+				ConfigType: &hcm.HttpFilter_TypedConfig{TypedConfig: routerConfig},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &listener.Listener{
+		Name:    HTTPResourceName,
+		Address: socketAddress("0.0.0.0", port),
+		FilterChains: []*listener.FilterChain{{
+			Filters: []*listener.Filter{{
+				Name:       wellknown.HTTPConnectionManager,
+				ConfigType: &listener.Filter_TypedConfig{TypedConfig: manager},
+			}},
+		}},
+	}, nil
+}
+
+func buildTCPListener(r models.Route) (*listener.Listener, error) {
+	name := TCPListenerName(r.Port)
+	weights := destinationWeights(r.Destinations)
+
+	clusters := []*tcpproxy.TcpProxy_WeightedCluster_ClusterWeight{}
+	for i, destination := range r.Destinations {
+		clusters = append(clusters, &tcpproxy.TcpProxy_WeightedCluster_ClusterWeight{
+			Name:   webhook.ServiceName(destination),
+			Weight: uint32(weights[i]),
+		})
+	}
+
+	proxy, err := anypb.New(&tcpproxy.TcpProxy{
+		StatPrefix: name,
+		ClusterSpecifier: &tcpproxy.TcpProxy_WeightedClusters{
+			WeightedClusters: &tcpproxy.TcpProxy_WeightedCluster{Clusters: clusters},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &listener.Listener{
+		Name:    name,
+		Address: socketAddress("0.0.0.0", r.Port),
+		FilterChains: []*listener.FilterChain{{
+			Filters: []*listener.Filter{{
+				Name:       wellknown.TCPProxy,
+				ConfigType: &listener.Filter_TypedConfig{TypedConfig: proxy},
+			}},
+		}},
+	}, nil
+}
+
+func TCPListenerName(port int) string {
+	return fmt.Sprintf("cf-tcp-%d", port)
+}
+
+func adsConfigSource() *core.ConfigSource {
+	return &core.ConfigSource{
+		ResourceApiVersion:    core.ApiVersion_V3,
+		ConfigSourceSpecifier: &core.ConfigSource_Ads{Ads: &core.AggregatedConfigSource{}},
+	}
+}
+
+func socketAddress(address string, port int) *core.Address {
+	return &core.Address{
+		Address: &core.Address_SocketAddress{
+			SocketAddress: &core.SocketAddress{
+				Address:       address,
+				PortSpecifier: &core.SocketAddress_PortValue{PortValue: uint32(port)},
+			},
+		},
+	}
+}
+
+// versionOf hashes the resources, which are in a stable order
+func versionOf(resources []types.Resource) (string, error) {
+	hash := sha256.New()
+	for _, resource := range resources {
+		bytes, err := proto.MarshalOptions{Deterministic: true}.Marshal(protov1.MessageV2(resource))
+		if err != nil {
+			return "", err
+		}
+		hash.Write(bytes)
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
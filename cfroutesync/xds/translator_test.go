@@ -0,0 +1,334 @@
+package xds_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/models"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/xds"
+	"code.cloudfoundry.org/cf-k8s-networking/cfroutesync/xds/fakes"
+
+	cluster "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	endpoint "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	listener "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	route "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	buffer "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/buffer/v3"
+	tcpproxy "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/tcp_proxy/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	"github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Translator", func() {
+	var (
+		translator *xds.Translator
+		endpoints  *fakes.EndpointsGetter
+		routes     []models.Route
+	)
+
+	destination := func(guid string, weight *int) models.Destination {
+		return models.Destination{
+			Guid:   guid,
+			App:    models.App{Guid: "app-" + guid, Process: models.Process{Type: "web"}},
+			Port:   8080,
+			Weight: weight,
+		}
+	}
+
+	resourcesOf := func(snapshot cache.Snapshot, typ types.ResponseType) map[string]types.Resource {
+		resources := map[string]types.Resource{}
+		for name, item := range snapshot.Resources[typ].Items {
+			resources[name] = item.Resource
+		}
+		return resources
+	}
+
+	BeforeEach(func() {
+		endpoints = &fakes.EndpointsGetter{}
+		endpoints.AddressesStub = func(destination models.Destination) ([]string, error) {
+			if destination.Guid == "dest-0" {
+				return []string{"10.0.0.1", "10.0.0.2"}, nil
+			}
+			return []string{}, nil
+		}
+		translator = &xds.Translator{
+			HTTPListenerPort: 8080,
+			Endpoints:        endpoints,
+		}
+
+		space := models.Space{Guid: "space-guid", Organization: models.Organization{Guid: "org-guid"}}
+		routes = []models.Route{
+			{
+				Guid:         "route-0",
+				Host:         "www",
+				Url:          "www.example.com",
+				Protocol:     models.ProtocolHTTP,
+				Domain:       models.Domain{Name: "example.com"},
+				Space:        space,
+				Destinations: []models.Destination{destination("dest-0", nil)},
+			},
+			{
+				Guid:     "route-1",
+				Host:     "www",
+				Path:     "/api",
+				Url:      "www.example.com/api",
+				Protocol: models.ProtocolHTTP,
+				Domain:   models.Domain{Name: "example.com"},
+				Space:    space,
+				Destinations: []models.Destination{
+					destination("dest-0", models.IntPtr(25)),
+					destination("dest-1", models.IntPtr(75)),
+				},
+			},
+			{
+				Guid:         "route-2",
+				Url:          "tcp.example.com:2001",
+				Protocol:     models.ProtocolTCP,
+				Port:         2001,
+				Domain:       models.Domain{Name: "tcp.example.com"},
+				Space:        space,
+				Destinations: []models.Destination{destination("dest-2", nil)},
+			},
+			{
+				Guid:         "route-3",
+				Host:         "internal",
+				Protocol:     models.ProtocolHTTP,
+				Domain:       models.Domain{Name: "apps.internal", Internal: true},
+				Space:        space,
+				Destinations: []models.Destination{destination("dest-3", nil)},
+			},
+		}
+	})
+
+	It("builds an eds cluster with the addresses of the ready pods for each destination", func() {
+		snapshot, err := translator.Translate(routes)
+		Expect(err).NotTo(HaveOccurred())
+
+		clusters := resourcesOf(snapshot, types.Cluster)
+		Expect(clusters).To(HaveLen(3))
+		Expect(clusters).To(HaveKey("s-dest-0"))
+		envoyCluster := clusters["s-dest-0"].(*cluster.Cluster)
+		Expect(envoyCluster.GetType()).To(Equal(cluster.Cluster_EDS))
+		Expect(envoyCluster.EdsClusterConfig.EdsConfig.GetAds()).NotTo(BeNil())
+		Expect(envoyCluster.LoadAssignment).To(BeNil())
+
+		loadAssignments := resourcesOf(snapshot, types.Endpoint)
+		Expect(loadAssignments).To(HaveLen(3))
+		lbEndpoints := loadAssignments["s-dest-0"].(*endpoint.ClusterLoadAssignment).Endpoints[0].LbEndpoints
+		Expect(lbEndpoints).To(HaveLen(2))
+		Expect(lbEndpoints[0].GetEndpoint().Address.GetSocketAddress().Address).To(Equal("10.0.0.1"))
+		Expect(lbEndpoints[1].GetEndpoint().Address.GetSocketAddress().Address).To(Equal("10.0.0.2"))
+		Expect(lbEndpoints[1].GetEndpoint().Address.GetSocketAddress().GetPortValue()).To(Equal(uint32(8080)))
+
+		Expect(loadAssignments["s-dest-1"].(*endpoint.ClusterLoadAssignment).Endpoints[0].LbEndpoints).To(BeEmpty())
+		Expect(snapshot.Consistent()).To(Succeed())
+	})
+
+	It("only changes the version of the endpoints when the pods of a destination change", func() {
+		before, err := translator.Translate(routes)
+		Expect(err).NotTo(HaveOccurred())
+
+		endpoints.AddressesReturns([]string{"10.0.0.3"}, nil)
+		endpoints.AddressesStub = nil
+		after, err := translator.Translate(routes)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(after.Resources[types.Endpoint].Version).NotTo(Equal(before.Resources[types.Endpoint].Version))
+		Expect(after.Resources[types.Cluster].Version).To(Equal(before.Resources[types.Cluster].Version))
+		Expect(after.Resources[types.Route].Version).To(Equal(before.Resources[types.Route].Version))
+	})
+
+	Context("when the endpoints cannot be looked up", func() {
+		It("returns an error", func() {
+			endpoints.AddressesStub = nil
+			endpoints.AddressesReturns(nil, errors.New("potato"))
+
+			_, err := translator.Translate(routes)
+			Expect(err).To(MatchError("looking up the endpoints of destination dest-0: potato"))
+		})
+	})
+
+	It("builds a virtual host per fqdn with segment aware path matches and weighted clusters", func() {
+		snapshot, err := translator.Translate(routes)
+		Expect(err).NotTo(HaveOccurred())
+
+		routeConfigs := resourcesOf(snapshot, types.Route)
+		Expect(routeConfigs).To(HaveKey("cf-http"))
+		virtualHosts := routeConfigs["cf-http"].(*route.RouteConfiguration).VirtualHosts
+		Expect(virtualHosts).To(HaveLen(1))
+		Expect(virtualHosts[0].Domains).To(Equal([]string{"www.example.com"}))
+
+		envoyRoutes := virtualHosts[0].Routes
+		Expect(envoyRoutes).To(HaveLen(3))
+		Expect(envoyRoutes[0].Match.GetPath()).To(Equal("/api"))
+		Expect(envoyRoutes[1].Match.GetPrefix()).To(Equal("/api/"))
+		Expect(envoyRoutes[2].Match.GetPrefix()).To(Equal("/"))
+
+		weighted := envoyRoutes[0].GetRoute().GetWeightedClusters()
+		Expect(weighted.TotalWeight.Value).To(Equal(uint32(100)))
+		Expect(weighted.Clusters).To(HaveLen(2))
+		Expect(weighted.Clusters[1].Name).To(Equal("s-dest-1"))
+		Expect(weighted.Clusters[1].Weight.Value).To(Equal(uint32(75)))
+		Expect(weighted.Clusters[1].RequestHeadersToAdd[0].Header.Key).To(Equal("CF-App-Id"))
+		Expect(weighted.Clusters[1].RequestHeadersToAdd[0].Header.Value).To(Equal("app-dest-1"))
+	})
+
+	It("builds the http listener and a tcp proxy listener per tcp route", func() {
+		snapshot, err := translator.Translate(routes)
+		Expect(err).NotTo(HaveOccurred())
+
+		listeners := resourcesOf(snapshot, types.Listener)
+		Expect(listeners).To(HaveLen(2))
+		Expect(listeners["cf-http"].(*listener.Listener).Address.GetSocketAddress().GetPortValue()).To(Equal(uint32(8080)))
+
+		tcpListener := listeners["cf-tcp-2001"].(*listener.Listener)
+		Expect(tcpListener.Address.GetSocketAddress().GetPortValue()).To(Equal(uint32(2001)))
+		proxy := &tcpproxy.TcpProxy{}
+		Expect(tcpListener.FilterChains[0].Filters[0].GetTypedConfig().UnmarshalTo(proxy)).To(Succeed())
+		Expect(proxy.GetWeightedClusters().Clusters[0].Name).To(Equal("s-dest-2"))
+	})
+
+	It("only changes the versions of the resource types that changed", func() {
+		before, err := translator.Translate(routes)
+		Expect(err).NotTo(HaveOccurred())
+
+		routes[1].Destinations[0].Weight = models.IntPtr(50)
+		routes[1].Destinations[1].Weight = models.IntPtr(50)
+		after, err := translator.Translate(routes)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(after.Resources[types.Route].Version).NotTo(Equal(before.Resources[types.Route].Version))
+		Expect(after.Resources[types.Cluster].Version).To(Equal(before.Resources[types.Cluster].Version))
+		Expect(after.Resources[types.Listener].Version).To(Equal(before.Resources[types.Listener].Version))
+	})
+
+	Context("when a route is bound to a route service", func() {
+		It("skips the route rather than routing around the route service", func() {
+			routes[1].RouteServiceUrl = "https://route-service.example.com"
+
+			snapshot, err := translator.Translate(routes)
+			Expect(err).NotTo(HaveOccurred())
+
+			virtualHosts := resourcesOf(snapshot, types.Route)["cf-http"].(*route.RouteConfiguration).VirtualHosts
+			Expect(virtualHosts[0].Routes).To(HaveLen(1))
+			Expect(virtualHosts[0].Routes[0].Match.GetPrefix()).To(Equal("/"))
+			Expect(resourcesOf(snapshot, types.Cluster)).NotTo(HaveKey("s-dest-1"))
+		})
+	})
+
+	Context("when two tcp routes use the same port", func() {
+		It("builds one listener for the route with the lowest guid", func() {
+			routes = append(routes, models.Route{
+				Guid:         "route-0-tcp",
+				Url:          "tcp.example.com:2001",
+				Protocol:     models.ProtocolTCP,
+				Port:         2001,
+				Domain:       models.Domain{Name: "tcp.example.com"},
+				Destinations: []models.Destination{destination("dest-1", nil)},
+			})
+
+			snapshot, err := translator.Translate(routes)
+			Expect(err).NotTo(HaveOccurred())
+
+			listeners := resourcesOf(snapshot, types.Listener)
+			Expect(listeners).To(HaveLen(2))
+			proxy := &tcpproxy.TcpProxy{}
+			Expect(listeners["cf-tcp-2001"].(*listener.Listener).FilterChains[0].Filters[0].GetTypedConfig().UnmarshalTo(proxy)).To(Succeed())
+			Expect(proxy.GetWeightedClusters().Clusters[0].Name).To(Equal("s-dest-1"))
+			Expect(resourcesOf(snapshot, types.Cluster)).NotTo(HaveKey("s-dest-2"))
+		})
+	})
+
+	Context("when a tcp route uses the port of the http listener", func() {
+		It("skips the tcp route and keeps the http listener", func() {
+			routes[2].Port = 8080
+			routes[2].Url = "tcp.example.com:8080"
+
+			snapshot, err := translator.Translate(routes)
+			Expect(err).NotTo(HaveOccurred())
+
+			listeners := resourcesOf(snapshot, types.Listener)
+			Expect(listeners).To(HaveLen(1))
+			Expect(listeners).To(HaveKey("cf-http"))
+			Expect(resourcesOf(snapshot, types.Cluster)).NotTo(HaveKey("s-dest-2"))
+		})
+	})
+
+	Context("when there is a wildcard route", func() {
+		It("falls back to it from the virtual hosts of the fqdns it covers", func() {
+			routes = append(routes, models.Route{
+				Guid:         "route-wildcard",
+				Host:         "*",
+				Path:         "/fallback",
+				Url:          "*.example.com/fallback",
+				Protocol:     models.ProtocolHTTP,
+				Domain:       models.Domain{Name: "example.com"},
+				Destinations: []models.Destination{destination("dest-2", nil)},
+			})
+			routes[0].Path = "/root"
+			routes[0].Url = "www.example.com/root"
+
+			snapshot, err := translator.Translate(routes)
+			Expect(err).NotTo(HaveOccurred())
+
+			virtualHosts := resourcesOf(snapshot, types.Route)["cf-http"].(*route.RouteConfiguration).VirtualHosts
+			Expect(virtualHosts).To(HaveLen(2))
+			Expect(virtualHosts[0].Domains).To(Equal([]string{"*.example.com"}))
+
+			envoyRoutes := virtualHosts[1].Routes
+			Expect(virtualHosts[1].Domains).To(Equal([]string{"www.example.com"}))
+			Expect(envoyRoutes).To(HaveLen(6))
+			Expect(envoyRoutes[4].Match.GetPath()).To(Equal("/fallback"))
+			Expect(envoyRoutes[4].GetRoute().GetWeightedClusters().Clusters[0].Name).To(Equal("s-dest-2"))
+		})
+	})
+
+	Context("when a route has traffic policy annotations", func() {
+		It("applies them to the route", func() {
+			routes[0].Annotations = map[string]string{
+				"cfroutesync.cloudfoundry.org/timeout":            "30s",
+				"cfroutesync.cloudfoundry.org/retries":            "3",
+				"cfroutesync.cloudfoundry.org/cors-allow-origins": "https://example.com, *",
+			}
+
+			snapshot, err := translator.Translate(routes)
+			Expect(err).NotTo(HaveOccurred())
+
+			envoyRoutes := resourcesOf(snapshot, types.Route)["cf-http"].(*route.RouteConfiguration).VirtualHosts[0].Routes
+			action := envoyRoutes[2].GetRoute()
+			Expect(action.Timeout.AsDuration()).To(Equal(30 * time.Second))
+			Expect(action.RetryPolicy.NumRetries.Value).To(Equal(uint32(3)))
+			Expect(action.Cors.AllowOriginStringMatch).To(HaveLen(2))
+			Expect(action.Cors.AllowOriginStringMatch[0].GetExact()).To(Equal("https://example.com"))
+			Expect(action.Cors.AllowOriginStringMatch[1].GetSafeRegex().Regex).To(Equal(".*"))
+
+			Expect(envoyRoutes[0].GetRoute().Timeout).To(BeNil())
+			Expect(envoyRoutes[0].GetRoute().RetryPolicy).To(BeNil())
+			Expect(envoyRoutes[0].GetRoute().Cors).To(BeNil())
+		})
+	})
+
+	Context("when a route has the max request size annotation", func() {
+		It("enables the buffer filter with the limit for the route only", func() {
+			routes[1].Annotations = map[string]string{
+				"cfroutesync.cloudfoundry.org/max-request-size": "1Mi",
+			}
+
+			snapshot, err := translator.Translate(routes)
+			Expect(err).NotTo(HaveOccurred())
+
+			virtualHost := resourcesOf(snapshot, types.Route)["cf-http"].(*route.RouteConfiguration).VirtualHosts[0]
+			disabled := &buffer.BufferPerRoute{}
+			Expect(virtualHost.TypedPerFilterConfig["envoy.filters.http.buffer"].UnmarshalTo(disabled)).To(Succeed())
+			Expect(disabled.GetDisabled()).To(BeTrue())
+
+			for _, envoyRoute := range virtualHost.Routes[:2] {
+				limit := &buffer.BufferPerRoute{}
+				Expect(envoyRoute.TypedPerFilterConfig["envoy.filters.http.buffer"].UnmarshalTo(limit)).To(Succeed())
+				Expect(limit.GetBuffer().MaxRequestBytes.Value).To(Equal(uint32(1024 * 1024)))
+			}
+			Expect(virtualHost.Routes[2].TypedPerFilterConfig).To(BeEmpty())
+		})
+	})
+})